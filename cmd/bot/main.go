@@ -1,12 +1,27 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
 	"os"
 
+	"postavkinBot/internal/api"
 	"postavkinBot/internal/bot"
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/leader"
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/metrics"
+	"postavkinBot/internal/secret"
 	"postavkinBot/internal/storage"
 	"postavkinBot/internal/wb"
+	"postavkinBot/internal/webhook"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
@@ -16,81 +31,212 @@ import (
 var updatesChan = make(chan tgbotapi.Update)
 
 func main() {
-	// Загружаем .env
-	err := godotenv.Load()
+	configPath := flag.String("config", "", "файл настроек YAML или TOML (по умолчанию config.yaml, config.yml или config.toml, если есть)")
+	flag.Parse()
+
+	// .env необязателен: переменные окружения можно задать и без него
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fatal("Ошибка загрузки .env файла", err)
+	}
+
+	// Служебная команда: bot migrate up|down [N]|status
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(*configPath, flag.Args()[1:]))
+	}
+
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatal("Ошибка загрузки .env файла")
+		fatal("Ошибка в настройках", err)
 	}
 
-	// Инициализация токена бота
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if botToken == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN не установлен")
+	// Журнал: секреты из настроек вычищаются из всех записей
+	logging.AddSecret(cfg.Telegram.Token, cfg.WB.APIKey, cfg.WB.MasterKey, cfg.Telegram.Webhook.SecretToken, cfg.Telegram.Webhook.PathSecret)
+	if u, err := url.Parse(cfg.Database.DSN); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			logging.AddSecret(password)
+		}
+	}
+	logger := logging.Setup(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err := tgbotapi.SetLogger(logging.BotLogger{Logger: logger}); err != nil {
+		fatal("Ошибка настройки журнала Telegram", err)
 	}
 
 	// Инициализация бота
-	tgBot, err := tgbotapi.NewBotAPI(botToken)
+	tgBot, err := tgbotapi.NewBotAPI(cfg.Telegram.Token)
 	if err != nil {
-		log.Panic(err)
+		fatal("Ошибка подключения к Telegram", err)
 	}
-	tgBot.Debug = true
+	tgBot.Debug = cfg.Log.Level == "debug"
 
-	log.Printf("Бот запущен как: %s", tgBot.Self.UserName)
+	slog.Info("Бот запущен", "username", tgBot.Self.UserName)
 
 	// Инициализация БД
-	dbPath := "data.db"
-	storageInstance, err := storage.NewStorage(dbPath)
+	storageInstance, err := storage.NewStorage(cfg.Database.Source())
 	if err != nil {
-		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+		fatal("Ошибка подключения к базе данных", err)
+	}
+
+	// Общий ключ без доступа к поставкам бесполезен: коэффициенты приёмки не получить
+	if info, err := wb.ParseToken(cfg.WB.APIKey); err != nil {
+		slog.Warn("Общий ключ WB не разобран, срок действия не проверяется", logging.Err(err))
+	} else if !info.HasScope(wb.ScopeSupplies) {
+		fatal("Ошибка общего ключа WB", errors.New("у ключа нет доступа к категории «Поставки»"))
+	} else {
+		slog.Info("Общий ключ WB", "seller_id", info.SellerID, "expires_at", info.ExpiresAt, "read_only", info.ReadOnly())
 	}
 
-	// Инициализация WB клиента
-	wbClient := wb.NewClient()
+	// Клиенты WB: общий на ключе бота и по одному на каждый ключ пользователя
+	wbPool := wb.NewPool(cfg.WB.BaseURL, cfg.WB.APIKey, cfg.WB.Timeout)
+	wbClient := wbPool.Shared()
+
+	// Шифрование ключей WB пользователей; без мастер-ключа /apikey выключена
+	var keys *secret.Box
+	if cfg.WB.MasterKey != "" {
+		if keys, err = secret.NewBox(cfg.WB.MasterKey); err != nil {
+			fatal("Ошибка мастер-ключа", err)
+		}
+	}
+
+	// Менеджер жизненного цикла: SIGINT/SIGTERM отменяет его контекст
+	manager := lifecycle.New()
+
+	// Зависимости обработчиков и планировщика
+	deps := &bot.Deps{
+		Config:  cfg,
+		Storage: storageInstance,
+		WB:      wbClient,
+		WBPool:  wbPool,
+		Keys:    keys,
+		Catalog: bot.NewCatalog(wbClient),
+		Queue: bot.NewQueue(tgBot, storageInstance, bot.QueueLimits{
+			GlobalPerSecond: cfg.Limits.GlobalPerSecond,
+			PrivateInterval: cfg.Limits.PrivateSendInterval,
+			GroupInterval:   cfg.Limits.GroupSendInterval,
+			MaxAttempts:     cfg.Limits.MaxSendAttempts,
+		}),
+	}
+
+	// Выбор ведущего: опрос WB, очередь и проверка ключей работают только на нём,
+	// команды обрабатывает любой экземпляр
+	elector := leader.New(storageInstance, cfg.Leader.InstanceID, cfg.Leader.LeaseTTL)
+	manager.Go("leader", elector.Run)
+	slog.Info("Экземпляр участвует в выборе ведущего", "instance", elector.ID())
+
+	// Отправка уведомлений из очереди
+	manager.Go("queue", elector.Lead(deps.Queue.Run))
+	metrics.QueueDepth(storageInstance.CountPendingMessages)
+
+	// Метрики и проверки состояния
+	if cfg.Metrics.Listen != "" {
+		server := metrics.NewServer(cfg.Metrics.Listen, cfg.Metrics.ReadyMaxAge, cfg.Metrics.HealthMaxAge)
+		manager.Go("metrics", server.Run)
+	}
 
-	// Связываем пакеты bot -> storage и wb
-	bot.Storage = storageInstance
-	bot.WbClient = wbClient
-	bot.UpdatesChan = updatesChan // << добавляем канал апдейтов в пакет bot
+	// События по складам: уведомления в Telegram, история и метрики
+	deps.Events = events.NewBus()
+	deps.Events.SubscribeSync("telegram", bot.NewTelegramSink(deps.Queue))
+	deps.Events.Subscribe("history", events.HistorySink(storageInstance), 1024)
+	deps.Events.Subscribe("metrics", events.MetricsSink(), 256)
+	if keys != nil {
+		dispatcher := webhook.NewDispatcher(storageInstance, keys, cfg.Webhooks, bot.NotifyWebhookDisabled(deps))
+		deps.Events.Subscribe("webhooks", dispatcher, 1024)
+		manager.Go("webhooks", elector.Lead(dispatcher.Run))
+	} else if count, err := storageInstance.CountEnabledWebhooks(); err != nil {
+		slog.Error("Ошибка подсчёта вебхуков", logging.Err(err))
+	} else if count > 0 {
+		// Секреты подписи без мастер-ключа не расшифровать: события на вебхуки не уходят
+		slog.Warn("Вебхуки настроены, но wb.master_key не задан: доставка выключена", "webhooks", count)
+	}
+	manager.Go("events", deps.Events.Run)
 
 	// Старт планировщика
-	bot.StartCronJob(tgBot)
+	if err := deps.Catalog.Load(); err != nil {
+		fatal("Ошибка получения списка складов при старте", err)
+	}
+	manager.Go("catalog", deps.Catalog.Run)
+	deps.Scheduler = bot.NewScheduler(deps)
+	manager.Go("scheduler", elector.Lead(deps.Scheduler.Run))
+	manager.Go("keys", elector.Lead(bot.NewKeyWatcher(deps).Run))
+	manager.Go("janitor", elector.Lead(bot.NewJanitor(deps).Run))
+
+	// REST API отвечает на любом экземпляре: коэффициенты берутся из снимка ведущего в базе, WB не запрашивается
+	if cfg.API.Listen != "" {
+		server := api.NewServer(cfg.API, storageInstance, deps.Catalog, deps.Scheduler.Snapshot, bot.NotifySubscriptionChanged(deps))
+		manager.Go("api", server.Run)
+	}
 
-	// Получение апдейтов
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := tgBot.GetUpdatesChan(u)
+	// Маршрутизация команд
+	router := bot.NewRouter(tgBot, tgBot.Self.UserName, deps)
+	bot.RegisterRoutes(router)
 
-	// Параллельно закидываем апдейты в канал
-	go func() {
-		for update := range updates {
-			updatesChan <- update
-		}
-	}()
+	// Получение апдейтов: long polling или webhook, обработка общая
+	var source bot.UpdateSource
+	if cfg.Telegram.Mode == "webhook" {
+		source, err = bot.StartWebhook(tgBot, webhookConfig(cfg.Telegram.Webhook), updatesChan, manager.Fail)
+	} else {
+		source, err = bot.StartPolling(tgBot, updatesChan)
+	}
+	if err != nil {
+		// Фоновые задачи уже запущены — останавливаем их как при сигнале
+		manager.Fail(fmt.Errorf("ошибка запуска получения апдейтов: %w", err))
+	} else {
+		// Обработка апдейтов
+		manager.Go("updates", func(ctx context.Context) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case update := <-updatesChan:
+					router.HandleUpdate(ctx, update)
+				}
+			}
+		})
+	}
 
-	// Обработка апдейтов
-	for update := range updatesChan {
-		if update.Message == nil {
-			continue
-		}
+	<-manager.Done()
+	if err := manager.Err(); err != nil {
+		slog.Error("Остановка из-за ошибки", logging.Err(err))
+	} else {
+		slog.Info("Получен сигнал остановки, завершаем работу")
+	}
 
-		switch update.Message.Command() {
-		case "start":
-			bot.HandleStart(tgBot, update)
-		case "help":
-			bot.HandleHelp(tgBot, update)
-		case "warehouses":
-			bot.HandleWarehouses(tgBot, update)
-		case "addwarehouse":
-			bot.HandleAddWarehouse(tgBot, update)
-		case "mywarehouses":
-			bot.HandleMyWarehouses(tgBot, update)
-		case "removewarehouse":
-			bot.HandleRemoveWarehouse(tgBot, update)
-		case "setinterval":
-			bot.HandleSetInterval(tgBot, update)
-		default:
-			msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Неизвестная команда. Введите /help для списка доступных команд.")
-			tgBot.Send(msg)
+	// Сначала перестаём принимать апдейты, затем ждём текущие отправки и записи в базу
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if source != nil {
+		if err := source.Stop(ctx); err != nil {
+			slog.Error("Ошибка остановки получения апдейтов", logging.Err(err))
 		}
 	}
+	if err := manager.Shutdown(cfg.ShutdownTimeout); err != nil {
+		slog.Error("Ошибка остановки", logging.Err(err))
+	}
+	if err := storageInstance.Close(); err != nil {
+		slog.Error("Ошибка закрытия базы данных", logging.Err(err))
+	}
+
+	slog.Info("Бот остановлен")
+	if manager.Err() != nil {
+		os.Exit(1)
+	}
+}
+
+// fatal — записать ошибку запуска и завершить процесс
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.Err(err))
+	os.Exit(1)
+}
+
+// webhookConfig — настройки webhook для приёма апдейтов
+func webhookConfig(w config.Webhook) bot.WebhookConfig {
+	return bot.WebhookConfig{
+		URL:          w.URL,
+		Listen:       w.Listen,
+		PathSecret:   w.PathSecret,
+		SecretToken:  w.SecretToken,
+		CertFile:     w.CertFile,
+		KeyFile:      w.KeyFile,
+		UploadCert:   w.UploadCert,
+		DeleteOnStop: w.DeleteOnStop,
+	}
 }
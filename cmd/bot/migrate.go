@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/storage"
+)
+
+const migrateUsage = `Использование: bot [-config файл] migrate <команда>
+  up        применить все недостающие миграции
+  down [N]  откатить N последних миграций (по умолчанию 1)
+  status    показать версию схемы и список миграций`
+
+// runMigrate — команда migrate, возвращает код завершения процесса
+func runMigrate(configPath string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка в настройках: %v\n", err)
+		return 1
+	}
+	if cfg.Database.Source() == "" {
+		fmt.Fprintln(os.Stderr, "Не задана база (database.dsn или DATABASE_URL, database.path или DB_PATH)")
+		return 1
+	}
+
+	store, err := storage.Open(cfg.Database.Source())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка подключения к базе данных: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := store.MigrateUp()
+		for _, m := range applied {
+			fmt.Printf("Применена миграция %d: %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка миграции: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("Схема актуальна")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+		reverted, err := store.MigrateDown(steps)
+		for _, m := range reverted {
+			fmt.Printf("Откачена миграция %d: %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка отката: %v\n", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Нечего откатывать")
+		}
+
+	case "status":
+		version, err := store.SchemaVersion()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка чтения версии схемы: %v\n", err)
+			return 1
+		}
+		status, err := store.MigrationStatus()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка чтения миграций: %v\n", err)
+			return 1
+		}
+
+		fmt.Printf("Версия схемы: %d, последняя версия бота: %d\n", version, storage.LatestVersion())
+		if version > storage.LatestVersion() {
+			fmt.Println("Схема новее бота: обновите бота, прежде чем запускать его на этой базе")
+		}
+		for _, m := range status {
+			applied := "не применена"
+			if m.AppliedAt != nil {
+				applied = "применена " + m.AppliedAt.Local().Format("02.01.2006 15:04:05")
+			}
+			fmt.Printf("%4d  %-50s  %s\n", m.Version, m.Name, applied)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,267 @@
+// Package alert — шаблоны уведомлений о слотах на складах.
+//
+// Шаблон пишется на Go text/template. Доступные поля:
+//
+//	{{.WarehouseName}}  название склада
+//	{{.WarehouseID}}    ID склада
+//	{{.Date}}           дата приёмки, ДД.ММ.ГГГГ
+//	{{.Coefficient}}    коэффициент приёмки (0 — бесплатно)
+//	{{.BoxType}}        тип поставки, например «Короба»
+//	{{.BoxTypeID}}      ID типа поставки
+//	{{.AllowUnload}}    разгрузка разрешена (true/false)
+//	{{.SortingCenter}}  склад является сортировочным центром (true/false)
+//	{{.Link}}           ссылка на планирование поставки в личном кабинете WB
+//
+// Строковые поля уже экранированы под выбранный режим разметки, поэтому в шаблоне
+// их можно вставлять как есть, в том числе внутрь <a href="..."> или [текст](...).
+package alert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"postavkinBot/internal/wb"
+)
+
+// Режимы разметки Telegram
+const (
+	ParseModeText       = ""
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// SupplyLink — страница планирования поставок в личном кабинете продавца
+const SupplyLink = "https://seller.wildberries.ru/supplies-management/all-supplies"
+
+// MaxTemplateSize — ограничение длины шаблона, чтобы сообщение влезало в лимит Telegram
+const MaxTemplateSize = 2000
+
+// MaxMessageSize — лимит Telegram на текст сообщения; длиннее шаблон выводить не может
+const MaxMessageSize = 4096
+
+// maxCacheSize — сколько разобранных шаблонов держать в памяти
+const maxCacheSize = 1024
+
+var (
+	// ErrTemplateTooLong — шаблон длиннее MaxTemplateSize
+	ErrTemplateTooLong = errors.New("шаблон слишком длинный")
+	// ErrMessageTooLong — по шаблону выходит сообщение длиннее MaxMessageSize
+	ErrMessageTooLong = errors.New("сообщение по шаблону длиннее лимита Telegram")
+	// ErrUnsupportedAction — в шаблоне циклы, вложенные шаблоны или слишком широкий printf
+	ErrUnsupportedAction = errors.New("в шаблоне можно использовать только поля, if, with и функции без циклов")
+)
+
+// wideVerb — ширина или точность printf из пяти и больше цифр: fmt собрал бы огромную строку в памяти
+var wideVerb = regexp.MustCompile(`%[^a-zA-Z%]*[0-9]{5,}`)
+
+// funcs — printf с проверкой ширины: остальной вывод ограничен limitWriter
+var funcs = template.FuncMap{
+	"printf": func(format string, args ...any) (string, error) {
+		if wideVerb.MatchString(format) {
+			return "", ErrUnsupportedAction
+		}
+		return fmt.Sprintf(format, args...), nil
+	},
+}
+
+// cache — разобранные шаблоны по тексту и режиму разметки, чтобы не разбирать их для каждого получателя
+var cache = struct {
+	sync.RWMutex
+	templates map[cacheKey]*template.Template
+}{templates: make(map[cacheKey]*template.Template)}
+
+// cacheKey — шаблон в кэше
+type cacheKey struct {
+	text, parseMode string
+}
+
+// Data — поля, доступные в шаблоне
+type Data struct {
+	WarehouseName string
+	WarehouseID   int
+	Date          string
+	Coefficient   int
+	BoxType       string
+	BoxTypeID     int
+	AllowUnload   bool
+	SortingCenter bool
+	Link          string
+}
+
+// NewData — поля шаблона из коэффициента WB, строки экранированы под parseMode
+func NewData(c wb.Coefficient, parseMode string) Data {
+	date := c.Date
+	if t, err := time.Parse(time.RFC3339, c.Date); err == nil {
+		date = t.Format("02.01.2006")
+	}
+
+	return Data{
+		WarehouseName: Escape(parseMode, c.WarehouseName),
+		WarehouseID:   c.WarehouseID,
+		Date:          Escape(parseMode, date),
+		Coefficient:   c.Coefficient,
+		BoxType:       Escape(parseMode, c.BoxTypeName),
+		BoxTypeID:     c.BoxTypeID,
+		AllowUnload:   c.AllowUnload,
+		SortingCenter: c.IsSortingCenter,
+		Link:          Escape(parseMode, SupplyLink),
+	}
+}
+
+// Sample — данные для проверки шаблона, когда свежих коэффициентов нет
+func Sample() wb.Coefficient {
+	return wb.Coefficient{
+		Date:          time.Now().Format(time.RFC3339),
+		Coefficient:   0,
+		WarehouseID:   507,
+		WarehouseName: "Коледино",
+		AllowUnload:   true,
+		BoxTypeName:   "Короба",
+		BoxTypeID:     2,
+	}
+}
+
+// ParseMode — нормализовать название режима разметки; false, если режим не поддерживается
+func ParseMode(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "", "text", "plain":
+		return ParseModeText, true
+	case "html":
+		return ParseModeHTML, true
+	case "markdown", "markdownv2":
+		return ParseModeMarkdownV2, true
+	default:
+		return "", false
+	}
+}
+
+// Parse — разобрать шаблон, проверить его на тестовых данных и запомнить разобранным для Render
+func Parse(text, parseMode string) (*template.Template, error) {
+	if len(text) > MaxTemplateSize {
+		return nil, ErrTemplateTooLong
+	}
+
+	tpl, err := template.New("alert").Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Поля Data плоские, циклам и вложенным шаблонам обходить нечего, а без них
+	// время выполнения ограничено длиной шаблона
+	if len(tpl.Templates()) > 1 || !simple(tpl.Root) {
+		return nil, ErrUnsupportedAction
+	}
+
+	// Ошибки вроде несуществующего поля видны только при выполнении
+	if _, err := execute(tpl, NewData(Sample(), parseMode)); err != nil {
+		return nil, err
+	}
+
+	cache.Lock()
+	if len(cache.templates) >= maxCacheSize {
+		clear(cache.templates)
+	}
+	cache.templates[cacheKey{text, parseMode}] = tpl
+	cache.Unlock()
+	return tpl, nil
+}
+
+// simple — в дереве шаблона нет range, template и прочих узлов, кроме текста, действий и условий
+func simple(node parse.Node) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, child := range n.Nodes {
+			if !simple(child) {
+				return false
+			}
+		}
+		return true
+	case *parse.TextNode, *parse.ActionNode, *parse.CommentNode:
+		return true
+	case *parse.IfNode:
+		return simple(n.List) && simple(n.ElseList)
+	case *parse.WithNode:
+		return simple(n.List) && simple(n.ElseList)
+	default:
+		return false
+	}
+}
+
+// Render — текст уведомления по шаблону; разобранный шаблон берётся из кэша
+func Render(text, parseMode string, c wb.Coefficient) (string, error) {
+	cache.RLock()
+	tpl, ok := cache.templates[cacheKey{text, parseMode}]
+	cache.RUnlock()
+
+	if !ok {
+		var err error
+		if tpl, err = Parse(text, parseMode); err != nil {
+			return "", err
+		}
+	}
+	return execute(tpl, NewData(c, parseMode))
+}
+
+// limitWriter — буфер, который прерывает выполнение шаблона, когда текст перерос лимит
+type limitWriter struct {
+	bytes.Buffer
+}
+
+// Write — дописать p или вернуть ErrMessageTooLong
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > MaxMessageSize {
+		return 0, ErrMessageTooLong
+	}
+	return w.Buffer.Write(p)
+}
+
+// execute — выполнить шаблон, пустой или слишком длинный результат считается ошибкой
+func execute(tpl *template.Template, data Data) (string, error) {
+	var buf limitWriter
+	if err := tpl.Execute(&buf, data); err != nil {
+		if errors.Is(err, ErrMessageTooLong) {
+			return "", ErrMessageTooLong
+		}
+		return "", err
+	}
+
+	text := strings.TrimSpace(buf.String())
+	if text == "" {
+		return "", fmt.Errorf("шаблон дал пустое сообщение")
+	}
+	return text, nil
+}
+
+// markdownV2Special — символы, которые MarkdownV2 требует экранировать
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// Escape — экранировать произвольный текст для режима разметки
+func Escape(parseMode, text string) string {
+	switch parseMode {
+	case ParseModeHTML:
+		return html.EscapeString(text)
+	case ParseModeMarkdownV2:
+		var b strings.Builder
+		for _, r := range text {
+			if strings.ContainsRune(markdownV2Special, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	default:
+		return text
+	}
+}
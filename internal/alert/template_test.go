@@ -0,0 +1,101 @@
+package alert
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"postavkinBot/internal/wb"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		parseMode string
+		text      string
+		want      string
+	}{
+		{ParseModeText, `<b>Склад & "Ко"</b>`, `<b>Склад & "Ко"</b>`},
+		{ParseModeHTML, `<b>`, `&lt;b&gt;`},
+		{ParseModeHTML, `Склад & Ко`, `Склад &amp; Ко`},
+		{ParseModeHTML, `"Коледино"`, `&#34;Коледино&#34;`},
+		{ParseModeHTML, `'a'`, `&#39;a&#39;`},
+		{ParseModeHTML, `&amp;`, `&amp;amp;`},
+		{ParseModeMarkdownV2, `Склад_1 *новый*`, `Склад\_1 \*новый\*`},
+		{ParseModeMarkdownV2, `[a](b)`, `\[a\]\(b\)`},
+		{ParseModeMarkdownV2, `01.02.2026!`, `01\.02\.2026\!`},
+		{ParseModeMarkdownV2, `a\b`, `a\\b`},
+		{ParseModeMarkdownV2, `<&">`, `<&"\>`},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.parseMode, tt.text); got != tt.want {
+			t.Errorf("Escape(%q, %q) = %q, ожидалось %q", tt.parseMode, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRenderEscapesFields(t *testing.T) {
+	c := wb.Coefficient{
+		Date:          "2026-10-20T00:00:00Z",
+		WarehouseID:   507,
+		WarehouseName: `<script>"Склад" & Ко</script>`,
+		BoxTypeName:   `Короба <b>`,
+		AllowUnload:   true,
+	}
+
+	tests := []struct {
+		parseMode string
+		template  string
+		want      string
+	}{
+		{
+			ParseModeHTML,
+			`<b>{{.WarehouseName}}</b> {{.BoxType}} <a href="{{.Link}}">план</a>`,
+			`<b>&lt;script&gt;&#34;Склад&#34; &amp; Ко&lt;/script&gt;</b> Короба &lt;b&gt; <a href="` + SupplyLink + `">план</a>`,
+		},
+		{
+			ParseModeMarkdownV2,
+			`*{{.WarehouseName}}* {{.Date}}`,
+			`*<script\>"Склад" & Ко</script\>* 20\.10\.2026`,
+		},
+		{
+			ParseModeText,
+			`{{.WarehouseName}}`,
+			`<script>"Склад" & Ко</script>`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.template, tt.parseMode, c)
+		if err != nil {
+			t.Errorf("%s: %v", tt.parseMode, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: %q, ожидалось %q", tt.parseMode, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		err      error
+	}{
+		{"цикл", `{{range .WarehouseName}}x{{end}}`, ErrUnsupportedAction},
+		{"вложенный шаблон", `{{define "x"}}a{{end}}{{template "x"}}`, ErrUnsupportedAction},
+		{"широкий printf", `{{printf "%100000d" 1}}`, ErrUnsupportedAction},
+		{"длинный шаблон", strings.Repeat("a", MaxTemplateSize+1), ErrTemplateTooLong},
+		{"длинное сообщение", `{{printf "%9999d" 1}}`, ErrMessageTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.template, ParseModeHTML); !errors.Is(err, tt.err) {
+				t.Errorf("ошибка %v, ожидалась %v", err, tt.err)
+			}
+		})
+	}
+
+	if _, err := Parse(`{{.NoSuchField}}`, ParseModeHTML); err == nil {
+		t.Error("несуществующее поле должно давать ошибку при разборе")
+	}
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+const (
+	defaultLimit = 100 // Записей истории по умолчанию
+	maxLimit     = 500 // Больше за один запрос не отдаём
+)
+
+// warehouseJSON — склад в подписке
+type warehouseJSON struct {
+	ID   int    `json:"warehouse_id"`
+	Name string `json:"name"`
+}
+
+// subscriptionJSON — личная подписка с порогом и типом поставки
+type subscriptionJSON struct {
+	ID             int    `json:"warehouse_id"`
+	Name           string `json:"name"`
+	MaxCoefficient int    `json:"max_coefficient"`
+	BoxTypeID      int    `json:"box_type_id"` // 0 — любой
+}
+
+// teamJSON — команда пользователя и её общий список складов
+type teamJSON struct {
+	ID         uint            `json:"id"`
+	Name       string          `json:"name"`
+	Role       string          `json:"role"`
+	Warehouses []warehouseJSON `json:"warehouses"`
+}
+
+// eventJSON — запись истории
+type eventJSON struct {
+	Kind                string    `json:"kind"`
+	WarehouseID         int       `json:"warehouse_id"`
+	WarehouseName       string    `json:"warehouse_name"`
+	BoxTypeID           int       `json:"box_type_id"`
+	Date                string    `json:"date"`
+	Coefficient         int       `json:"coefficient"`
+	PreviousCoefficient *int      `json:"previous_coefficient,omitempty"`
+	AllowUnload         bool      `json:"allow_unload"`
+	TeamID              uint      `json:"team_id,omitempty"` // Для уведомлений по списку команды
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// subscriptions — склады пользователя: личные и его команды
+type subscriptions struct {
+	personal []storage.Subscription
+	team     *storage.Team
+	member   *storage.TeamMember
+	shared   []int
+}
+
+// all — все склады пользователя без повторов
+func (s subscriptions) all() []int {
+	ids := make([]int, 0, len(s.personal)+len(s.shared))
+	for _, sub := range s.personal {
+		ids = append(ids, sub.WarehouseID)
+	}
+	for _, id := range s.shared {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// loadSubscriptions — склады пользователя из базы
+func (s *Server) loadSubscriptions(user *storage.User) (subscriptions, error) {
+	var subs subscriptions
+	var err error
+	if subs.personal, err = s.store.GetChatSubscriptions(user.TelegramID); err != nil {
+		return subs, err
+	}
+
+	member, err := s.store.GetTeamMember(user.TelegramID)
+	if storage.IsNotFound(err) {
+		return subs, nil
+	}
+	if err != nil {
+		return subs, err
+	}
+	if subs.team, err = s.store.GetTeam(member.TeamID); err != nil {
+		return subs, err
+	}
+	subs.member = member
+	subs.shared, err = s.store.GetTeamWarehouses(member.TeamID)
+	return subs, err
+}
+
+// warehouses — склады с названиями из справочника
+func (s *Server) warehouses(ids []int) []warehouseJSON {
+	list := make([]warehouseJSON, len(ids))
+	for i, id := range ids {
+		list[i] = warehouseJSON{ID: id, Name: s.catalog.Name(id)}
+	}
+	return list
+}
+
+// handleSubscriptions — GET /api/v1/subscriptions
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *request) {
+	subs, err := s.loadSubscriptions(r.user)
+	if err != nil {
+		r.log.Error("Ошибка получения подписок для API", logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return
+	}
+
+	body := struct {
+		Warehouses []subscriptionJSON `json:"warehouses"`
+		Team       *teamJSON          `json:"team,omitempty"`
+	}{Warehouses: make([]subscriptionJSON, len(subs.personal))}
+	for i, sub := range subs.personal {
+		body.Warehouses[i] = s.subscriptionJSON(sub)
+	}
+	if subs.team != nil {
+		body.Team = &teamJSON{ID: subs.team.ID, Name: subs.team.Name, Role: subs.member.Role, Warehouses: s.warehouses(subs.shared)}
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// handleCoefficients — GET /api/v1/coefficients[?warehouse_id=]: последние коэффициенты по складам пользователя
+func (s *Server) handleCoefficients(w http.ResponseWriter, r *request) {
+	subs, err := s.loadSubscriptions(r.user)
+	if err != nil {
+		r.log.Error("Ошибка получения подписок для API", logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return
+	}
+	ids := subs.all()
+	if id, ok, valid := queryInt(r, "warehouse_id"); !valid {
+		writeError(w, http.StatusBadRequest, "invalid_warehouse_id")
+		return
+	} else if ok {
+		if !slices.Contains(ids, id) {
+			writeError(w, http.StatusNotFound, "not_subscribed")
+			return
+		}
+		ids = []int{id}
+	}
+
+	coefficients, fetchedAt, err := s.snapshot()
+	if err != nil {
+		r.log.Warn("Коэффициенты для API недоступны", logging.Err(err))
+		writeError(w, http.StatusServiceUnavailable, "coefficients_unavailable")
+		return
+	}
+
+	filtered := []wb.Coefficient{}
+	for _, c := range coefficients {
+		if slices.Contains(ids, c.WarehouseID) {
+			filtered = append(filtered, c)
+		}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		FetchedAt    time.Time        `json:"fetched_at"`
+		AgeSeconds   int64            `json:"age_seconds"`
+		Coefficients []wb.Coefficient `json:"coefficients"`
+	}{fetchedAt.UTC(), int64(time.Since(fetchedAt).Seconds()), filtered})
+}
+
+// handleAlerts — GET /api/v1/alerts[?warehouse_id=&since=&limit=]: уведомления, поставленные пользователю
+func (s *Server) handleAlerts(w http.ResponseWriter, r *request) {
+	filter, ok := historyFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.ChatID = r.user.TelegramID
+	filter.Kind = string(events.SlotOpened)
+	s.writeHistory(w, r, "alerts", filter)
+}
+
+// handleHistory — GET /api/v1/history[?warehouse_id=&kind=&since=&limit=]: изменения коэффициентов.
+// Без warehouse_id — по складам пользователя.
+func (s *Server) handleHistory(w http.ResponseWriter, r *request) {
+	filter, ok := historyFilter(w, r)
+	if !ok {
+		return
+	}
+	switch kind := events.Kind(r.URL.Query().Get("kind")); kind {
+	case "", events.SlotOpened, events.SlotClosed, events.CoefficientChanged, events.WarehouseAdded:
+		filter.Kind = string(kind)
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_kind")
+		return
+	}
+
+	if len(filter.WarehouseIDs) == 0 {
+		subs, err := s.loadSubscriptions(r.user)
+		if err != nil {
+			r.log.Error("Ошибка получения подписок для API", logging.Err(err))
+			writeError(w, http.StatusInternalServerError, "internal")
+			return
+		}
+		if filter.WarehouseIDs = subs.all(); len(filter.WarehouseIDs) == 0 {
+			writeJSON(w, http.StatusOK, map[string][]eventJSON{"events": {}})
+			return
+		}
+	}
+	s.writeHistory(w, r, "events", filter)
+}
+
+// writeHistory — выборка из истории под ключом field
+func (s *Server) writeHistory(w http.ResponseWriter, r *request, field string, filter storage.SlotEventFilter) {
+	records, err := s.store.GetSlotEvents(filter)
+	if err != nil {
+		r.log.Error("Ошибка получения истории для API", logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return
+	}
+
+	list := make([]eventJSON, len(records))
+	for i, e := range records {
+		list[i] = eventJSON{
+			Kind:                e.Kind,
+			WarehouseID:         e.WarehouseID,
+			WarehouseName:       e.WarehouseName,
+			BoxTypeID:           e.BoxTypeID,
+			Date:                e.Date,
+			Coefficient:         e.Coefficient,
+			PreviousCoefficient: e.Previous,
+			AllowUnload:         e.AllowUnload,
+			TeamID:              e.TeamID,
+			CreatedAt:           e.CreatedAt.UTC(),
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string][]eventJSON{field: list})
+}
+
+// historyFilter — общие параметры истории: warehouse_id, since (RFC 3339) и limit
+func historyFilter(w http.ResponseWriter, r *request) (storage.SlotEventFilter, bool) {
+	filter := storage.SlotEventFilter{Limit: defaultLimit}
+
+	id, ok, valid := queryInt(r, "warehouse_id")
+	if !valid {
+		writeError(w, http.StatusBadRequest, "invalid_warehouse_id")
+		return filter, false
+	}
+	if ok {
+		filter.WarehouseIDs = []int{id}
+	}
+
+	limit, ok, valid := queryInt(r, "limit")
+	if !valid || ok && (limit < 1 || limit > maxLimit) {
+		writeError(w, http.StatusBadRequest, "invalid_limit")
+		return filter, false
+	}
+	if ok {
+		filter.Limit = limit
+	}
+
+	if since := r.URL.Query().Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_since")
+			return filter, false
+		}
+		filter.Since = t
+	}
+	return filter, true
+}
+
+// queryInt — целый параметр запроса: ok — задан, valid — задан корректно или не задан
+func queryInt(r *request, name string) (value int, ok, valid bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, false, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false, false
+	}
+	return value, true, true
+}
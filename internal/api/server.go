@@ -0,0 +1,182 @@
+// Package api — REST API для пользователей бота: подписки, коэффициенты по их складам и история.
+// Запросы подписываются токеном из /apitoken в заголовке Authorization: Bearer <токен>.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/ratelimit"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+// touchInterval — как часто обновлять время последнего использования токена
+const touchInterval = time.Minute
+
+// Catalog — справочник складов
+type Catalog interface {
+	Name(id int) string
+}
+
+// SnapshotFunc — последние коэффициенты приёмки и время их получения. Должна отвечать из кэша,
+// не обращаясь к WB: API доступно на любом экземпляре и не должно тратить лимиты WB.
+type SnapshotFunc func() ([]wb.Coefficient, time.Time, error)
+
+// Change — изменение подписки через API
+type Change string
+
+const (
+	SubscriptionAdded   Change = "added"
+	SubscriptionUpdated Change = "updated"
+	SubscriptionRemoved Change = "removed"
+)
+
+// NotifyFunc — подтверждение пользователю в Telegram об изменении его подписки через API
+type NotifyFunc func(telegramID int64, change Change, sub storage.Subscription)
+
+// Server — HTTP-сервер REST API
+type Server struct {
+	server   *http.Server
+	store    storage.Repository
+	catalog  Catalog
+	snapshot SnapshotFunc
+	notify   NotifyFunc
+	limiter  *ratelimit.Limiter[uint] // Запросы по ID токена
+}
+
+// NewServer — сервер API по настройкам cfg
+func NewServer(cfg config.API, store storage.Repository, catalog Catalog, snapshot SnapshotFunc, notify NotifyFunc) *Server {
+	s := &Server{
+		store:    store,
+		catalog:  catalog,
+		snapshot: snapshot,
+		notify:   notify,
+		limiter:  ratelimit.New[uint](cfg.TokenRequests, cfg.TokenPeriod),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/subscriptions", s.auth(s.handleSubscriptions))
+	mux.HandleFunc("POST /api/v1/subscriptions", s.auth(s.handleAddSubscription))
+	mux.HandleFunc("PATCH /api/v1/subscriptions/{warehouse_id}", s.auth(s.handleUpdateSubscription))
+	mux.HandleFunc("DELETE /api/v1/subscriptions/{warehouse_id}", s.auth(s.handleRemoveSubscription))
+	mux.HandleFunc("GET /api/v1/coefficients", s.auth(s.handleCoefficients))
+	mux.HandleFunc("GET /api/v1/alerts", s.auth(s.handleAlerts))
+	mux.HandleFunc("GET /api/v1/history", s.auth(s.handleHistory))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found")
+	})
+
+	s.server = &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          logging.StdLogger(slog.Default(), slog.LevelWarn),
+	}
+	return s
+}
+
+// Run — обслуживать запросы до отмены ctx
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("REST API доступно", "listen", s.server.Addr)
+		errCh <- s.server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// request — запрос от пользователя с проверенным токеном
+type request struct {
+	*http.Request
+	user *storage.User
+	log  *slog.Logger
+}
+
+// handlerFunc — обработчик запроса с пользователем
+type handlerFunc func(w http.ResponseWriter, r *request)
+
+// auth — проверить токен из Authorization и его лимит запросов, затем передать пользователя обработчику
+func (s *Server) auth(next handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		t, err := s.store.GetAPITokenByHash(HashToken(strings.TrimSpace(token)))
+		if storage.IsNotFound(err) {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if err != nil {
+			slog.Error("Ошибка проверки токена API", logging.Err(err))
+			writeError(w, http.StatusInternalServerError, "internal")
+			return
+		}
+
+		if limit := s.limiter.Allow(t.ID, time.Now()); !limit.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(limit.RetryAfter.Seconds())+1))
+			writeError(w, http.StatusTooManyRequests, "rate_limited")
+			return
+		}
+
+		user, err := s.store.GetUserByTelegramID(t.TelegramID)
+		if err != nil {
+			slog.Error("Ошибка получения владельца токена API", logging.User(t.TelegramID), logging.Err(err))
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		if user.Banned {
+			writeError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+
+		now := time.Now()
+		if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) > touchInterval {
+			if err := s.store.TouchAPIToken(t.ID, now); err != nil {
+				slog.Warn("Ошибка отметки использования токена API", logging.Err(err))
+			}
+		}
+
+		logger := slog.With(logging.User(user.TelegramID), "method", r.Method, "path", r.URL.Path)
+		logger.Debug("Запрос к API")
+		next(w, &request{Request: r, user: user, log: logger})
+	}
+}
+
+// writeJSON — ответ JSON с кодом status
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError — ответ {"error": code}; коды машиночитаемые, без перевода
+func writeError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
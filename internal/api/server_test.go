@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/ratelimit"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+// testUser — владелец токена в тестах
+const testUser = 42
+
+// fakeCatalog — справочник из двух складов
+type fakeCatalog map[int]string
+
+func (c fakeCatalog) Name(id int) string { return c[id] }
+
+// notification — вызов NotifyFunc
+type notification struct {
+	telegramID int64
+	change     Change
+	sub        storage.Subscription
+}
+
+// testServer — сервер API над хранилищем в памяти, токен пользователя и записанные подтверждения
+type testServer struct {
+	*Server
+	store  *storage.Storage
+	token  string
+	notify []notification
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	st, err := storage.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	if err := st.CreateUser(testUser, "seller"); err != nil {
+		t.Fatal(err)
+	}
+	token, prefix, hash := NewToken()
+	if err := st.CreateAPIToken(&storage.APIToken{TelegramID: testUser, Prefix: prefix, Hash: hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := &testServer{store: st, token: token}
+	snapshot := func() ([]wb.Coefficient, time.Time, error) { return nil, time.Time{}, nil }
+	notify := func(telegramID int64, change Change, sub storage.Subscription) {
+		ts.notify = append(ts.notify, notification{telegramID, change, sub})
+	}
+	cfg := config.API{TokenRequests: 100, TokenPeriod: time.Minute}
+	ts.Server = NewServer(cfg, st, fakeCatalog{507: "Коледино", 117986: "Казань"}, snapshot, notify)
+	return ts
+}
+
+// do — выполнить запрос с токеном пользователя
+func (ts *testServer) do(method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+ts.token)
+	rec := httptest.NewRecorder()
+	ts.server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorCode — поле error из ответа
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("ответ не JSON: %q", rec.Body.String())
+	}
+	return body.Error
+}
+
+func TestTokenRateLimit(t *testing.T) {
+	ts := newTestServer(t)
+	ts.limiter = ratelimit.New[uint](2, time.Minute)
+
+	for i := range 2 {
+		if rec := ts.do("GET", "/api/v1/subscriptions", ""); rec.Code != http.StatusOK {
+			t.Fatalf("запрос %d: код %d, тело %s", i, rec.Code, rec.Body)
+		}
+	}
+
+	rec := ts.do("GET", "/api/v1/subscriptions", "")
+	if rec.Code != http.StatusTooManyRequests || errorCode(t, rec) != "rate_limited" {
+		t.Fatalf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	// Окно закончится чуть меньше чем через минуту, секунды округляются вверх
+	if retry := rec.Header().Get("Retry-After"); retry != "60" && retry != "61" {
+		t.Errorf("Retry-After %q, ожидалось 60", retry)
+	}
+}
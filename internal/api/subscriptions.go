@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+// maxBodySize — больше тело запроса на изменение подписки не бывает
+const maxBodySize = 4 << 10
+
+// filterRequest — порог и тип поставки в теле POST и PATCH, nil — не менять
+type filterRequest struct {
+	MaxCoefficient *int `json:"max_coefficient"`
+	BoxTypeID      *int `json:"box_type_id"`
+}
+
+// apply — перенести заданные поля в подписку, false и код ошибки — значения неверные
+func (f filterRequest) apply(sub *storage.Subscription) (string, bool) {
+	if f.MaxCoefficient != nil {
+		if *f.MaxCoefficient < 0 {
+			return "invalid_max_coefficient", false
+		}
+		sub.MaxCoefficient = *f.MaxCoefficient
+	}
+	if f.BoxTypeID != nil {
+		if _, ok := wb.BoxTypes[*f.BoxTypeID]; !ok && *f.BoxTypeID != 0 {
+			return "invalid_box_type_id", false
+		}
+		sub.BoxTypeID = *f.BoxTypeID
+	}
+	return "", true
+}
+
+// subscriptionJSON — подписка с названием склада из справочника
+func (s *Server) subscriptionJSON(sub storage.Subscription) subscriptionJSON {
+	return subscriptionJSON{
+		ID:             sub.WarehouseID,
+		Name:           s.catalog.Name(sub.WarehouseID),
+		MaxCoefficient: sub.MaxCoefficient,
+		BoxTypeID:      sub.BoxTypeID,
+	}
+}
+
+// handleAddSubscription — POST /api/v1/subscriptions {"warehouse_id", "max_coefficient", "box_type_id"}
+func (s *Server) handleAddSubscription(w http.ResponseWriter, r *request) {
+	var body struct {
+		WarehouseID int `json:"warehouse_id"`
+		filterRequest
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if s.catalog.Name(body.WarehouseID) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "unknown_warehouse")
+		return
+	}
+	sub := storage.Subscription{ChatID: r.user.TelegramID, WarehouseID: body.WarehouseID, MaxCoefficient: storage.DefaultMaxCoefficient}
+	if code, ok := body.apply(&sub); !ok {
+		writeError(w, http.StatusUnprocessableEntity, code)
+		return
+	}
+
+	// Подписка создаётся сразу с порогом: без него планировщик успел бы проверить её с порогом по умолчанию
+	err := s.store.CreateSubscription(&sub)
+	if errors.Is(err, storage.ErrAlreadySubscribed) {
+		writeError(w, http.StatusConflict, "already_subscribed")
+		return
+	}
+	if err != nil {
+		r.log.Error("Ошибка добавления склада через API", logging.Warehouse(body.WarehouseID), logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return
+	}
+
+	r.log.Info("Склад добавлен через API", logging.Warehouse(sub.WarehouseID), "max_coefficient", sub.MaxCoefficient, "box_type_id", sub.BoxTypeID)
+	s.notify(r.user.TelegramID, SubscriptionAdded, sub)
+	writeJSON(w, http.StatusCreated, s.subscriptionJSON(sub))
+}
+
+// handleUpdateSubscription — PATCH /api/v1/subscriptions/{warehouse_id} {"max_coefficient", "box_type_id"}
+func (s *Server) handleUpdateSubscription(w http.ResponseWriter, r *request) {
+	sub, ok := s.pathSubscription(w, r)
+	if !ok {
+		return
+	}
+	var body filterRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if code, ok := body.apply(sub); !ok {
+		writeError(w, http.StatusUnprocessableEntity, code)
+		return
+	}
+
+	if err := s.store.SetSubscriptionFilter(r.user.TelegramID, sub.WarehouseID, sub.MaxCoefficient, sub.BoxTypeID); err != nil {
+		r.log.Error("Ошибка сохранения порога подписки через API", logging.Warehouse(sub.WarehouseID), logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return
+	}
+
+	r.log.Info("Подписка изменена через API", logging.Warehouse(sub.WarehouseID), "max_coefficient", sub.MaxCoefficient, "box_type_id", sub.BoxTypeID)
+	s.notify(r.user.TelegramID, SubscriptionUpdated, *sub)
+	writeJSON(w, http.StatusOK, s.subscriptionJSON(*sub))
+}
+
+// handleRemoveSubscription — DELETE /api/v1/subscriptions/{warehouse_id}
+func (s *Server) handleRemoveSubscription(w http.ResponseWriter, r *request) {
+	sub, ok := s.pathSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.store.RemoveSubscription(r.user.TelegramID, sub.WarehouseID); err != nil {
+		r.log.Error("Ошибка удаления склада через API", logging.Warehouse(sub.WarehouseID), logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return
+	}
+
+	r.log.Info("Склад удалён через API", logging.Warehouse(sub.WarehouseID))
+	s.notify(r.user.TelegramID, SubscriptionRemoved, *sub)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathSubscription — личная подписка на склад из пути запроса
+func (s *Server) pathSubscription(w http.ResponseWriter, r *request) (*storage.Subscription, bool) {
+	id, err := strconv.Atoi(r.PathValue("warehouse_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_warehouse_id")
+		return nil, false
+	}
+
+	sub, err := s.store.GetChatSubscription(r.user.TelegramID, id)
+	if storage.IsNotFound(err) {
+		writeError(w, http.StatusNotFound, "not_subscribed")
+		return nil, false
+	}
+	if err != nil {
+		r.log.Error("Ошибка получения подписки для API", logging.Warehouse(id), logging.Err(err))
+		writeError(w, http.StatusInternalServerError, "internal")
+		return nil, false
+	}
+	return sub, true
+}
+
+// decodeBody — разобрать JSON из тела запроса; неизвестные поля — ошибка, чтобы опечатки не терялись молча
+func decodeBody(w http.ResponseWriter, r *request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body")
+		return false
+	}
+	return true
+}
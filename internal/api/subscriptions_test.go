@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"postavkinBot/internal/storage"
+)
+
+func TestAddSubscription(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do("POST", "/api/v1/subscriptions", `{"warehouse_id":507,"box_type_id":2}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	var got subscriptionJSON
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := subscriptionJSON{ID: 507, Name: "Коледино", MaxCoefficient: storage.DefaultMaxCoefficient, BoxTypeID: 2}
+	if got != want {
+		t.Errorf("ответ %+v, ожидался %+v", got, want)
+	}
+
+	sub, err := ts.store.GetChatSubscription(testUser, 507)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.MaxCoefficient != storage.DefaultMaxCoefficient || sub.BoxTypeID != 2 {
+		t.Errorf("в базе порог %d и тип %d", sub.MaxCoefficient, sub.BoxTypeID)
+	}
+
+	if len(ts.notify) != 1 || ts.notify[0].telegramID != testUser || ts.notify[0].change != SubscriptionAdded || ts.notify[0].sub.WarehouseID != 507 {
+		t.Errorf("подтверждения %+v", ts.notify)
+	}
+}
+
+func TestAddSubscriptionZeroThreshold(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do("POST", "/api/v1/subscriptions", `{"warehouse_id":507,"max_coefficient":0}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	var got subscriptionJSON
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.MaxCoefficient != 0 {
+		t.Errorf("в ответе порог %d, ожидался 0", got.MaxCoefficient)
+	}
+
+	sub, err := ts.store.GetChatSubscription(testUser, 507)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.MaxCoefficient != 0 {
+		t.Errorf("в базе порог %d, ожидался 0", sub.MaxCoefficient)
+	}
+}
+
+func TestAddSubscriptionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		err  string
+	}{
+		{"неизвестный склад", `{"warehouse_id":1}`, http.StatusUnprocessableEntity, "unknown_warehouse"},
+		{"отрицательный порог", `{"warehouse_id":507,"max_coefficient":-1}`, http.StatusUnprocessableEntity, "invalid_max_coefficient"},
+		{"неизвестный тип поставки", `{"warehouse_id":507,"box_type_id":99}`, http.StatusUnprocessableEntity, "invalid_box_type_id"},
+		{"неизвестное поле", `{"warehouse_id":507,"max":1}`, http.StatusBadRequest, "invalid_body"},
+		{"не JSON", `507`, http.StatusBadRequest, "invalid_body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			rec := ts.do("POST", "/api/v1/subscriptions", tt.body)
+			if rec.Code != tt.code || errorCode(t, rec) != tt.err {
+				t.Errorf("код %d, тело %s; ожидался %d %s", rec.Code, rec.Body, tt.code, tt.err)
+			}
+			if len(ts.notify) != 0 {
+				t.Errorf("подтверждение при ошибке: %+v", ts.notify)
+			}
+		})
+	}
+}
+
+func TestAddSubscriptionDuplicate(t *testing.T) {
+	ts := newTestServer(t)
+	if err := ts.store.AddSubscription(testUser, 507); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := ts.do("POST", "/api/v1/subscriptions", `{"warehouse_id":507,"max_coefficient":3}`)
+	if rec.Code != http.StatusConflict || errorCode(t, rec) != "already_subscribed" {
+		t.Fatalf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	sub, err := ts.store.GetChatSubscription(testUser, 507)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.MaxCoefficient != storage.DefaultMaxCoefficient {
+		t.Errorf("повторное добавление изменило порог на %d", sub.MaxCoefficient)
+	}
+	if len(ts.notify) != 0 {
+		t.Errorf("подтверждение при конфликте: %+v", ts.notify)
+	}
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	ts := newTestServer(t)
+	if err := ts.store.AddSubscription(testUser, 507); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := ts.do("PATCH", "/api/v1/subscriptions/507", `{"max_coefficient":0}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	sub, err := ts.store.GetChatSubscription(testUser, 507)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.MaxCoefficient != 0 || sub.BoxTypeID != 0 {
+		t.Errorf("в базе порог %d и тип %d", sub.MaxCoefficient, sub.BoxTypeID)
+	}
+	if len(ts.notify) != 1 || ts.notify[0].change != SubscriptionUpdated || ts.notify[0].sub.MaxCoefficient != 0 {
+		t.Errorf("подтверждения %+v", ts.notify)
+	}
+}
+
+func TestUpdateSubscriptionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		code int
+		err  string
+	}{
+		{"нет подписки", "/api/v1/subscriptions/117986", `{"max_coefficient":0}`, http.StatusNotFound, "not_subscribed"},
+		{"неверный ID", "/api/v1/subscriptions/abc", `{"max_coefficient":0}`, http.StatusBadRequest, "invalid_warehouse_id"},
+		{"отрицательный порог", "/api/v1/subscriptions/507", `{"max_coefficient":-5}`, http.StatusUnprocessableEntity, "invalid_max_coefficient"},
+		{"неизвестный тип поставки", "/api/v1/subscriptions/507", `{"box_type_id":7}`, http.StatusUnprocessableEntity, "invalid_box_type_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t)
+			if err := ts.store.AddSubscription(testUser, 507); err != nil {
+				t.Fatal(err)
+			}
+			rec := ts.do("PATCH", tt.path, tt.body)
+			if rec.Code != tt.code || errorCode(t, rec) != tt.err {
+				t.Errorf("код %d, тело %s; ожидался %d %s", rec.Code, rec.Body, tt.code, tt.err)
+			}
+			if len(ts.notify) != 0 {
+				t.Errorf("подтверждение при ошибке: %+v", ts.notify)
+			}
+		})
+	}
+}
+
+func TestRemoveSubscription(t *testing.T) {
+	ts := newTestServer(t)
+	if err := ts.store.AddSubscription(testUser, 507); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := ts.do("DELETE", "/api/v1/subscriptions/507", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	if _, err := ts.store.GetChatSubscription(testUser, 507); !storage.IsNotFound(err) {
+		t.Errorf("подписка осталась в базе: %v", err)
+	}
+	if len(ts.notify) != 1 || ts.notify[0].change != SubscriptionRemoved || ts.notify[0].sub.WarehouseID != 507 {
+		t.Errorf("подтверждения %+v", ts.notify)
+	}
+
+	rec = ts.do("DELETE", "/api/v1/subscriptions/507", "")
+	if rec.Code != http.StatusNotFound || errorCode(t, rec) != "not_subscribed" {
+		t.Errorf("повторное удаление: код %d, тело %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSubscriptionsUnauthorized(t *testing.T) {
+	ts := newTestServer(t)
+	ts.token = "pk_wrong"
+
+	rec := ts.do("POST", "/api/v1/subscriptions", `{"warehouse_id":507}`)
+	if rec.Code != http.StatusUnauthorized || errorCode(t, rec) != "unauthorized" {
+		t.Errorf("код %d, тело %s", rec.Code, rec.Body)
+	}
+	if len(ts.notify) != 0 {
+		t.Errorf("подтверждение без токена: %+v", ts.notify)
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// tokenPrefix — начало всех токенов, чтобы их было легко узнать в конфигурации и утечках
+const tokenPrefix = "pk_"
+
+// NewToken — новый токен, его начало для показа в списке и хэш для хранения
+func NewToken() (token, prefix, hash string) {
+	b := make([]byte, 24)
+	rand.Read(b)
+	token = tokenPrefix + hex.EncodeToString(b)
+	return token, token[:len(tokenPrefix)+6], HashToken(token)
+}
+
+// HashToken — SHA-256 токена в hex: в базе хранятся только хэши
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
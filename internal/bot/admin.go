@@ -0,0 +1,299 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	minPollInterval = 10   // Секунд; WB отдаёт коэффициенты не чаще 6 раз в минуту
+	maxPollInterval = 3600 // Секунд
+	topWarehouses   = 5    // Сколько складов показывать в /stats
+)
+
+func HandleStats(c *Context) {
+	users, err := c.Storage.CountUsers()
+	if err != nil {
+		c.Log.Error("Ошибка подсчёта пользователей", logging.Err(err))
+		c.Reply(c.T("admin.stats_error"))
+		return
+	}
+	subscriptions, err := c.Storage.CountSubscriptions()
+	if err != nil {
+		c.Log.Error("Ошибка подсчёта подписок", logging.Err(err))
+		c.Reply(c.T("admin.stats_error"))
+		return
+	}
+	top, err := c.Storage.TopWarehouses(topWarehouses)
+	if err != nil {
+		c.Log.Error("Ошибка получения популярных складов", logging.Err(err))
+		c.Reply(c.T("admin.stats_error"))
+		return
+	}
+
+	now := time.Now().In(mskLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, mskLocation)
+	sent, err := c.Storage.CountMessagesSince(storage.KindAlert, storage.MessageSent, today)
+	if err != nil {
+		c.Log.Error("Ошибка подсчёта уведомлений", logging.Err(err))
+		c.Reply(c.T("admin.stats_error"))
+		return
+	}
+	failed, err := c.Storage.CountMessagesSince(storage.KindAlert, storage.MessageDead, today)
+	if err != nil {
+		c.Log.Error("Ошибка подсчёта уведомлений", logging.Err(err))
+		c.Reply(c.T("admin.stats_error"))
+		return
+	}
+
+	requests, failures, err := c.Scheduler.WBStats()
+	if err != nil {
+		c.Log.Error("Ошибка чтения статистики WB API", logging.Err(err))
+		c.Reply(c.T("admin.stats_error"))
+		return
+	}
+	errorRate := 0.0
+	if requests > 0 {
+		errorRate = float64(failures) * 100 / float64(requests)
+	}
+
+	text := c.T("admin.stats",
+		users.Total, users.Blocked, users.Banned,
+		subscriptions,
+		sent, failed,
+		requests, failures, errorRate,
+		c.Scheduler.CheckInterval(),
+	)
+	if len(top) == 0 {
+		text += c.T("admin.top_empty")
+	}
+	for _, w := range top {
+		name := c.Catalog.Name(w.WarehouseID)
+		if name == "" {
+			name = c.T("warehouse.unknown")
+		}
+		text += fmt.Sprintf("- %s (ID: %d): %d\n", name, w.WarehouseID, w.Count)
+	}
+
+	c.Reply(text)
+}
+
+func HandleBroadcast(c *Context) {
+	text := strings.TrimSpace(c.Args)
+	if text == "" {
+		c.Reply(c.T("admin.broadcast_usage"))
+		return
+	}
+
+	recipients, err := c.Storage.GetBroadcastRecipients()
+	if err != nil {
+		c.Log.Error("Ошибка получения получателей рассылки", logging.Err(err))
+		c.Reply(c.T("admin.broadcast_error"))
+		return
+	}
+	draft, err := c.Storage.CreateDraft(c.SenderID(), text)
+	if err != nil {
+		c.Log.Error("Ошибка сохранения черновика рассылки", logging.Err(err))
+		c.Reply(c.T("admin.broadcast_error"))
+		return
+	}
+
+	c.Reply(c.T("admin.broadcast_preview", len(recipients)))
+
+	id := strconv.FormatUint(uint64(draft.ID), 10)
+	preview := tgbotapi.NewMessage(c.ChatID(), text)
+	preview.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("admin.broadcast_send"), "broadcast:send:"+id),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("admin.broadcast_cancel"), "broadcast:cancel:"+id),
+	))
+	c.Send(preview)
+}
+
+// HandleBroadcastCallback — подтверждение или отмена рассылки кнопкой под предпросмотром
+func HandleBroadcastCallback(c *Context) {
+	action, rawID, _ := strings.Cut(c.Args, ":")
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		c.AnswerCallback("")
+		return
+	}
+
+	// Кнопки убираем сразу, чтобы рассылку нельзя было подтвердить дважды
+	c.AnswerCallback("")
+	c.Send(tgbotapi.NewEditMessageReplyMarkup(c.ChatID(), c.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
+
+	draft, err := c.Storage.TakeDraft(uint(id))
+	if storage.IsNotFound(err) {
+		c.Reply(c.T("admin.broadcast_expired"))
+		return
+	}
+	if err != nil {
+		c.Log.Error("Ошибка получения черновика рассылки", "draft_id", id, logging.Err(err))
+		c.Reply(c.T("admin.broadcast_error"))
+		return
+	}
+
+	if action != "send" {
+		c.Reply(c.T("admin.broadcast_cancelled"))
+		return
+	}
+
+	recipients, err := c.Storage.GetBroadcastRecipients()
+	if err != nil {
+		c.Log.Error("Ошибка получения получателей рассылки", logging.Err(err))
+		c.Reply(c.T("admin.broadcast_error"))
+		return
+	}
+
+	queued := 0
+	for _, chatID := range recipients {
+		if err := c.Queue.Enqueue(chatID, storage.KindBroadcast, draft.Text, ""); err != nil {
+			c.Log.Error("Ошибка постановки рассылки в очередь", "recipient_id", chatID, logging.Err(err))
+			continue
+		}
+		queued++
+	}
+
+	c.Log.Info("Администратор запустил рассылку", "recipients", queued)
+	c.Reply(c.T("admin.broadcast_queued", queued))
+}
+
+func HandleUserInfo(c *Context) {
+	telegramID, err := strconv.ParseInt(strings.TrimSpace(c.Args), 10, 64)
+	if err != nil {
+		c.Reply(c.T("admin.user_usage"))
+		return
+	}
+
+	user, err := c.Storage.GetUserByTelegramID(telegramID)
+	if storage.IsNotFound(err) {
+		c.Reply(c.T("admin.user_not_found", telegramID))
+		return
+	}
+	if err != nil {
+		c.Log.Error("Ошибка получения пользователя", "target_id", telegramID, logging.Err(err))
+		c.Reply(c.T("admin.user_error"))
+		return
+	}
+
+	warehouseIDs, err := c.Storage.GetChatWarehouses(telegramID)
+	if err != nil {
+		c.Log.Error("Ошибка получения складов пользователя", "target_id", telegramID, logging.Err(err))
+		c.Reply(c.T("admin.user_error"))
+		return
+	}
+
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.Username != "" {
+		name = "@" + user.Username
+	}
+	lastSeen := "—"
+	if user.LastSeenAt != nil {
+		lastSeen = user.LastSeenAt.In(mskLocation).Format("02.01.2006 15:04")
+	}
+	team := c.T("admin.no")
+	if member, err := c.Storage.GetTeamMember(telegramID); err == nil {
+		if t, err := c.Storage.GetTeam(member.TeamID); err == nil {
+			team = fmt.Sprintf("«%s» (%s)", t.Name, c.T("role."+member.Role))
+		}
+	}
+
+	text := c.T("admin.user_info",
+		name, user.TelegramID,
+		userLanguage(*user),
+		lastSeen,
+		c.yesNo(user.Blocked),
+		c.yesNo(user.Banned),
+		team,
+	)
+	if len(warehouseIDs) == 0 {
+		text += c.T("admin.no")
+	}
+	for _, id := range warehouseIDs {
+		warehouse := c.Catalog.Name(id)
+		if warehouse == "" {
+			warehouse = c.T("warehouse.unknown")
+		}
+		text += fmt.Sprintf("- %s (ID: %d)\n", warehouse, id)
+	}
+
+	c.Reply(text)
+}
+
+func HandleBan(c *Context) {
+	setBanned(c, true)
+}
+
+func HandleUnban(c *Context) {
+	setBanned(c, false)
+}
+
+// setBanned — общая часть /ban и /unban
+func setBanned(c *Context, banned bool) {
+	telegramID, err := strconv.ParseInt(strings.TrimSpace(c.Args), 10, 64)
+	if err != nil {
+		c.Reply(c.T("admin.ban_usage", c.Command))
+		return
+	}
+	if banned && c.Config.IsAdmin(telegramID) {
+		c.Reply(c.T("admin.ban_admin"))
+		return
+	}
+
+	err = c.Storage.SetUserBanned(telegramID, banned)
+	if storage.IsNotFound(err) {
+		c.Reply(c.T("admin.user_not_found", telegramID))
+		return
+	}
+	if err != nil {
+		c.Log.Error("Ошибка изменения бана пользователя", "target_id", telegramID, logging.Err(err))
+		c.Reply(c.T("admin.ban_error"))
+		return
+	}
+
+	c.Log.Info("Администратор изменил бан пользователя", "target_id", telegramID, "banned", banned)
+	if banned {
+		c.Reply(c.T("admin.banned", telegramID))
+	} else {
+		c.Reply(c.T("admin.unbanned", telegramID))
+	}
+}
+
+func HandlePollInterval(c *Context) {
+	args := strings.TrimSpace(c.Args)
+	if args == "" {
+		c.Reply(c.T("admin.interval_current", c.Scheduler.LoadCheckInterval(), minPollInterval, maxPollInterval))
+		return
+	}
+
+	seconds, err := strconv.Atoi(args)
+	if err != nil || seconds < minPollInterval || seconds > maxPollInterval {
+		c.Reply(c.T("admin.interval_invalid", minPollInterval, maxPollInterval))
+		return
+	}
+
+	if err := c.Scheduler.SetCheckInterval(seconds); err != nil {
+		c.Log.Error("Ошибка сохранения интервала проверки", logging.Err(err))
+		c.Reply(c.T("admin.interval_error"))
+		return
+	}
+	c.Log.Info("Администратор изменил интервал проверки", "seconds", seconds)
+	c.Reply(c.T("admin.interval_set", c.Scheduler.CheckInterval()))
+}
+
+// yesNo — «да» или «нет» на языке отправителя
+func (c *Context) yesNo(v bool) string {
+	if v {
+		return c.T("admin.yes")
+	}
+	return c.T("admin.no")
+}
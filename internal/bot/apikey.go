@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/wb"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleAPIKey — свой ключ WB API: /apikey <ключ>, /apikey delete или /apikey для просмотра
+func HandleAPIKey(c *Context) {
+	// Ключ, отправленный в группу, видят все участники — удаляем сообщение до ответа
+	if !c.Chat().IsPrivate() {
+		if strings.TrimSpace(c.Args) != "" {
+			deleteMessage(c)
+		}
+		c.Reply(c.T("error.private_only"))
+		return
+	}
+	// Проверка после удаления: анонимный администратор группы, пост канала или сбой регистрации
+	// не должны оставлять ключ в чате
+	if c.User == nil {
+		c.Reply(c.T("error.users_only"))
+		return
+	}
+	if c.Keys == nil {
+		c.Reply(c.T("apikey.disabled"))
+		return
+	}
+
+	args := strings.TrimSpace(c.Args)
+	switch args {
+	case "":
+		if c.User.WBAPIKey == "" {
+			c.Reply(c.T("apikey.status_none"))
+			return
+		}
+		key, err := c.Keys.Decrypt(c.User.WBAPIKey)
+		if err != nil {
+			c.Log.Error("Ошибка расшифровки ключа WB", logging.Err(err))
+			c.Reply(c.T("apikey.error"))
+			return
+		}
+		text := c.T("apikey.status_set", maskKey(key))
+		if info, err := wb.ParseToken(key); err == nil {
+			text += expiryNote(c, info, time.Now())
+		}
+		c.Reply(text)
+
+	case "delete", "remove", "off":
+		if c.User.WBAPIKey != "" {
+			if key, err := c.Keys.Decrypt(c.User.WBAPIKey); err == nil {
+				c.WBPool.Remove(key)
+				logging.RemoveSecret(key)
+			}
+		}
+		if err := c.Storage.SetUserWBAPIKey(c.User.TelegramID, ""); err != nil {
+			c.Log.Error("Ошибка удаления ключа WB", logging.Err(err))
+			c.Reply(c.T("apikey.error"))
+			return
+		}
+		c.Reply(c.T("apikey.removed"))
+
+	default:
+		setAPIKey(c, args)
+	}
+}
+
+// setAPIKey — проверить ключ в WB, зашифровать и сохранить
+func setAPIKey(c *Context, key string) {
+	// Сообщение с ключом не должно оставаться в истории чата
+	deleteMessage(c)
+
+	// Срок и права видны в самом токене — без запроса к WB
+	now := time.Now()
+	info, err := wb.ParseToken(key)
+	if err != nil {
+		c.Reply(c.T("apikey.malformed"))
+		return
+	}
+	// Вычищать из журнала только то, что действительно похоже на ключ, и только пока он сохранён
+	logging.AddSecret(key)
+	saved := false
+	defer func() {
+		if !saved {
+			logging.RemoveSecret(key)
+		}
+	}()
+	if !info.HasScope(wb.ScopeSupplies) {
+		c.Reply(c.T("apikey.no_scope"))
+		return
+	}
+	if info.Expired(now) {
+		c.Reply(c.T("apikey.expired", formatExpiry(info.ExpiresAt)))
+		return
+	}
+
+	if err := c.WBPool.NewClient(key).Ping(); err != nil {
+		if errors.Is(err, wb.ErrUnauthorized) {
+			c.Reply(c.T("apikey.invalid"))
+			return
+		}
+		c.Log.Warn("Ошибка проверки ключа WB", logging.Err(err))
+		c.Reply(c.T("apikey.check_error"))
+		return
+	}
+
+	encrypted, err := c.Keys.Encrypt(key)
+	if err != nil {
+		c.Log.Error("Ошибка шифрования ключа WB", logging.Err(err))
+		c.Reply(c.T("apikey.error"))
+		return
+	}
+	if c.User.WBAPIKey != "" {
+		if old, err := c.Keys.Decrypt(c.User.WBAPIKey); err == nil && old != key {
+			c.WBPool.Remove(old)
+			logging.RemoveSecret(old)
+		}
+	}
+	if err := c.Storage.SetUserWBAPIKey(c.User.TelegramID, encrypted); err != nil {
+		c.Log.Error("Ошибка сохранения ключа WB", logging.Err(err))
+		c.Reply(c.T("apikey.error"))
+		return
+	}
+
+	saved = true
+
+	c.Log.Info("Пользователь подключил свой ключ WB", "seller_id", info.SellerID, "expires_at", info.ExpiresAt)
+	text := c.T("apikey.saved", maskKey(key)) + expiryNote(c, info, now)
+	c.Reply(text)
+}
+
+// expiryNote — строка о сроке действия ключа, с предупреждением, если он скоро истечёт
+func expiryNote(c *Context, info wb.TokenInfo, now time.Time) string {
+	switch {
+	case info.ExpiresAt.IsZero():
+		return ""
+	case info.ExpiresWithin(now, c.Config.WB.KeyExpiryWarning):
+		return "\n\n" + c.T("apikey.expiring", formatExpiry(info.ExpiresAt))
+	}
+	return "\n\n" + c.T("apikey.expires", formatExpiry(info.ExpiresAt))
+}
+
+// maskKey — последние символы ключа, чтобы его можно было узнать, не показывая целиком
+func maskKey(key string) string {
+	const visible = 6
+	if len(key) <= visible {
+		return "…"
+	}
+	return "…" + key[len(key)-visible:]
+}
+
+// deleteMessage — удалить сообщение с ключом из чата
+func deleteMessage(c *Context) {
+	if _, err := c.Bot.Request(tgbotapi.NewDeleteMessage(c.ChatID(), c.Message.MessageID)); err != nil {
+		c.Log.Warn("Не удалось удалить сообщение с ключом WB", logging.Err(err))
+	}
+}
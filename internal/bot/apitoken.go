@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"postavkinBot/internal/api"
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+// HandleAPIToken — токены REST API: /apitoken, /apitoken new [название], /apitoken revoke <ID>
+func HandleAPIToken(c *Context) {
+	if c.Config.API.Listen == "" {
+		c.Reply(c.T("apitoken.disabled"))
+		return
+	}
+
+	command, arg, _ := strings.Cut(strings.TrimSpace(c.Args), " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(command) {
+	case "":
+		listAPITokens(c)
+	case "new", "create":
+		createAPIToken(c, arg)
+	case "revoke", "delete":
+		id, err := strconv.ParseUint(strings.TrimPrefix(arg, "#"), 10, 0)
+		if err != nil {
+			c.Reply(c.T("apitoken.usage"))
+			return
+		}
+		err = c.Storage.DeleteAPIToken(c.User.TelegramID, uint(id))
+		if storage.IsNotFound(err) {
+			c.Reply(c.T("apitoken.not_found", id))
+			return
+		}
+		if err != nil {
+			c.Log.Error("Ошибка отзыва токена API", logging.Err(err))
+			c.Reply(c.T("apitoken.error"))
+			return
+		}
+		c.Log.Info("Токен API отозван", "token_id", id)
+		c.Reply(c.T("apitoken.revoked", id))
+	default:
+		c.Reply(c.T("apitoken.usage"))
+	}
+}
+
+// listAPITokens — токены пользователя без самих значений
+func listAPITokens(c *Context) {
+	tokens, err := c.Storage.GetAPITokens(c.User.TelegramID)
+	if err != nil {
+		c.Log.Error("Ошибка получения токенов API", logging.Err(err))
+		c.Reply(c.T("apitoken.error"))
+		return
+	}
+	if len(tokens) == 0 {
+		c.Reply(c.T("apitoken.empty") + "\n\n" + c.T("apitoken.usage"))
+		return
+	}
+
+	text := c.T("apitoken.header")
+	for _, t := range tokens {
+		lastUsed := c.T("apitoken.never")
+		if t.LastUsedAt != nil {
+			lastUsed = formatExpiry(*t.LastUsedAt)
+		}
+		text += fmt.Sprintf("\n#%d %s… %s\n   %s", t.ID, t.Prefix, t.Name, c.T("apitoken.item", formatExpiry(t.CreatedAt), lastUsed))
+	}
+	c.Reply(text + "\n\n" + c.T("apitoken.usage"))
+}
+
+// createAPIToken — выпустить токен и показать его один раз
+func createAPIToken(c *Context, name string) {
+	tokens, err := c.Storage.GetAPITokens(c.User.TelegramID)
+	if err != nil {
+		c.Log.Error("Ошибка получения токенов API", logging.Err(err))
+		c.Reply(c.T("apitoken.error"))
+		return
+	}
+	if len(tokens) >= c.Config.API.MaxTokens {
+		c.Reply(c.N("apitoken.limit", c.Config.API.MaxTokens))
+		return
+	}
+
+	token, prefix, hash := api.NewToken()
+	// Открытый токен есть только в этом ответе: в базе лежит хэш
+	logging.AddSecret(token)
+	defer logging.RemoveSecret(token)
+	if len([]rune(name)) > 64 {
+		name = string([]rune(name)[:64])
+	}
+	t := &storage.APIToken{TelegramID: c.User.TelegramID, Name: name, Prefix: prefix, Hash: hash}
+	if err := c.Storage.CreateAPIToken(t); err != nil {
+		c.Log.Error("Ошибка сохранения токена API", logging.Err(err))
+		c.Reply(c.T("apitoken.error"))
+		return
+	}
+
+	c.Log.Info("Выпущен токен API", "token_id", t.ID)
+	c.Reply(c.T("apitoken.created", t.ID, token))
+}
+
+// NotifySubscriptionChanged — подтверждение в Telegram, что подписку изменили через REST API
+func NotifySubscriptionChanged(deps *Deps) api.NotifyFunc {
+	return func(telegramID int64, change api.Change, sub storage.Subscription) {
+		lang := notifyLanguage(deps, telegramID)
+		name := deps.Catalog.Name(sub.WarehouseID)
+		text := i18n.T(lang, "api.subscription_"+string(change), name, sub.WarehouseID)
+		if change != api.SubscriptionRemoved {
+			boxType := i18n.T(lang, "api.box_type_any")
+			if sub.BoxTypeID != 0 {
+				boxType = wb.BoxTypes[sub.BoxTypeID]
+			}
+			text += "\n" + i18n.T(lang, "api.subscription_filter", sub.MaxCoefficient, boxType)
+		}
+		if err := deps.Queue.Enqueue(telegramID, storage.KindSystem, text, ""); err != nil {
+			slog.Error("Ошибка постановки подтверждения изменения через API в очередь", logging.Chat(telegramID), logging.Warehouse(sub.WarehouseID), logging.Err(err))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/wb"
+)
+
+// catalogRefreshInterval — как часто перечитывать справочник: WB добавляет и переименовывает склады
+const catalogRefreshInterval = time.Hour
+
+// errEmptyCatalog — WB вернул пустой список складов
+var errEmptyCatalog = errors.New("пустой справочник складов")
+
+// Catalog — кэш справочника складов WB
+type Catalog struct {
+	client *wb.Client
+
+	mu         sync.RWMutex
+	warehouses []wb.Warehouse
+}
+
+// NewCatalog — создать пустой кэш складов
+func NewCatalog(client *wb.Client) *Catalog {
+	return &Catalog{client: client}
+}
+
+// Load — загрузить справочник складов из API
+func (c *Catalog) Load() error {
+	warehouses, err := c.client.GetWarehouses()
+	if err != nil {
+		return err
+	}
+	if len(warehouses) == 0 {
+		// Пустой ответ не затирает справочник: без него не найти ни одного склада
+		return errEmptyCatalog
+	}
+
+	c.mu.Lock()
+	c.warehouses = warehouses
+	c.mu.Unlock()
+	return nil
+}
+
+// Run — перечитывать справочник раз в catalogRefreshInterval до отмены ctx. Справочник свой
+// у каждого экземпляра — по нему ищут склады и резервные, поэтому обновляется не только на ведущем.
+// При ошибке остаётся прежний список.
+func (c *Catalog) Run(ctx context.Context) error {
+	for lifecycle.Sleep(ctx, catalogRefreshInterval) {
+		if err := c.Load(); err != nil {
+			slog.Warn("Ошибка обновления справочника складов, используется прежний", logging.Err(err))
+			continue
+		}
+		slog.Debug("Справочник складов обновлён", "warehouses", len(c.All()))
+	}
+	return nil
+}
+
+// All — все склады из кэша
+func (c *Catalog) All() []wb.Warehouse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.warehouses
+}
+
+// Name — название склада по ID или пустая строка
+func (c *Catalog) Name(id int) string {
+	return findWarehouseName(c.All(), id)
+}
@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/secret"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender — часть Telegram API, которой пользуются обработчики и планировщик
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)
+}
+
+// Deps — зависимости обработчиков и планировщика
+type Deps struct {
+	Config    *config.Config
+	Storage   storage.Repository
+	WB        *wb.Client  // Клиент с общим ключом бота
+	WBPool    *wb.Pool    // Клиенты по ключам пользователей
+	Keys      *secret.Box // Шифрование ключей пользователей, nil — мастер-ключ не задан
+	Catalog   *Catalog
+	Queue     *Queue
+	Events    *events.Bus // События по складам: Telegram, история и другие получатели
+	Scheduler *Scheduler
+}
+
+// Context — контекст обработки одного апдейта
+type Context struct {
+	context.Context
+	*Deps
+
+	Bot      Sender
+	BotName  string                  // Username бота для ссылок и разбора /command@botname
+	Update   tgbotapi.Update         // Исходный апдейт
+	Message  *tgbotapi.Message       // Сообщение, пост канала или сообщение с нажатой кнопкой
+	Callback *tgbotapi.CallbackQuery // Нажатие inline-кнопки, если есть
+	Command  string                  // Имя команды без слэша и @botname
+	Args     string                  // Аргументы команды, текст сообщения или data кнопки без префикса
+	Answer   string                  // Ответ на вопрос из Ask, пусто — команда вызвана сама
+	User     *storage.User           // Отправитель из базы, заполняется middleware Register
+	NewUser  bool                    // Пользователь зарегистрирован этим апдейтом
+	Lang     string                  // Язык ответов, заполняется middleware Register
+	Log      *slog.Logger            // Журнал с request_id, chat_id и user_id апдейта
+
+	router *Router
+}
+
+// ChatID — чат, в котором идёт диалог
+func (c *Context) ChatID() int64 {
+	if c.Message == nil {
+		return 0
+	}
+	return c.Message.Chat.ID
+}
+
+// Chat — чат, в котором идёт диалог
+func (c *Context) Chat() *tgbotapi.Chat {
+	if c.Message == nil {
+		return nil
+	}
+	return c.Message.Chat
+}
+
+// From — пользователь, отправивший сообщение или нажавший кнопку (nil для постов канала)
+func (c *Context) From() *tgbotapi.User {
+	if c.Callback != nil {
+		return c.Callback.From
+	}
+	if c.Message == nil {
+		return nil
+	}
+	return c.Message.From
+}
+
+// SenderID — ID пользователя или чата, от имени которого пришёл апдейт
+func (c *Context) SenderID() int64 {
+	if from := c.From(); from != nil {
+		return from.ID
+	}
+	if c.Message == nil {
+		return 0
+	}
+	return senderID(c.Message)
+}
+
+// T — текст на языке отправителя
+func (c *Context) T(key string, args ...interface{}) string {
+	return i18n.T(c.Lang, key, args...)
+}
+
+// N — текст на языке отправителя с формой множественного числа по n
+func (c *Context) N(key string, n int, args ...interface{}) string {
+	return i18n.N(c.Lang, key, n, args...)
+}
+
+// Reply — отправить текст в текущий чат
+func (c *Context) Reply(text string) {
+	c.Send(tgbotapi.NewMessage(c.ChatID(), text))
+}
+
+// Send — отправить произвольное сообщение с логированием ошибки
+func (c *Context) Send(msg tgbotapi.Chattable) {
+	if _, err := c.Bot.Send(msg); err != nil {
+		c.Log.Error("Ошибка отправки сообщения", logging.Err(err))
+		markIfBlocked(c.Storage, c.ChatID(), err)
+	}
+}
+
+// Ask — задать вопрос из обработчика команды. Следующий текст того же отправителя в этом чате
+// придёт в ту же команду с прежними c.Args и текстом в c.Answer, на каком бы экземпляре бота ни был получен.
+func (c *Context) Ask(prompt string) {
+	if err := c.router.ask(c); err != nil {
+		c.Log.Error("Ошибка сохранения вопроса", logging.Err(err))
+		c.Reply(c.T("error.internal"))
+		return
+	}
+	c.Reply(prompt)
+}
+
+// AnswerCallback — убрать «часики» на нажатой кнопке, при необходимости показав уведомление
+func (c *Context) AnswerCallback(text string) {
+	if c.Callback == nil {
+		return
+	}
+	if _, err := c.Bot.Request(tgbotapi.NewCallback(c.Callback.ID, text)); err != nil {
+		c.Log.Error("Ошибка ответа на нажатие кнопки", logging.Err(err))
+	}
+}
@@ -1,107 +1,735 @@
 package bot
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"postavkinBot/internal/alert"
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/metrics"
+	"postavkinBot/internal/storage"
 	"postavkinBot/internal/wb"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-var (
-	notifiedWarehouses = make(map[int64]map[int]int64) // userID -> warehouseID -> timestamp
-	checkInterval      = 15 * time.Second              // Проверка каждые 15 секунд
-	repeatNotifyDelay  = time.Minute                   // Повторное уведомление через 1 минуту
-	cachedWarehouses   []wb.Warehouse                  // Кэш складов
-)
+var mskLocation = time.FixedZone("MSK", 3*60*60) // Часовой пояс для тихих часов
 
-// StartCronJob — запуск задач проверки
-func StartCronJob(bot *tgbotapi.BotAPI) {
-	var err error
-	cachedWarehouses, err = WbClient.GetWarehouses()
-	if err != nil {
-		log.Fatalf("Ошибка получения списка складов при старте: %v", err)
+// Scheduler — периодическая проверка коэффициентов и постановка уведомлений в очередь
+type Scheduler struct {
+	deps *Deps
+
+	mu            sync.Mutex
+	checkInterval time.Duration
+	detector      *events.Detector // Изменения по общему ключу, только из checkWarehouses
+
+	keysMu  sync.Mutex
+	apiKeys map[string]string // Зашифрованный ключ WB -> расшифрованный
+
+	notifyMu           sync.Mutex
+	notifiedWarehouses map[notificationKey]int64 // Получатель и склад -> время уведомления, копия таблицы notifications
+
+	snapshotMu sync.Mutex
+	snapshot   []wb.Coefficient // Последний ответ WB по общему ключу: свой или ведущего из базы
+	snapshotAt time.Time
+	loadedAt   time.Time         // Когда снимок последний раз сверялся с базой
+	savedHash  [sha256.Size]byte // Хэш снимка, записанного в базу, только из checkWarehouses
+}
+
+// NewScheduler — создать планировщик
+func NewScheduler(deps *Deps) *Scheduler {
+	return &Scheduler{
+		deps:               deps,
+		checkInterval:      deps.Config.Scheduler.CheckInterval,
+		notifiedWarehouses: make(map[notificationKey]int64),
+		apiKeys:            make(map[string]string),
+		detector:           events.NewDetector(),
+	}
+}
+
+// Run — цикл проверки складов, работает до отмены ctx
+func (s *Scheduler) Run(ctx context.Context) error {
+	for tick := int64(1); ; tick++ {
+		logger := slog.With(slog.Int64(logging.KeyTickID, tick))
+		logger.Debug("Проверка складов по кэшу")
+		started := time.Now()
+		result := s.checkWarehouses(ctx, logger)
+		elapsed := time.Since(started)
+		s.saveWBStats()
+		metrics.SchedulerTickDuration.Observe(elapsed.Seconds())
+
+		interval := s.LoadCheckInterval()
+		attrs := []any{"duration", elapsed, "recipients", result.recipients, "warehouses", result.warehouses, "events", result.events, "alerts", result.alerts}
+		if elapsed > interval {
+			logger.Warn("Проверка складов дольше интервала проверки", append(attrs, "interval", interval)...)
+		} else {
+			logger.Debug("Проверка складов завершена", attrs...)
+		}
+
+		if !lifecycle.Sleep(ctx, interval) {
+			return nil
+		}
 	}
+}
+
+// CheckInterval — текущий интервал проверки
+func (s *Scheduler) CheckInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkInterval
+}
+
+// checkIntervalSetting — настройка с интервалом проверки в секундах, заданным через /pollinterval
+const checkIntervalSetting = "scheduler.check_interval"
 
-	go func() {
-		for {
-			log.Println("[CRON] Проверка складов по кэшу...")
-			checkWarehouses(bot)
-			time.Sleep(checkInterval)
+// LoadCheckInterval — интервал проверки из базы: /pollinterval мог выполнить другой экземпляр.
+// Пока интервал не задан или база недоступна, остаётся прежний.
+func (s *Scheduler) LoadCheckInterval() time.Duration {
+	setting, err := s.deps.Storage.GetSetting(checkIntervalSetting)
+	if err != nil {
+		if !storage.IsNotFound(err) {
+			slog.Warn("Ошибка чтения интервала проверки", logging.Err(err))
 		}
-	}()
+		return s.CheckInterval()
+	}
+	seconds, err := strconv.Atoi(setting.Value)
+	if err != nil || seconds <= 0 {
+		slog.Warn("Некорректный интервал проверки в базе", "value", setting.Value)
+		return s.CheckInterval()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkInterval = time.Duration(seconds) * time.Second
+	return s.checkInterval
 }
 
-// SetCheckInterval — установить интервал проверки
-func SetCheckInterval(seconds int) {
+// SetCheckInterval — сохранить интервал проверки в базе; ведущий подхватит его на следующей проверке
+func (s *Scheduler) SetCheckInterval(seconds int) error {
 	if seconds <= 0 {
 		seconds = 1
 	}
-	checkInterval = time.Duration(seconds) * time.Second
-	log.Printf("Интервал проверки изменён на каждые %d секунд\n", seconds)
+	if err := s.deps.Storage.SetSetting(checkIntervalSetting, strconv.Itoa(seconds)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.checkInterval = time.Duration(seconds) * time.Second
+	s.mu.Unlock()
+
+	slog.Info("Интервал проверки изменён", "seconds", seconds)
+	return nil
 }
 
-// checkWarehouses — проверка лимитов всех пользователей
-func checkWarehouses(bot *tgbotapi.BotAPI) {
-	users, err := Storage.GetAllUsers()
+// wbStatsSetting — запросы и ошибки WB API ведущего экземпляра с его запуска, через пробел.
+// WB запрашивает только ведущий, поэтому счётчики остальных экземпляров для /stats бесполезны.
+const wbStatsSetting = "wb.stats"
+
+// saveWBStats — сохранить счётчики WB API в базе для /stats на любом экземпляре
+func (s *Scheduler) saveWBStats() {
+	requests, failures := s.deps.WBPool.Stats()
+	value := strconv.FormatInt(requests, 10) + " " + strconv.FormatInt(failures, 10)
+	if err := s.deps.Storage.SetSetting(wbStatsSetting, value); err != nil {
+		slog.Warn("Ошибка сохранения статистики WB API", logging.Err(err))
+	}
+}
+
+// WBStats — запросы и ошибки WB API ведущего экземпляра из базы; нули, пока ведущий их не сохранил
+func (s *Scheduler) WBStats() (requests, failures int64, err error) {
+	setting, err := s.deps.Storage.GetSetting(wbStatsSetting)
+	if storage.IsNotFound(err) {
+		return 0, 0, nil
+	}
 	if err != nil {
-		log.Printf("Ошибка получения пользователей: %v", err)
+		return 0, 0, err
+	}
+	rawRequests, rawFailures, _ := strings.Cut(setting.Value, " ")
+	if requests, err = strconv.ParseInt(rawRequests, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("некорректная статистика WB API %q: %w", setting.Value, err)
+	}
+	if failures, err = strconv.ParseInt(rawFailures, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("некорректная статистика WB API %q: %w", setting.Value, err)
+	}
+	return requests, failures, nil
+}
+
+// errNoSnapshot — ведущий экземпляр ещё не получил коэффициенты
+var errNoSnapshot = errors.New("коэффициенты приёмки ещё не получены")
+
+// Snapshot — последние коэффициенты по общему ключу и время их получения ведущим экземпляром.
+// WB отсюда не запрашивается: ведущий сохраняет каждый ответ в базе, а остальные экземпляры
+// читают его оттуда не чаще раза за интервал проверки.
+func (s *Scheduler) Snapshot() ([]wb.Coefficient, time.Time, error) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	interval := s.CheckInterval()
+	if time.Since(s.snapshotAt) > interval && time.Since(s.loadedAt) > interval {
+		s.loadSnapshot()
+	}
+	if s.snapshot == nil {
+		return nil, time.Time{}, errNoSnapshot
+	}
+	return s.snapshot, s.snapshotAt, nil
+}
+
+// loadSnapshot — взять из базы снимок ведущего, если он новее своего. Вызывается под snapshotMu.
+func (s *Scheduler) loadSnapshot() {
+	s.loadedAt = time.Now()
+
+	fetchedAt, err := s.deps.Storage.GetCoefficientSnapshotTime()
+	if err != nil {
+		if !storage.IsNotFound(err) {
+			slog.Warn("Ошибка чтения снимка коэффициентов", logging.Err(err))
+		}
+		return
+	}
+	if !fetchedAt.After(s.snapshotAt) {
 		return
 	}
 
-	coefficients, err := WbClient.GetAcceptanceCoefficients()
+	snapshot, err := s.deps.Storage.GetCoefficientSnapshot()
 	if err != nil {
-		if isTooManyRequestsError(err) {
-			log.Println("[WARN] Слишком много запросов (429), делаем паузу 1 минуту...")
-			time.Sleep(1 * time.Minute)
-		} else {
-			log.Printf("Ошибка получения коэффициентов приёмки: %v", err)
+		slog.Warn("Ошибка чтения снимка коэффициентов", logging.Err(err))
+		return
+	}
+	var coefficients []wb.Coefficient
+	if err := json.Unmarshal([]byte(snapshot.Data), &coefficients); err != nil {
+		slog.Error("Некорректный снимок коэффициентов в базе", logging.Err(err))
+		return
+	}
+	s.snapshot, s.snapshotAt = coefficients, snapshot.FetchedAt
+}
+
+// setSnapshot — запомнить коэффициенты из проверки и сохранить их в базе для других экземпляров.
+// Если WB вернул то же, что в прошлый раз, обновляется только время получения.
+func (s *Scheduler) setSnapshot(coefficients []wb.Coefficient, at time.Time) {
+	s.snapshotMu.Lock()
+	s.snapshot, s.snapshotAt = coefficients, at
+	s.snapshotMu.Unlock()
+
+	data, err := json.Marshal(coefficients)
+	if err != nil {
+		slog.Error("Ошибка сохранения снимка коэффициентов", logging.Err(err))
+		return
+	}
+	hash := sha256.Sum256(data)
+	if hash == s.savedHash {
+		err = s.deps.Storage.TouchCoefficientSnapshot(at)
+		if !storage.IsNotFound(err) {
+			if err != nil {
+				slog.Warn("Ошибка сохранения снимка коэффициентов", logging.Err(err))
+			}
+			return
 		}
+	}
+	if err := s.deps.Storage.SaveCoefficientSnapshot(string(data), at); err != nil {
+		slog.Warn("Ошибка сохранения снимка коэффициентов", logging.Err(err))
 		return
 	}
+	s.savedHash = hash
+}
 
-	for _, user := range users {
-		checkUserWarehouses(bot, user.TelegramID, cachedWarehouses, coefficients)
+// recipient — получатель уведомлений: чат или участник команды в личном чате
+type recipient struct {
+	chatID int64
+	teamID uint // Команда, по чьему списку получатель проверяется, 0 — свои подписки чата
+	format alertFormat
+
+	maxCoefficient int // Порог и тип поставки из подписки на склад
+	boxTypeID      int
+}
+
+// subscriberBatch — получатель и его подписки, которые проверяются по одному ответу WB
+type subscriberBatch struct {
+	recipient     recipient
+	subscriptions []storage.Subscription
+}
+
+// keyGroup — получатели с одним зашифрованным ключом WB пользователя
+type keyGroup struct {
+	encrypted  string
+	telegramID int64 // Владелец ключа, для журнала
+	batches    []subscriberBatch
+}
+
+// coefficientGroup — получатели, которых проверяем по одному ответу WB: общему или по ключу пользователя
+type coefficientGroup struct {
+	coefficients map[int][]wb.Coefficient // Склад -> коэффициенты склада в порядке ответа
+	subscribers  map[int][]recipient      // Склад -> получатели, которые его отслеживают
+}
+
+// newCoefficientGroup — группа по ответу WB
+func newCoefficientGroup(coefficients []wb.Coefficient) *coefficientGroup {
+	group := &coefficientGroup{
+		coefficients: make(map[int][]wb.Coefficient, len(coefficients)),
+		subscribers:  make(map[int][]recipient),
+	}
+	for _, c := range coefficients {
+		group.coefficients[c.WarehouseID] = append(group.coefficients[c.WarehouseID], c)
+	}
+	return group
+}
+
+// subscribe — добавить получателя к складам подписок с их порогами
+func (g *coefficientGroup) subscribe(r recipient, subscriptions []storage.Subscription) {
+	for _, sub := range subscriptions {
+		r.maxCoefficient, r.boxTypeID = sub.MaxCoefficient, sub.BoxTypeID
+		g.subscribers[sub.WarehouseID] = append(g.subscribers[sub.WarehouseID], r)
 	}
 }
 
-// checkUserWarehouses — проверка складов одного пользователя
-func checkUserWarehouses(bot *tgbotapi.BotAPI, telegramID int64, allWarehouses []wb.Warehouse, coefficients []wb.Coefficient) {
-	warehouseIDs, err := Storage.GetUserWarehouses(telegramID)
+// coefficient — первый коэффициент склада с типом поставки получателя
+func (g *coefficientGroup) coefficient(warehouseID int, r recipient) (wb.Coefficient, bool) {
+	for _, c := range g.coefficients[warehouseID] {
+		if r.boxTypeID == 0 || c.BoxTypeID == r.boxTypeID {
+			return c, true
+		}
+	}
+	return wb.Coefficient{}, false
+}
+
+// open — открыта ли приёмка для получателя: коэффициент не выше его порога и выгрузка разрешена
+func (r recipient) open(c wb.Coefficient) bool {
+	return events.OpenFor(c, r.maxCoefficient)
+}
+
+// checkResult — итоги одной проверки для журнала
+type checkResult struct {
+	recipients int   // Проверено получателей
+	warehouses int   // Проверено пар «ответ WB — склад»
+	events     int   // Изменений по складам
+	alerts     int64 // Уведомлений подписчикам
+}
+
+// checkWarehouses — проверка лимитов всех чатов и команд. Подписки и участники команд загружаются
+// одним запросом, ключи пользователей и склады проверяются параллельно в scheduler.workers потоков.
+func (s *Scheduler) checkWarehouses(ctx context.Context, logger *slog.Logger) (result checkResult) {
+	chats, err := s.deps.Storage.GetAllChats()
 	if err != nil {
-		log.Printf("Ошибка получения складов пользователя %d: %v", telegramID, err)
+		logger.Error("Ошибка получения чатов", logging.Err(err))
 		return
 	}
 
-	now := time.Now().Unix()
+	teams, err := s.deps.Storage.GetAllTeams()
+	if err != nil {
+		logger.Error("Ошибка получения команд", logging.Err(err))
+		return
+	}
 
-	for _, id := range warehouseIDs {
-		coefficient := findCoefficient(coefficients, id)
-		if coefficient != nil && (coefficient.Coefficient == 0 || coefficient.Coefficient == 1) && coefficient.AllowUnload {
-			lastNotified := getLastNotificationTime(telegramID, id)
+	users, err := s.deps.Storage.GetAllUsers()
+	if err != nil {
+		logger.Error("Ошибка получения пользователей", logging.Err(err))
+		return
+	}
+	usersByID := make(map[int64]storage.User, len(users))
+	for _, user := range users {
+		usersByID[user.TelegramID] = user
+	}
 
-			if lastNotified == 0 || now-lastNotified >= int64(repeatNotifyDelay.Seconds()) {
-				text := fmt.Sprintf(
-					"📦 Лимит на складе: %s (ID: %d)\n📈 Коэффициент: %d\n🗓 Дата: %s",
-					coefficient.WarehouseName,
-					coefficient.WarehouseID,
-					coefficient.Coefficient,
-					coefficient.Date,
-				)
+	subscriptions, err := s.deps.Storage.GetAllSubscriptions()
+	if err != nil {
+		logger.Error("Ошибка получения подписок", logging.Err(err))
+		return
+	}
+	chatSubscriptions := make(map[int64][]storage.Subscription)
+	teamSubscriptions := make(map[uint][]storage.Subscription)
+	for _, sub := range subscriptions {
+		if sub.TeamID != 0 {
+			teamSubscriptions[sub.TeamID] = append(teamSubscriptions[sub.TeamID], sub)
+		} else {
+			chatSubscriptions[sub.ChatID] = append(chatSubscriptions[sub.ChatID], sub)
+		}
+	}
 
-				msg := tgbotapi.NewMessage(telegramID, text)
-				if _, err := bot.Send(msg); err != nil {
-					log.Printf("Ошибка отправки сообщения пользователю %d: %v", telegramID, err)
+	members, err := s.deps.Storage.GetAllTeamMembers()
+	if err != nil {
+		logger.Error("Ошибка получения участников команд", logging.Err(err))
+		return
+	}
+	teamMembers := make(map[uint][]storage.TeamMember)
+	for _, member := range members {
+		teamMembers[member.TeamID] = append(teamMembers[member.TeamID], member)
+	}
+
+	coefficients, err := s.deps.WB.GetAcceptanceCoefficients()
+	if err != nil {
+		if isTooManyRequestsError(err) {
+			pause := s.deps.Config.Scheduler.RateLimitPause
+			logger.Warn("Слишком много запросов к WB (429), делаем паузу", "pause", pause)
+			lifecycle.Sleep(ctx, pause)
+		} else {
+			logger.Error("Ошибка получения коэффициентов приёмки", logging.Err(err))
+		}
+		return
+	}
+	metrics.MarkCoefficientsFetched()
+
+	now := time.Now()
+	s.setSnapshot(coefficients, now)
+	s.loadNotifications(logger)
+	changes := s.detector.Detect(coefficients, now)
+	for _, e := range changes {
+		if err := s.deps.Events.Publish(ctx, e); err != nil {
+			logger.Error("Ошибка обработки события склада", "kind", e.Kind, logging.Warehouse(e.Coefficient.WarehouseID), logging.Err(err))
+		}
+	}
+	result.events = len(changes)
+
+	// Получатели со своими ключами WB проверяются по ответу на их ключ: ключи расшифровываются
+	// и запрашиваются уже в потоках, здесь получатели только раскладываются по ключам
+	shared := newCoefficientGroup(coefficients)
+	var keyed []*keyGroup
+	byKey := make(map[string]*keyGroup)
+	subscribe := func(user storage.User, r recipient, subscriptions []storage.Subscription) {
+		if user.WBAPIKey == "" || s.deps.Keys == nil {
+			shared.subscribe(r, subscriptions)
+			return
+		}
+		group, ok := byKey[user.WBAPIKey]
+		if !ok {
+			group = &keyGroup{encrypted: user.WBAPIKey, telegramID: user.TelegramID}
+			byKey[user.WBAPIKey] = group
+			keyed = append(keyed, group)
+		}
+		group.batches = append(group.batches, subscriberBatch{recipient: r, subscriptions: subscriptions})
+	}
+
+	for _, chat := range chats {
+		subscriptions := chatSubscriptions[chat.ChatID]
+		if len(subscriptions) == 0 {
+			continue
+		}
+
+		// Личный чат совпадает с пользователем — учитываем его тихие часы, паузу, язык и шаблон
+		format := alertFormat{Lang: i18n.Default}
+		if user, ok := usersByID[chat.ChatID]; ok {
+			if user.Banned || !notificationsAllowed(user, now) {
+				continue
+			}
+			format = userAlertFormat(user)
+		}
+		// В личном чате — по ключу пользователя, в группах — по общему
+		subscribe(usersByID[chat.ChatID], recipient{chatID: chat.ChatID, format: format}, subscriptions)
+		result.recipients++
+	}
+
+	// Общий список команды рассылается каждому участнику в личный чат
+	for _, team := range teams {
+		subscriptions := teamSubscriptions[team.ID]
+		if len(subscriptions) == 0 {
+			continue
+		}
+
+		for _, member := range teamMembers[team.ID] {
+			format := alertFormat{Lang: i18n.Default}
+			if user, ok := usersByID[member.TelegramID]; ok {
+				if user.Blocked || user.Banned || !notificationsAllowed(user, now) {
+					continue
 				}
-				markAsNotified(telegramID, id, now)
+				format = userAlertFormat(user)
 			}
-		} else {
-			unmarkNotification(telegramID, id)
+			// Шаблон команды важнее личного шаблона участника
+			if team.AlertTemplate != "" {
+				format.Template, format.ParseMode = team.AlertTemplate, team.AlertFormat
+			}
+			subscribe(usersByID[member.TelegramID], recipient{chatID: member.TelegramID, teamID: team.ID, format: format}, subscriptions)
+			result.recipients++
 		}
 	}
+	metrics.RecipientsEvaluated.Add(float64(result.recipients))
+
+	groups := append([]*coefficientGroup{shared}, s.userGroups(ctx, logger, shared, keyed)...)
+	s.pruneKeys(keyed)
+	result.alerts = s.evaluate(ctx, logger, groups, now)
+	for _, group := range groups {
+		result.warehouses += len(group.subscribers)
+	}
+	return result
+}
+
+// keyFetch — запрос коэффициентов по одному ключу за проверку
+type keyFetch struct {
+	once         sync.Once
+	coefficients []wb.Coefficient
+	err          error
+}
+
+// userGroups — расшифровать ключи пользователей и запросить по ним коэффициенты в scheduler.workers потоков,
+// затем разложить получателей по группам. Одинаковые ключи запрашиваются один раз за проверку; если ключ
+// не расшифровался или WB ответил ошибкой, получатели проверяются по общему ответу shared.
+func (s *Scheduler) userGroups(ctx context.Context, logger *slog.Logger, shared *coefficientGroup, keyed []*keyGroup) []*coefficientGroup {
+	if len(keyed) == 0 {
+		return nil
+	}
+
+	type fetched struct {
+		key          string
+		coefficients []wb.Coefficient // nil — проверять по общему ответу
+	}
+	results := make([]fetched, len(keyed))
+
+	var fetchMu sync.Mutex
+	fetches := make(map[string]*keyFetch)
+	fetch := func(key string) ([]wb.Coefficient, error) {
+		fetchMu.Lock()
+		f, ok := fetches[key]
+		if !ok {
+			f = &keyFetch{}
+			fetches[key] = f
+		}
+		fetchMu.Unlock()
+
+		f.once.Do(func() {
+			f.coefficients, f.err = s.deps.WBPool.Get(key).GetAcceptanceCoefficients()
+		})
+		return f.coefficients, f.err
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.deps.Config.Scheduler.Workers, len(keyed)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				group := keyed[i]
+				key := s.userKey(logger, group.telegramID, group.encrypted)
+				if key == "" {
+					continue
+				}
+				coefficients, err := fetch(key)
+				if err != nil {
+					logger.Warn("Ошибка получения коэффициентов по ключу пользователя, используется общий ключ",
+						logging.User(group.telegramID), logging.Err(err))
+					continue
+				}
+				results[i] = fetched{key: key, coefficients: coefficients}
+			}
+		}()
+	}
+
+feed:
+	for i := range keyed {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var groups []*coefficientGroup
+	byKey := make(map[string]*coefficientGroup)
+	for i, kg := range keyed {
+		group := shared
+		if res := results[i]; res.coefficients != nil {
+			var ok bool
+			if group, ok = byKey[res.key]; !ok {
+				group = newCoefficientGroup(res.coefficients)
+				byKey[res.key] = group
+				groups = append(groups, group)
+			}
+		}
+		for _, b := range kg.batches {
+			group.subscribe(b.recipient, b.subscriptions)
+		}
+	}
+	return groups
+}
+
+// evaluate — проверить склады всех групп в scheduler.workers потоков, возвращает число уведомлений подписчикам
+func (s *Scheduler) evaluate(ctx context.Context, logger *slog.Logger, groups []*coefficientGroup, now time.Time) int64 {
+	type job struct {
+		group       *coefficientGroup
+		warehouseID int
+	}
+
+	jobs := make(chan job)
+	var alerts atomic.Int64
+	var wg sync.WaitGroup
+	for range s.deps.Config.Scheduler.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				alerts.Add(int64(s.checkWarehouse(ctx, logger, j.group, j.warehouseID, now)))
+			}
+		}()
+	}
+
+feed:
+	for _, group := range groups {
+		for id := range group.subscribers {
+			select {
+			case jobs <- job{group: group, warehouseID: id}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return alerts.Load()
+}
+
+// checkWarehouse — проверить один склад группы и опубликовать уведомления его получателям, возвращает их число.
+// Об открытой приёмке получатель узнаёт сразу и затем раз в scheduler.repeat_notify_delay, пока она открыта.
+func (s *Scheduler) checkWarehouse(ctx context.Context, logger *slog.Logger, group *coefficientGroup, warehouseID int, now time.Time) (alerts int) {
+	for _, r := range group.subscribers[warehouseID] {
+		coefficient, ok := group.coefficient(warehouseID, r)
+		open := ok && r.open(coefficient)
+
+		e := events.Event{
+			Coefficient: coefficient,
+			Recipient: &events.Recipient{
+				ChatID:    r.chatID,
+				TeamID:    r.teamID,
+				Lang:      r.format.Lang,
+				Template:  r.format.Template,
+				ParseMode: r.format.ParseMode,
+			},
+			At: now,
+		}
+
+		if !open {
+			if s.unmarkNotification(r, warehouseID) && ok {
+				e.Kind = events.SlotClosed
+				s.publish(ctx, logger, e)
+			}
+			continue
+		}
+
+		claimed, repeat := s.claimNotification(r, warehouseID, now.Unix())
+		if !claimed {
+			continue
+		}
+		e.Kind, e.Repeat = events.SlotOpened, repeat
+		if !s.publish(ctx, logger, e) {
+			// Следующая проверка попробует снова
+			s.unmarkNotification(r, warehouseID)
+			continue
+		}
+		alerts++
+	}
+	return alerts
+}
+
+// publish — опубликовать уведомление получателю, false — его не удалось доставить
+func (s *Scheduler) publish(ctx context.Context, logger *slog.Logger, e events.Event) bool {
+	if err := s.deps.Events.Publish(ctx, e); err != nil {
+		logger.Error("Ошибка доставки уведомления", "kind", e.Kind, logging.Chat(e.Recipient.ChatID),
+			logging.Warehouse(e.Coefficient.WarehouseID), logging.Err(err))
+		return false
+	}
+	return true
+}
+
+// userKey — расшифрованный ключ WB пользователя telegramID; при ошибке — "", общий ключ
+func (s *Scheduler) userKey(logger *slog.Logger, telegramID int64, encrypted string) string {
+	s.keysMu.Lock()
+	key, ok := s.apiKeys[encrypted]
+	s.keysMu.Unlock()
+	if ok {
+		return key
+	}
+
+	key, err := s.deps.Keys.Decrypt(encrypted)
+	if err != nil {
+		logger.Error("Ошибка расшифровки ключа WB", logging.User(telegramID), logging.Err(err))
+		return ""
+	}
+	logging.AddSecret(key)
+
+	s.keysMu.Lock()
+	s.apiKeys[encrypted] = key
+	s.keysMu.Unlock()
+	return key
+}
+
+// pruneKeys — забыть расшифрованные ключи, которых нет в этой проверке, и их клиентов WB:
+// пользователь удалил или заменил ключ, возможно, через другой экземпляр бота
+func (s *Scheduler) pruneKeys(keyed []*keyGroup) {
+	used := make(map[string]bool, len(keyed))
+	for _, group := range keyed {
+		used[group.encrypted] = true
+	}
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	stale := make(map[string]bool)
+	for encrypted, key := range s.apiKeys {
+		if !used[encrypted] {
+			delete(s.apiKeys, encrypted)
+			stale[key] = true
+		}
+	}
+	// Тот же ключ мог остаться у другого пользователя с другим шифротекстом
+	for _, key := range s.apiKeys {
+		delete(stale, key)
+	}
+	for key := range stale {
+		s.deps.WBPool.Remove(key)
+		logging.RemoveSecret(key)
+	}
+}
+
+// notificationsAllowed — можно ли сейчас отправлять уведомления пользователю
+func notificationsAllowed(user storage.User, now time.Time) bool {
+	if user.MutedUntil != nil && now.Before(*user.MutedUntil) {
+		return false
+	}
+
+	if user.QuietFrom == user.QuietTo {
+		return true
+	}
+
+	hour := now.In(mskLocation).Hour()
+	if user.QuietFrom < user.QuietTo {
+		return hour < user.QuietFrom || hour >= user.QuietTo
+	}
+	// Тихие часы через полночь, например 23-8
+	return hour < user.QuietFrom && hour >= user.QuietTo
+}
+
+// alertFormat — язык и шаблон уведомлений получателя
+type alertFormat struct {
+	Lang      string
+	Template  string // Пусто — стандартный текст alert.slot на языке Lang
+	ParseMode string
+}
+
+// userAlertFormat — формат уведомлений из настроек пользователя
+func userAlertFormat(user storage.User) alertFormat {
+	return alertFormat{
+		Lang:      userLanguage(user),
+		Template:  user.AlertTemplate,
+		ParseMode: user.AlertFormat,
+	}
+}
+
+// Render — текст уведомления и режим разметки. Если шаблон сломан, уходит стандартный текст.
+func (f alertFormat) Render(logger *slog.Logger, c wb.Coefficient) (string, string) {
+	if f.Template != "" {
+		text, err := alert.Render(f.Template, f.ParseMode, c)
+		if err == nil {
+			return text, f.ParseMode
+		}
+		logger.Warn("Ошибка шаблона уведомления", logging.Warehouse(c.WarehouseID), logging.Err(err))
+	}
+
+	return i18n.T(f.Lang, "alert.slot", c.WarehouseName, c.WarehouseID, c.Coefficient, c.Date), alert.ParseModeText
 }
 
 // findCoefficient — найти коэффициент по ID склада
@@ -118,3 +746,70 @@ func findCoefficient(coefficients []wb.Coefficient, warehouseID int) *wb.Coeffic
 func isTooManyRequestsError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "429 Too Many Requests")
 }
+
+// ===== Логика работы с уведомлениями =====
+
+// notificationKey — уведомление получателю по складу. Участник команды получает склад и по своей подписке,
+// и по списку команды — у этих уведомлений разные пороги, поэтому и отметки разные.
+type notificationKey struct {
+	chatID      int64
+	teamID      uint
+	warehouseID int
+}
+
+// loadNotifications — отметки об уведомлениях из базы, одним запросом на проверку: их мог оставить
+// прежний ведущий экземпляр. Если база недоступна, проверка идёт по отметкам в памяти.
+func (s *Scheduler) loadNotifications(logger *slog.Logger) {
+	notifications, err := s.deps.Storage.GetNotifications()
+	if err != nil {
+		logger.Error("Ошибка получения отметок об уведомлениях", logging.Err(err))
+		return
+	}
+	notified := make(map[notificationKey]int64, len(notifications))
+	for _, n := range notifications {
+		notified[notificationKey{n.ChatID, n.TeamID, n.WarehouseID}] = n.NotifiedAt.Unix()
+	}
+
+	s.notifyMu.Lock()
+	s.notifiedWarehouses = notified
+	s.notifyMu.Unlock()
+}
+
+// claimNotification — отметить уведомление, если по складу ещё не уведомляли или прошла задержка повтора.
+// claimed=false — уведомлять рано, repeat — это напоминание об уже открытой приёмке.
+func (s *Scheduler) claimNotification(r recipient, warehouseID int, now int64) (claimed, repeat bool) {
+	key := notificationKey{r.chatID, r.teamID, warehouseID}
+
+	s.notifyMu.Lock()
+	last := s.notifiedWarehouses[key]
+	if last != 0 && now-last < int64(s.deps.Config.Scheduler.RepeatNotifyDelay.Seconds()) {
+		s.notifyMu.Unlock()
+		return false, false
+	}
+	s.notifiedWarehouses[key] = now
+	s.notifyMu.Unlock()
+
+	n := &storage.Notification{ChatID: r.chatID, TeamID: r.teamID, WarehouseID: warehouseID, NotifiedAt: time.Unix(now, 0)}
+	if err := s.deps.Storage.SaveNotification(n); err != nil {
+		slog.Warn("Ошибка сохранения отметки об уведомлении", logging.Chat(r.chatID), logging.Warehouse(warehouseID), logging.Err(err))
+	}
+	return true, last != 0
+}
+
+// unmarkNotification — удалить отметку об уведомлении, false — её не было
+func (s *Scheduler) unmarkNotification(r recipient, warehouseID int) bool {
+	key := notificationKey{r.chatID, r.teamID, warehouseID}
+
+	s.notifyMu.Lock()
+	_, ok := s.notifiedWarehouses[key]
+	delete(s.notifiedWarehouses, key)
+	s.notifyMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	if err := s.deps.Storage.DeleteNotification(r.chatID, r.teamID, warehouseID); err != nil {
+		slog.Warn("Ошибка удаления отметки об уведомлении", logging.Chat(r.chatID), logging.Warehouse(warehouseID), logging.Err(err))
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/secret"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+func TestUserGroups(t *testing.T) {
+	// WB отвечает коэффициентом, равным длине ключа, и считает запросы по ключам
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Authorization")
+		mu.Lock()
+		requests[key]++
+		mu.Unlock()
+		if strings.HasPrefix(key, "bad") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]wb.Coefficient{{WarehouseID: 507, Coefficient: len(key), AllowUnload: true}})
+	}))
+	defer server.Close()
+
+	keys, err := secret.NewBox(strings.Repeat("ab", secret.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt := func(key string) string {
+		encrypted, err := keys.Encrypt(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encrypted
+	}
+
+	cfg := config.Default()
+	cfg.Scheduler.Workers = 2
+	s := NewScheduler(&Deps{Config: &cfg, Keys: keys, WBPool: wb.NewPool(server.URL, "shared-key", time.Second)})
+
+	shared := newCoefficientGroup([]wb.Coefficient{{WarehouseID: 507, Coefficient: 0, AllowUnload: true}})
+	user := func(telegramID int64, encrypted string) *keyGroup {
+		return &keyGroup{encrypted: encrypted, telegramID: telegramID, batches: []subscriberBatch{
+			{recipient: recipient{chatID: telegramID}, subscriptions: subscriptions(507)},
+		}}
+	}
+	keyed := []*keyGroup{
+		user(1, encrypt("user-key-1")),
+		user(2, encrypt("user-key-1")), // Тот же ключ, другой шифротекст
+		user(3, encrypt("user-key-22")),
+		user(4, encrypt("bad-key-1")),
+		user(5, "не шифротекст"),
+	}
+
+	groups := s.userGroups(context.Background(), slog.Default(), shared, keyed)
+
+	if len(groups) != 2 {
+		t.Fatalf("групп по ключам %d, ожидалось 2", len(groups))
+	}
+	coefficients := make(map[int64]int) // Получатель -> коэффициент его группы
+	for _, group := range append(groups, shared) {
+		for _, r := range group.subscribers[507] {
+			c, _ := group.coefficient(507, r)
+			coefficients[r.chatID] = c.Coefficient
+		}
+	}
+	want := map[int64]int{1: len("user-key-1"), 2: len("user-key-1"), 3: len("user-key-22"), 4: 0, 5: 0}
+	for chatID, c := range want {
+		if coefficients[chatID] != c {
+			t.Errorf("получатель %d проверен по коэффициенту %d, ожидался %d", chatID, coefficients[chatID], c)
+		}
+	}
+
+	if requests["user-key-1"] != 1 || requests["user-key-22"] != 1 || requests["bad-key-1"] != 1 {
+		t.Errorf("запросы к WB %v: каждый ключ запрашивается один раз", requests)
+	}
+
+	// Пользователи 1 и 3 удалили ключи: остаётся только ключ пользователя 2, тот же, что был у первого
+	s.pruneKeys([]*keyGroup{keyed[1]})
+	if len(s.apiKeys) != 1 || s.deps.WBPool.Len() != 1 {
+		t.Errorf("после очистки ключей %d, клиентов %d, ожидалось по одному", len(s.apiKeys), s.deps.WBPool.Len())
+	}
+	if logging.Redact("user-key-22") != "user-key-22" || logging.Redact("user-key-1") == "user-key-1" {
+		t.Error("из журнала вычищаются удалённые ключи или не вычищается оставшийся")
+	}
+
+	s.pruneKeys(nil)
+	if len(s.apiKeys) != 0 || s.deps.WBPool.Len() != 0 {
+		t.Errorf("ключей %d, клиентов %d после удаления всех ключей", len(s.apiKeys), s.deps.WBPool.Len())
+	}
+}
+
+// subscriptions — подписки на склады с порогом по умолчанию
+func subscriptions(warehouseIDs ...int) []storage.Subscription {
+	subs := make([]storage.Subscription, len(warehouseIDs))
+	for i, id := range warehouseIDs {
+		subs[i] = storage.Subscription{WarehouseID: id, MaxCoefficient: storage.DefaultMaxCoefficient}
+	}
+	return subs
+}
+
+func TestNotificationsSurviveFailover(t *testing.T) {
+	st, err := storage.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	cfg := config.Default()
+	cfg.Scheduler.RepeatNotifyDelay = time.Hour
+	deps := &Deps{Config: &cfg, Storage: st}
+	r := recipient{chatID: 1, teamID: 2}
+	now := time.Now().Unix()
+
+	leader := NewScheduler(deps)
+	if claimed, repeat := leader.claimNotification(r, 507, now); !claimed || repeat {
+		t.Fatalf("первое уведомление: claimed=%v repeat=%v", claimed, repeat)
+	}
+
+	// Новый ведущий после смены читает отметки из базы и не повторяет уведомление раньше срока
+	next := NewScheduler(deps)
+	next.loadNotifications(slog.Default())
+	if claimed, _ := next.claimNotification(r, 507, now+60); claimed {
+		t.Error("новый ведущий повторил уведомление до repeat_notify_delay")
+	}
+	if claimed, _ := next.claimNotification(recipient{chatID: 1}, 507, now+60); !claimed {
+		t.Error("отметка команды не должна мешать уведомлению по подписке чата")
+	}
+	if claimed, repeat := next.claimNotification(r, 507, now+3600); !claimed || !repeat {
+		t.Errorf("повтор через час: claimed=%v repeat=%v", claimed, repeat)
+	}
+
+	// Приёмка закрылась — отметка снимается и в базе
+	if !next.unmarkNotification(r, 507) {
+		t.Fatal("отметки не было")
+	}
+	leader.loadNotifications(slog.Default())
+	if claimed, repeat := leader.claimNotification(r, 507, now+3660); !claimed || repeat {
+		t.Errorf("после закрытия приёмки: claimed=%v repeat=%v, ожидалось новое уведомление", claimed, repeat)
+	}
+}
+
+func TestWBStatsShared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	st, err := storage.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	cfg := config.Default()
+
+	leader := NewScheduler(&Deps{Config: &cfg, Storage: st, WBPool: wb.NewPool(server.URL, "shared-key", time.Second)})
+	follower := NewScheduler(&Deps{Config: &cfg, Storage: st, WBPool: wb.NewPool(server.URL, "shared-key", time.Second)})
+	if requests, failures, err := follower.WBStats(); err != nil || requests != 0 || failures != 0 {
+		t.Fatalf("до первой проверки: %d запросов, %d ошибок, %v", requests, failures, err)
+	}
+
+	leader.deps.WBPool.Get("").GetAcceptanceCoefficients()
+	leader.saveWBStats()
+
+	// Ведомый WB не запрашивал, но видит счётчики ведущего
+	requests, failures, err := follower.WBStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := leader.deps.WBPool.Stats(); requests != want || requests == 0 || failures != requests {
+		t.Errorf("у ведомого %d запросов, %d ошибок, у ведущего %d запросов", requests, failures, want)
+	}
+}
@@ -2,173 +2,158 @@ package bot
 
 import (
 	"fmt"
-	"log"
 
-	"postavkinBot/internal/storage"
+	"postavkinBot/internal/logging"
 	"postavkinBot/internal/wb"
-
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-)
-
-var (
-	WbClient *wb.Client
-	Storage  *storage.Storage
 )
 
-func HandleStart(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	telegramID := update.Message.From.ID
-	username := update.Message.From.UserName
+func HandleStart(c *Context) {
+	// Пользователь и чат уже сохранены middleware Register
+	if !c.Chat().IsPrivate() {
+		c.Reply(c.T("start.group"))
+		return
+	}
 
-	exists, err := Storage.UserExists(int64(telegramID))
-	if err != nil {
-		log.Printf("Ошибка проверки пользователя: %v", err)
+	if token := c.Args; token != "" {
+		handleJoinTeam(c, token)
 		return
 	}
 
-	var text string
-	if !exists {
-		err := Storage.CreateUser(int64(telegramID), username)
-		if err != nil {
-			log.Printf("Ошибка создания пользователя: %v", err)
-			return
-		}
-		text = "Вы зарегистрированы! 🎉 Добро пожаловать!"
+	if c.NewUser {
+		c.Reply(c.T("start.welcome"))
 	} else {
-		text = "С возвращением! 👋"
+		c.Reply(c.T("start.welcome_back"))
 	}
-
-	bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text))
 }
 
-func HandleHelp(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	helpText := "📋 Доступные команды:\n" +
-		"/start - Начало работы\n" +
-		"/help - Помощь\n" +
-		"/warehouses - Список всех складов\n" +
-		"/addwarehouse - Добавить склад в отслеживание\n" +
-		"/mywarehouses - Показать мои склады\n" +
-		"/removewarehouse - Удалить склад из отслеживания\n" +
-		"/setinterval - Установить интервал проверки лимитов"
-	bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, helpText))
+func HandleHelp(c *Context) {
+	c.Reply(c.T("help"))
 }
 
-func HandleWarehouses(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	warehouses, err := WbClient.GetWarehouses()
-	if err != nil {
-		log.Printf("Ошибка получения складов: %v", err)
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка при получении складов. Попробуйте позже."))
-		return
-	}
-
+func HandleWarehouses(c *Context) {
+	// Справочник из кэша: команда не тратит лимиты WB. Пустой — ещё не загрузился
+	warehouses := c.Catalog.All()
 	if len(warehouses) == 0 {
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Нет доступных складов."))
+		c.Reply(c.T("warehouses.error"))
 		return
 	}
 
 	const maxMessageSize = 4000
-	text := "📦 Список доступных складов:\n"
+	text := c.T("warehouses.header")
 	for _, w := range warehouses {
 		line := fmt.Sprintf("- %s (ID: %d)\n", w.Name, w.ID)
 
 		if len(text)+len(line) > maxMessageSize {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text))
+			c.Reply(text)
 			text = ""
 		}
 		text += line
 	}
 
 	if text != "" {
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text))
+		c.Reply(text)
 	}
 }
 
-func HandleAddWarehouse(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Введите ID склада, который хотите добавить в отслеживание:"))
-
-	waitForUserInput(update, func(input string) {
-		var warehouseID int
-		if _, err := fmt.Sscanf(input, "%d", &warehouseID); err != nil {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка: введите корректный числовой ID склада."))
-			return
-		}
+func HandleAddWarehouse(c *Context) {
+	// «/add Коледино, 507» добавляет сразу, без аргументов бот спрашивает список
+	input := warehouseQuery(c)
+	if input == "" {
+		c.Ask(c.T("add.prompt"))
+		return
+	}
 
-		if err := Storage.AddWarehouseToUser(update.Message.From.ID, warehouseID); err != nil {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка при добавлении склада."))
-			return
+	addWarehouses(c, input, "add:", "add.done", func(w wb.Warehouse) error {
+		err := c.Storage.AddSubscription(c.ChatID(), w.ID)
+		if err != nil {
+			c.Log.Error("Ошибка добавления склада", logging.Warehouse(w.ID), logging.Err(err))
 		}
-
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("✅ Склад с ID %d успешно добавлен!", warehouseID)))
+		return err
 	})
 }
 
-func HandleMyWarehouses(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	telegramID := update.Message.From.ID
-
-	warehouseIDs, err := Storage.GetUserWarehouses(int64(telegramID))
-	if err != nil {
-		log.Printf("Ошибка получения складов пользователя: %v", err)
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка при получении ваших складов."))
+// HandleAddWarehouseCallback — выбор склада кнопкой, когда название подошло к нескольким
+func HandleAddWarehouseCallback(c *Context) {
+	w, ok := callbackWarehouse(c)
+	if !ok {
 		return
 	}
 
-	if len(warehouseIDs) == 0 {
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "У вас пока нет складов в отслеживании. Добавьте их через /addwarehouse."))
+	if err := c.Storage.AddSubscription(c.ChatID(), w.ID); err != nil {
+		c.Log.Error("Ошибка добавления склада", logging.Warehouse(w.ID), logging.Err(err))
+		c.Reply(c.T("add.error"))
 		return
 	}
 
-	allWarehouses, err := WbClient.GetWarehouses()
+	c.Reply(c.T("add.done", w.Name, w.ID))
+}
+
+func HandleMyWarehouses(c *Context) {
+	warehouseIDs, err := c.Storage.GetChatWarehouses(c.ChatID())
 	if err != nil {
-		log.Printf("Ошибка получения всех складов WB: %v", err)
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка при получении списка складов. Попробуйте позже."))
+		c.Log.Error("Ошибка получения складов чата", logging.Err(err))
+		c.Reply(c.T("my.error"))
 		return
 	}
 
-	text := "📦 Ваши склады для отслеживания:\n"
+	if len(warehouseIDs) == 0 {
+		c.Reply(c.T("my.empty"))
+		return
+	}
+
+	text := c.N("my.header", len(warehouseIDs))
 	for _, id := range warehouseIDs {
-		name := findWarehouseName(allWarehouses, id)
+		name := c.Catalog.Name(id)
 		if name == "" {
-			name = fmt.Sprintf("Неизвестный склад (ID: %d)", id)
+			name = c.T("warehouse.unknown_with_id", id)
 		}
 		text += fmt.Sprintf("- %s (ID: %d)\n", name, id)
 	}
 
-	bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, text))
+	c.Reply(text)
 }
 
-func HandleRemoveWarehouse(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Введите ID склада, который хотите удалить из отслеживания:"))
+func HandleRemoveWarehouse(c *Context) {
+	if c.Answer == "" {
+		c.Ask(c.T("remove.prompt"))
+		return
+	}
 
-	waitForUserInput(update, func(input string) {
-		var warehouseID int
-		if _, err := fmt.Sscanf(input, "%d", &warehouseID); err != nil {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка: введите корректный числовой ID склада."))
-			return
-		}
+	warehouseID, ok := parseWarehouseID(c.Answer)
+	if !ok {
+		c.Reply(c.T("error.invalid_warehouse"))
+		return
+	}
 
-		if err := Storage.RemoveWarehouseFromUser(update.Message.From.ID, warehouseID); err != nil {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка при удалении склада."))
-			return
-		}
+	if err := c.Storage.RemoveSubscription(c.ChatID(), warehouseID); err != nil {
+		c.Log.Error("Ошибка удаления склада", logging.Warehouse(warehouseID), logging.Err(err))
+		c.Reply(c.T("remove.error"))
+		return
+	}
 
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("✅ Склад с ID %d успешно удалён из отслеживания!", warehouseID)))
-	})
+	c.Reply(c.T("remove.done", warehouseID))
 }
 
-func HandleSetInterval(bot *tgbotapi.BotAPI, update tgbotapi.Update) {
-	bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Введите интервал проверки в минутах (например 5, 10, 15):"))
+func HandleSetInterval(c *Context) {
+	if c.Answer == "" {
+		c.Ask(c.T("interval.prompt"))
+		return
+	}
 
-	waitForUserInput(update, func(input string) {
-		var interval int
-		if _, err := fmt.Sscanf(input, "%d", &interval); err != nil || interval <= 0 {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка: пожалуйста, введите положительное число."))
-			return
-		}
+	var interval int
+	if _, err := fmt.Sscanf(c.Answer, "%d", &interval); err != nil || interval <= 0 {
+		c.Reply(c.T("interval.invalid"))
+		return
+	}
 
-		if err := Storage.UpdateCheckInterval(update.Message.From.ID, interval); err != nil {
-			bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Ошибка при сохранении интервала."))
-			return
-		}
+	if err := c.Storage.UpdateCheckInterval(c.ChatID(), interval); err != nil {
+		c.Reply(c.T("interval.error"))
+		return
+	}
 
-		bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("✅ Интервал обновлён! Теперь лимиты будут проверяться каждые %d минут.", interval)))
-	})
+	c.Reply(c.N("interval.done", interval))
+}
+
+func HandleUnknown(c *Context) {
+	c.Reply(c.T("error.unknown_command"))
 }
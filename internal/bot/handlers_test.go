@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// testCatalog — справочник складов без обращения к WB
+func testCatalog(warehouses ...wb.Warehouse) *Catalog {
+	return &Catalog{warehouses: warehouses}
+}
+
+// newTestContext — контекст команды в личном чате пользователя 42 без роутера и клиента WB:
+// обработчик, который полезет в WB, упадёт на nil
+func newTestContext(t *testing.T, catalog *Catalog) (*Context, *fakeSender) {
+	t.Helper()
+	st, err := storage.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	if err := st.EnsureChat(42, "private", "seller"); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &fakeSender{}
+	return &Context{
+		Context: context.Background(),
+		Deps:    &Deps{Storage: st, Catalog: catalog},
+		Bot:     sender,
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 42, Type: "private"}, From: &tgbotapi.User{ID: 42}},
+		Lang:    "ru",
+		Log:     slog.Default(),
+	}, sender
+}
+
+func TestHandleWarehouses(t *testing.T) {
+	c, sender := newTestContext(t, testCatalog(wb.Warehouse{ID: 507, Name: "Коледино"}, wb.Warehouse{ID: 117986, Name: "Казань"}))
+
+	HandleWarehouses(c)
+
+	want := i18n.T("ru", "warehouses.header") + "- Коледино (ID: 507)\n- Казань (ID: 117986)\n"
+	if len(sender.sent) != 1 || sender.sent[0] != want {
+		t.Errorf("отправлено %q, ожидалось %q", sender.sent, want)
+	}
+}
+
+func TestHandleWarehousesSplitsLongList(t *testing.T) {
+	var warehouses []wb.Warehouse
+	for id := range 300 {
+		warehouses = append(warehouses, wb.Warehouse{ID: 100000 + id, Name: "Склад с длинным названием"})
+	}
+	c, sender := newTestContext(t, testCatalog(warehouses...))
+
+	HandleWarehouses(c)
+
+	if len(sender.sent) < 2 {
+		t.Fatalf("отправлено %d сообщений, список должен быть разбит", len(sender.sent))
+	}
+	lines := 0
+	for _, text := range sender.sent {
+		if len(text) > 4096 {
+			t.Errorf("сообщение длиной %d больше лимита Telegram", len(text))
+		}
+		lines += strings.Count(text, "(ID: ")
+	}
+	if lines != len(warehouses) {
+		t.Errorf("в сообщениях %d складов, ожидалось %d", lines, len(warehouses))
+	}
+}
+
+func TestHandleWarehousesEmptyCatalog(t *testing.T) {
+	c, sender := newTestContext(t, testCatalog())
+
+	HandleWarehouses(c)
+
+	if len(sender.sent) != 1 || sender.sent[0] != i18n.T("ru", "warehouses.error") {
+		t.Errorf("отправлено %q", sender.sent)
+	}
+}
+
+func TestHandleMyWarehouses(t *testing.T) {
+	c, sender := newTestContext(t, testCatalog(wb.Warehouse{ID: 507, Name: "Коледино"}))
+	for _, id := range []int{507, 999} {
+		if err := c.Storage.AddSubscription(42, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	HandleMyWarehouses(c)
+
+	want := i18n.N("ru", "my.header", 2) +
+		"- Коледино (ID: 507)\n" +
+		"- " + i18n.T("ru", "warehouse.unknown_with_id", 999) + " (ID: 999)\n"
+	if len(sender.sent) != 1 || sender.sent[0] != want {
+		t.Errorf("отправлено %q, ожидалось %q", sender.sent, want)
+	}
+}
+
+func TestHandleMyWarehousesEmpty(t *testing.T) {
+	c, sender := newTestContext(t, testCatalog())
+
+	HandleMyWarehouses(c)
+
+	if len(sender.sent) != 1 || sender.sent[0] != i18n.T("ru", "my.empty") {
+		t.Errorf("отправлено %q", sender.sent)
+	}
+}
+
+func TestHandleRemoveWarehouse(t *testing.T) {
+	c, sender := newTestContext(t, testCatalog())
+	if err := c.Storage.AddSubscription(42, 507); err != nil {
+		t.Fatal(err)
+	}
+	c.Answer = "507"
+
+	HandleRemoveWarehouse(c)
+
+	if len(sender.sent) != 1 || sender.sent[0] != i18n.T("ru", "remove.done", 507) {
+		t.Errorf("отправлено %q", sender.sent)
+	}
+	if ids, _ := c.Storage.GetChatWarehouses(42); len(ids) != 0 {
+		t.Errorf("склады после удаления: %v", ids)
+	}
+}
+
+func TestHandleSetInterval(t *testing.T) {
+	tests := []struct {
+		answer   string
+		reply    string
+		interval int
+	}{
+		{"15", i18n.N("ru", "interval.done", 15), 15},
+		{"0", i18n.T("ru", "interval.invalid"), 5},
+		{"часто", i18n.T("ru", "interval.invalid"), 5},
+	}
+	for _, tt := range tests {
+		c, sender := newTestContext(t, testCatalog())
+		c.Answer = tt.answer
+
+		HandleSetInterval(c)
+
+		if len(sender.sent) != 1 || sender.sent[0] != tt.reply {
+			t.Errorf("%q: отправлено %q, ожидалось %q", tt.answer, sender.sent, tt.reply)
+		}
+		chat, err := c.Storage.GetChat(42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chat.CheckInterval != tt.interval {
+			t.Errorf("%q: интервал %d, ожидался %d", tt.answer, chat.CheckInterval, tt.interval)
+		}
+	}
+}
+
+func TestHandleAddWarehouseArgs(t *testing.T) {
+	c, sender := newTestContext(t, testCatalog(testWarehouses...))
+	c.Args = "Коледино, Казань, 507"
+
+	HandleAddWarehouse(c)
+
+	ids, err := c.Storage.GetChatWarehouses(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 507 || ids[1] != 117986 {
+		t.Errorf("склады %v, ожидались [507 117986]", ids)
+	}
+	want := i18n.T("ru", "add.done", "Коледино", 507) + "\n" + i18n.T("ru", "add.done", "Казань", 117986)
+	if len(sender.sent) != 1 || sender.sent[0] != want {
+		t.Errorf("отправлено %q, ожидалось %q без вопроса", sender.sent, want)
+	}
+}
+
+func TestAPIKeyDeletedFromGroupWithoutUser(t *testing.T) {
+	r, sender := newTestRouter(t)
+	cfg := config.Default()
+	r.deps.Config = &cfg
+	RegisterRoutes(r)
+
+	tests := []struct {
+		name string
+		from *tgbotapi.User
+		chat *tgbotapi.Chat
+	}{
+		{"анонимный администратор", &tgbotapi.User{ID: 1087968824, IsBot: true, UserName: "GroupAnonymousBot"}, &tgbotapi.Chat{ID: -100, Type: "supergroup"}},
+		{"пост канала", nil, &tgbotapi.Chat{ID: -200, Type: "channel"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender.requests = nil
+			update := message("/apikey eyJhbGciOiJFUzI1NiJ9.eyJzIjoxMDI0fQ.c2ln")
+			update.Message.MessageID = 7
+			update.Message.From, update.Message.Chat = tt.from, tt.chat
+			if tt.from == nil {
+				update = tgbotapi.Update{ChannelPost: update.Message}
+			}
+
+			r.HandleUpdate(context.Background(), update)
+
+			deleted := false
+			for _, req := range sender.requests {
+				if d, ok := req.(tgbotapi.DeleteMessageConfig); ok && d.ChatID == tt.chat.ID && d.MessageID == 7 {
+					deleted = true
+				}
+			}
+			if !deleted {
+				t.Errorf("сообщение с ключом не удалено, запросы %v", sender.requests)
+			}
+		})
+	}
+}
@@ -1,22 +1,87 @@
 package bot
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
 	"postavkinBot/internal/wb"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// Канал для получения обновлений
-var UpdatesChan chan tgbotapi.Update
+// NormalizeUpdate — подставить пост канала в Message, чтобы обработчики работали одинаково для всех типов чатов
+func NormalizeUpdate(update *tgbotapi.Update) {
+	if update.Message == nil && update.ChannelPost != nil {
+		update.Message = update.ChannelPost
+	}
+}
 
-// waitForUserInput — ожидание ввода пользователя через общий канал UpdatesChan
-func waitForUserInput(update tgbotapi.Update, callback func(input string)) {
-	for nextUpdate := range UpdatesChan {
-		if nextUpdate.Message != nil && nextUpdate.Message.Chat.ID == update.Message.Chat.ID {
-			callback(nextUpdate.Message.Text)
-			break
-		}
+// senderID — ID отправителя сообщения: пользователь или чат, от имени которого оно отправлено
+func senderID(msg *tgbotapi.Message) int64 {
+	if msg.From != nil {
+		return msg.From.ID
+	}
+	if msg.SenderChat != nil {
+		return msg.SenderChat.ID
+	}
+	return 0
+}
+
+// chatTitle — название чата для хранения в базе
+func chatTitle(chat *tgbotapi.Chat) string {
+	if chat.Title != "" {
+		return chat.Title
+	}
+	if chat.UserName != "" {
+		return chat.UserName
+	}
+	return strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+}
+
+// canManageChat — может ли отправитель менять подписки чата.
+// В личных чатах и каналах — всегда, в группах — только администраторы.
+func canManageChat(c *Context) bool {
+	msg := c.Message
+	if msg.Chat.IsPrivate() || msg.Chat.IsChannel() {
+		return true
+	}
+
+	// Анонимный администратор группы пишет от имени самой группы
+	if msg.SenderChat != nil && msg.SenderChat.ID == msg.Chat.ID {
+		return true
+	}
+	from := c.From()
+	if from == nil {
+		return false
+	}
+
+	member, err := c.Bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: msg.Chat.ID,
+			UserID: from.ID,
+		},
+	})
+	if err != nil {
+		c.Log.Error("Ошибка получения прав пользователя в чате", logging.Err(err))
+		return false
+	}
+
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// parseWarehouseID — разбор ID склада из ввода пользователя
+func parseWarehouseID(input string) (int, bool) {
+	var warehouseID int
+	if _, err := fmt.Sscanf(input, "%d", &warehouseID); err != nil {
+		return 0, false
 	}
+	return warehouseID, true
 }
 
 // findWarehouseName — поиск названия склада по ID
@@ -29,27 +94,33 @@ func findWarehouseName(warehouses []wb.Warehouse, id int) string {
 	return ""
 }
 
-// ===== Логика работы с уведомлениями =====
+// isBlockedError — Telegram ответил 403: пользователь заблокировал бота или бот удалён из чата
+func isBlockedError(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden
+}
 
-// getLastNotificationTime — получить время последней отправки уведомления
-func getLastNotificationTime(userID int64, warehouseID int) int64 {
-	if warehouses, ok := notifiedWarehouses[userID]; ok {
-		return warehouses[warehouseID]
+// markIfBlocked — при ошибке 403 отметить чат недоступным, а для личного чата ещё и пользователя.
+// Возвращает true, если ошибка означала блокировку.
+func markIfBlocked(store storage.Repository, chatID int64, err error) bool {
+	if !isBlockedError(err) {
+		return false
 	}
-	return 0
-}
 
-// markAsNotified — отметить время последнего уведомления
-func markAsNotified(userID int64, warehouseID int, timestamp int64) {
-	if _, ok := notifiedWarehouses[userID]; !ok {
-		notifiedWarehouses[userID] = make(map[int]int64)
+	slog.Warn("Бот заблокирован в чате, уведомления отключены", logging.Chat(chatID))
+	if err := store.SetChatBlocked(chatID, true); err != nil {
+		slog.Error("Ошибка отметки чата как заблокированного", logging.Chat(chatID), logging.Err(err))
 	}
-	notifiedWarehouses[userID][warehouseID] = timestamp
+	// У личных чатов ID положительный и совпадает с ID пользователя
+	if chatID > 0 {
+		if err := store.SetUserBlocked(chatID, true); err != nil {
+			slog.Error("Ошибка отметки пользователя как заблокировавшего бота", logging.User(chatID), logging.Err(err))
+		}
+	}
+	return true
 }
 
-// unmarkNotification — удалить запись об уведомлении
-func unmarkNotification(userID int64, warehouseID int) {
-	if warehouses, ok := notifiedWarehouses[userID]; ok {
-		delete(warehouses, warehouseID)
-	}
+// userLanguage — язык ответов пользователю: выбранный через /language или язык клиента Telegram
+func userLanguage(user storage.User) string {
+	return i18n.Resolve(user.Language, user.LanguageCode)
 }
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+)
+
+// janitorInterval — как часто удалять устаревшие записи
+const janitorInterval = time.Hour
+
+// Janitor — удаление устаревших служебных записей по срокам из retention
+type Janitor struct {
+	deps *Deps
+}
+
+// NewJanitor — создать уборку
+func NewJanitor(deps *Deps) *Janitor {
+	return &Janitor{deps: deps}
+}
+
+// Run — убирать сразу и затем раз в janitorInterval до отмены ctx
+func (j *Janitor) Run(ctx context.Context) error {
+	for {
+		j.clean(time.Now())
+		if !lifecycle.Sleep(ctx, janitorInterval) {
+			return nil
+		}
+	}
+}
+
+// clean — одна уборка: ошибка в одной таблице не мешает остальным
+func (j *Janitor) clean(now time.Time) {
+	retention := j.deps.Config.Retention
+	j.prune("outbound_messages", func() (int64, error) {
+		return j.deps.Storage.PruneMessages(now.Add(-retention.Messages))
+	})
+	j.prune("slot_events", func() (int64, error) {
+		return j.deps.Storage.PruneSlotEvents(now.Add(-retention.Events))
+	})
+	j.prune("webhook_deliveries", func() (int64, error) {
+		return j.deps.Storage.PruneWebhookDeliveries(now.Add(-retention.Deliveries))
+	})
+	j.prune("notifications", func() (int64, error) {
+		// Пока приёмка открыта, отметка обновляется раз в repeat_notify_delay; более старая осталась от удалённой подписки
+		return j.deps.Storage.PruneNotifications(now.Add(-j.deps.Config.Scheduler.RepeatNotifyDelay - 24*time.Hour))
+	})
+	j.prune("conversations", func() (int64, error) {
+		return j.deps.Storage.PruneConversations(now)
+	})
+}
+
+// prune — удалить устаревшие записи одной таблицы и записать итог в журнал
+func (j *Janitor) prune(table string, fn func() (int64, error)) {
+	deleted, err := fn()
+	if err != nil {
+		slog.Error("Ошибка удаления устаревших записей", "table", table, logging.Err(err))
+		return
+	}
+	if deleted > 0 {
+		slog.Info("Удалены устаревшие записи", "table", table, "deleted", deleted)
+	}
+}
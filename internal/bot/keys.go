@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+// keyCheckInterval — как часто проверять сроки ключей WB
+const keyCheckInterval = 24 * time.Hour
+
+// sharedKeyWarnedSetting — префикс настройки с временем предупреждения о сроке общего ключа, после него ID ключа
+const sharedKeyWarnedSetting = "wb.shared_key_warned."
+
+// formatExpiry — дата истечения ключа по МСК
+func formatExpiry(t time.Time) string {
+	return t.In(mskLocation).Format("02.01.2006 15:04")
+}
+
+// KeyWatcher — проверка сроков ключей WB: общего ключа бота (предупреждает администраторов)
+// и ключей пользователей (предупреждает владельцев)
+type KeyWatcher struct {
+	deps *Deps
+}
+
+// NewKeyWatcher — создать проверку ключей
+func NewKeyWatcher(deps *Deps) *KeyWatcher {
+	return &KeyWatcher{deps: deps}
+}
+
+// Run — проверять ключи сразу и затем раз в keyCheckInterval до отмены ctx
+func (w *KeyWatcher) Run(ctx context.Context) error {
+	for {
+		w.check(time.Now())
+		if !lifecycle.Sleep(ctx, keyCheckInterval) {
+			return nil
+		}
+	}
+}
+
+// check — одна проверка всех ключей
+func (w *KeyWatcher) check(now time.Time) {
+	warning := w.deps.Config.WB.KeyExpiryWarning
+
+	if info, err := wb.ParseToken(w.deps.Config.WB.APIKey); err != nil {
+		slog.Warn("Срок общего ключа WB не проверить", logging.Err(err))
+	} else if info.ExpiresWithin(now, warning) {
+		key := "keys.shared_expiring"
+		if info.Expired(now) {
+			key = "keys.shared_expired"
+		}
+		slog.Warn("Общий ключ WB скоро истечёт", "expires_at", info.ExpiresAt)
+		if !alreadyWarned(w.sharedKeyWarnedAt(info), info, now) {
+			for _, adminID := range w.deps.Config.Admins {
+				w.notify(adminID, key, formatExpiry(info.ExpiresAt))
+			}
+			if err := w.deps.Storage.SetSetting(sharedKeyWarnedName(info), strconv.FormatInt(now.Unix(), 10)); err != nil {
+				slog.Error("Ошибка сохранения отметки о предупреждении про общий ключ WB", logging.Err(err))
+			}
+		}
+	}
+
+	if w.deps.Keys == nil {
+		return
+	}
+	users, err := w.deps.Storage.GetAllUsers()
+	if err != nil {
+		slog.Error("Ошибка получения пользователей для проверки ключей WB", logging.Err(err))
+		return
+	}
+	for _, user := range users {
+		if user.WBAPIKey == "" || user.Blocked || user.Banned {
+			continue
+		}
+		key, err := w.deps.Keys.Decrypt(user.WBAPIKey)
+		if err != nil {
+			slog.Error("Ошибка расшифровки ключа WB", logging.User(user.TelegramID), logging.Err(err))
+			continue
+		}
+		info, err := wb.ParseToken(key)
+		if err != nil || !info.ExpiresWithin(now, warning) || alreadyWarned(user.WBAPIKeyWarnedAt, info, now) {
+			continue
+		}
+
+		text := "keys.user_expiring"
+		if info.Expired(now) {
+			text = "keys.user_expired"
+		}
+		w.notify(user.TelegramID, text, formatExpiry(info.ExpiresAt))
+		if err := w.deps.Storage.SetUserWBAPIKeyWarned(user.TelegramID, user.WBAPIKey, now); err != nil {
+			slog.Error("Ошибка сохранения отметки о предупреждении про ключ WB", logging.User(user.TelegramID), logging.Err(err))
+		}
+	}
+}
+
+// alreadyWarned — о сроке ключа уже предупреждали: один раз, пока он не истёк, и ещё раз после истечения.
+// Отметка в базе, поэтому после перезапуска или смены ведущего предупреждение не повторяется.
+func alreadyWarned(warnedAt *time.Time, info wb.TokenInfo, now time.Time) bool {
+	if warnedAt == nil {
+		return false
+	}
+	return !info.Expired(now) || !warnedAt.Before(info.ExpiresAt)
+}
+
+// sharedKeyWarnedName — настройка с отметкой о предупреждении про общий ключ; у нового ключа она своя
+func sharedKeyWarnedName(info wb.TokenInfo) string {
+	id := info.ID
+	if id == "" {
+		id = strconv.FormatInt(info.ExpiresAt.Unix(), 10)
+	}
+	return sharedKeyWarnedSetting + id
+}
+
+// sharedKeyWarnedAt — когда администраторов предупредили о сроке общего ключа, nil — ещё нет
+func (w *KeyWatcher) sharedKeyWarnedAt(info wb.TokenInfo) *time.Time {
+	setting, err := w.deps.Storage.GetSetting(sharedKeyWarnedName(info))
+	if err != nil {
+		if !storage.IsNotFound(err) {
+			slog.Warn("Ошибка чтения отметки о предупреждении про общий ключ WB", logging.Err(err))
+		}
+		return nil
+	}
+	seconds, err := strconv.ParseInt(setting.Value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	at := time.Unix(seconds, 0)
+	return &at
+}
+
+// notify — служебное уведомление на языке получателя
+func (w *KeyWatcher) notify(telegramID int64, key string, args ...interface{}) {
+	notifyUser(w.deps, telegramID, key, args...)
+}
+
+// notifyUser — служебное уведомление пользователю на его языке через очередь
+func notifyUser(deps *Deps, telegramID int64, key string, args ...interface{}) {
+	lang := notifyLanguage(deps, telegramID)
+	if err := deps.Queue.Enqueue(telegramID, storage.KindSystem, i18n.T(lang, key, args...), ""); err != nil {
+		slog.Error("Ошибка постановки служебного уведомления в очередь", logging.Chat(telegramID), "key", key, logging.Err(err))
+	}
+}
+
+// notifyLanguage — язык служебных уведомлений пользователю
+func notifyLanguage(deps *Deps, telegramID int64) string {
+	if user, err := deps.Storage.GetUserByTelegramID(telegramID); err == nil {
+		return userLanguage(*user)
+	}
+	return i18n.Default
+}
@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/secret"
+	"postavkinBot/internal/storage"
+)
+
+// expiringToken — ключ WB с правом на поставки, истекающий в expires
+func expiringToken(id string, expires time.Time) string {
+	part := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	return part(`{"alg":"ES256"}`) + "." + part(fmt.Sprintf(`{"id":%q,"exp":%d,"s":1024}`, id, expires.Unix())) + ".c2ln"
+}
+
+func TestKeyWatcherWarnsOnce(t *testing.T) {
+	st, err := storage.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	keys, err := secret.NewBox(strings.Repeat("ab", secret.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	expires := now.Add(72 * time.Hour)
+	cfg := config.Default()
+	cfg.Admins = []int64{1}
+	cfg.WB.APIKey = expiringToken("shared", expires)
+	cfg.WB.KeyExpiryWarning = 7 * 24 * time.Hour
+	deps := &Deps{Config: &cfg, Storage: st, Keys: keys, Queue: NewQueue(nil, st, QueueLimits{})}
+
+	setKey := func(id string) {
+		t.Helper()
+		encrypted, err := keys.Encrypt(expiringToken(id, expires))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SetUserWBAPIKey(42, encrypted); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.CreateUser(42, "seller"); err != nil {
+		t.Fatal(err)
+	}
+	setKey("user")
+
+	steps := []struct {
+		name string
+		at   time.Time
+		do   func()
+		want int64 // Всего предупреждений в очереди после шага
+	}{
+		{"ключи скоро истекут", now, nil, 2},
+		{"перезапуск или смена ведущего", now.Add(time.Hour), nil, 2},
+		{"следующий день", now.Add(24 * time.Hour), nil, 2},
+		{"ключи истекли", expires.Add(time.Hour), nil, 4},
+		{"после истечения", expires.Add(25 * time.Hour), nil, 4},
+		{"пользователь сменил ключ", expires.Add(26 * time.Hour), func() { setKey("user-2") }, 5},
+	}
+	for _, step := range steps {
+		if step.do != nil {
+			step.do()
+		}
+		// Каждый шаг — новый экземпляр: отметки должны браться только из базы
+		NewKeyWatcher(deps).check(step.at)
+		if got, _ := st.CountPendingMessages(); got != step.want {
+			t.Errorf("%s: предупреждений %d, ожидалось %d", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// languageAuto — data кнопки «как в Telegram»: сбрасывает выбранный язык
+const languageAuto = "auto"
+
+func HandleLanguage(c *Context) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, lang := range i18n.Supported {
+		label := i18n.Name(lang)
+		if c.User.Language == lang {
+			label = "✅ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "lang:"+lang),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("language.auto"), "lang:"+languageAuto),
+	))
+
+	msg := tgbotapi.NewMessage(c.ChatID(), c.T("language.choose"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	c.Send(msg)
+}
+
+// HandleLanguageCallback — выбор языка кнопкой из /language
+func HandleLanguageCallback(c *Context) {
+	lang := c.Args
+	if lang == languageAuto {
+		lang = ""
+	} else if !i18n.IsSupported(lang) {
+		c.AnswerCallback("")
+		return
+	}
+
+	if err := c.Storage.SetUserLanguage(c.User.TelegramID, lang); err != nil {
+		c.Log.Error("Ошибка сохранения языка пользователя", logging.Err(err))
+		c.AnswerCallback(c.T("language.error"))
+		return
+	}
+	c.User.Language = lang
+	c.Lang = userLanguage(*c.User)
+
+	text := c.T("language.reset")
+	if lang != "" {
+		text = c.T("language.set", i18n.Name(lang))
+	}
+	c.AnswerCallback("")
+	c.Send(tgbotapi.NewEditMessageText(c.ChatID(), c.Message.MessageID, text))
+}
@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/ratelimit"
+	"postavkinBot/internal/storage"
+)
+
+// Recover — перехват паники в обработчике, чтобы один апдейт не ронял обработку остальных
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			defer func() {
+				if r := recover(); r != nil {
+					c.Log.Error("Паника при обработке апдейта", "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+					if c.Message != nil {
+						c.Reply(c.T("error.internal"))
+					}
+				}
+			}()
+			next(c)
+		}
+	}
+}
+
+// Logging — журнал обработанных апдейтов с длительностью
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			started := time.Now()
+			next(c)
+
+			kind := "text"
+			switch {
+			case c.Callback != nil:
+				kind = "callback:" + c.Callback.Data
+			case c.Command != "":
+				kind = "/" + c.Command
+			}
+			c.Log.Info("Апдейт обработан", "kind", kind, "duration", time.Since(started))
+		}
+	}
+}
+
+// RateLimit — не больше limit апдейтов от одного отправителя за period
+func RateLimit(limit int, period time.Duration) Middleware {
+	limiter := ratelimit.New[int64](limit, period)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			r := limiter.Allow(c.SenderID(), time.Now())
+			if r.First {
+				// Предупреждаем один раз, дальше молча отбрасываем
+				c.Reply(c.T("error.rate_limited"))
+			}
+			if r.Allowed {
+				next(c)
+			}
+		}
+	}
+}
+
+// PrivateOnly — команда доступна только в личном чате с ботом
+func PrivateOnly() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if !c.Chat().IsPrivate() {
+				c.Reply(c.T("error.private_only"))
+				return
+			}
+			next(c)
+		}
+	}
+}
+
+// Banned — апдейты забаненных пользователей отбрасываются молча. Стоит перед RateLimit и Register:
+// забаненному не уходят даже предупреждения о лимите, а его апдейты не пишутся в базу.
+func Banned() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if from := c.From(); from != nil && !from.IsBot {
+				user, err := c.Storage.GetUserByTelegramID(from.ID)
+				if err != nil && !storage.IsNotFound(err) {
+					c.Log.Error("Ошибка проверки бана", logging.Err(err))
+				}
+				if err == nil && user.Banned {
+					return
+				}
+			}
+			next(c)
+		}
+	}
+}
+
+// Register — автоматическая регистрация: при любом обращении создаёт или обновляет
+// пользователя (никнейм, имя, язык, время последнего визита) и чат, выбирает язык ответов.
+func Register() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if chat := c.Chat(); chat != nil {
+				if err := c.Storage.EnsureChat(chat.ID, chat.Type, chatTitle(chat)); err != nil {
+					c.Log.Error("Ошибка сохранения чата", logging.Err(err))
+				}
+			}
+
+			if from := c.From(); from != nil && !from.IsBot {
+				user, created, err := c.Storage.TouchUser(storage.UserProfile{
+					TelegramID:   from.ID,
+					Username:     from.UserName,
+					FirstName:    from.FirstName,
+					LastName:     from.LastName,
+					LanguageCode: from.LanguageCode,
+				})
+				if err != nil {
+					c.Log.Error("Ошибка регистрации пользователя", logging.Err(err))
+				} else {
+					c.User = user
+					c.NewUser = created
+					c.Lang = userLanguage(*user)
+				}
+			}
+
+			next(c)
+		}
+	}
+}
+
+// Registered — команда доступна только пользователю, сохранённому в базе (не каналу и не анонимному админу)
+func Registered() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if c.User == nil {
+				c.Reply(c.T("error.users_only"))
+				return
+			}
+			next(c)
+		}
+	}
+}
+
+// ChatManager — менять подписки чата могут только его администраторы
+func ChatManager() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if !canManageChat(c) {
+				c.Reply(c.T("error.not_chat_admin"))
+				return
+			}
+			next(c)
+		}
+	}
+}
+
+// AdminOnly — команда доступна только администраторам из настроек, остальным она «не существует»
+func AdminOnly() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if !c.Config.IsAdmin(c.SenderID()) {
+				if c.Callback != nil {
+					c.AnswerCallback("")
+				} else {
+					c.Reply(c.T("error.unknown_command"))
+				}
+				return
+			}
+			next(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	r, sender := newTestRouter(t)
+	r.Use(RateLimit(1, time.Minute))
+	handled := 0
+	r.Command("cmd", func(c *Context) { handled++ })
+
+	for range 3 {
+		r.HandleUpdate(context.Background(), message("/cmd"))
+	}
+
+	if handled != 1 {
+		t.Errorf("обработано %d апдейтов, ожидался 1", handled)
+	}
+	if len(sender.sent) != 1 {
+		t.Errorf("отправлено %d сообщений, ожидалось одно предупреждение", len(sender.sent))
+	}
+}
+
+func TestBannedBeforeRateLimit(t *testing.T) {
+	r, sender := newTestRouter(t)
+	r.Use(Banned(), RateLimit(1, time.Minute), Register())
+	handled := 0
+	r.Command("cmd", func(c *Context) { handled++ })
+
+	if err := r.deps.Storage.CreateUser(42, "spammer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.deps.Storage.SetUserBanned(42, true); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		r.HandleUpdate(context.Background(), message("/cmd"))
+	}
+
+	if handled != 0 || len(sender.sent) != 0 {
+		t.Errorf("обработано %d апдейтов, отправлено %q: забаненному бот не отвечает, даже о лимите", handled, sender.sent)
+	}
+
+	if err := r.deps.Storage.SetUserBanned(42, false); err != nil {
+		t.Fatal(err)
+	}
+	r.HandleUpdate(context.Background(), message("/cmd"))
+	if handled != 1 {
+		t.Errorf("после разбана обработано %d апдейтов, ожидался 1", handled)
+	}
+}
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/metrics"
+	"postavkinBot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	queueBatchSize = 100 // Сколько сообщений забирать из базы за раз
+	queueIdleDelay = 500 * time.Millisecond
+	maxRetryDelay  = 10 * time.Minute // Максимальная пауза между повторами
+)
+
+// QueueLimits — лимиты отправки
+type QueueLimits struct {
+	GlobalPerSecond int           // Не больше стольких сообщений в секунду на бота (у Telegram — 30)
+	PrivateInterval time.Duration // Пауза между сообщениями в личный чат
+	GroupInterval   time.Duration // Пауза между сообщениями в группу (у Telegram — 20 в минуту)
+	MaxAttempts     int           // После стольких временных ошибок сообщение уходит в dead letter
+}
+
+// Queue — очередь исходящих сообщений с учётом лимитов Telegram.
+// Сообщения хранятся в базе, поэтому переживают перезапуск бота.
+type Queue struct {
+	bot    Sender
+	store  storage.Repository
+	limits QueueLimits
+
+	mu       sync.Mutex
+	lastSent time.Time
+	chatNext map[int64]time.Time // Когда можно снова писать в чат
+}
+
+// NewQueue — создать очередь
+func NewQueue(bot Sender, store storage.Repository, limits QueueLimits) *Queue {
+	return &Queue{
+		bot:      bot,
+		store:    store,
+		limits:   limits,
+		chatNext: make(map[int64]time.Time),
+	}
+}
+
+// Enqueue — поставить текст в очередь на отправку в чат.
+// kind — вид сообщения (storage.KindAlert и т.д.), parseMode — режим разметки Telegram или "".
+func (q *Queue) Enqueue(chatID int64, kind, text, parseMode string) error {
+	if err := q.store.EnqueueMessage(chatID, kind, text, parseMode); err != nil {
+		return err
+	}
+	metrics.Messages.WithLabelValues(kind, "queued").Inc()
+	return nil
+}
+
+// Run — отправка сообщений из очереди до отмены ctx
+func (q *Queue) Run(ctx context.Context) error {
+	for {
+		sent, err := q.processBatch(ctx)
+		if err != nil {
+			slog.Error("Ошибка чтения очереди", logging.Err(err))
+		}
+
+		if sent == 0 && !lifecycle.Sleep(ctx, queueIdleDelay) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// processBatch — отправить по первому готовому сообщению из каждого чата. Порядок внутри чата
+// соблюдает выборка: следующее сообщение чата попадёт в неё только после отправки предыдущего.
+func (q *Queue) processBatch(ctx context.Context) (int, error) {
+	messages, err := q.store.GetDueMessages(queueBatchSize, q.busyChats())
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+		if !q.chatReady(msg.ChatID) {
+			continue
+		}
+
+		q.waitGlobal(ctx)
+		if q.send(msg) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// busyChats — чаты, в которые пока нельзя писать; заодно забывает те, где ограничение истекло
+func (q *Queue) busyChats() []int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var busy []int64
+	for chatID, next := range q.chatNext {
+		if now.After(next) {
+			delete(q.chatNext, chatID)
+			continue
+		}
+		busy = append(busy, chatID)
+	}
+	return busy
+}
+
+// chatReady — можно ли сейчас писать в чат
+func (q *Queue) chatReady(chatID int64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return !time.Now().Before(q.chatNext[chatID])
+}
+
+// waitGlobal — пауза для соблюдения общего лимита бота
+func (q *Queue) waitGlobal(ctx context.Context) {
+	q.mu.Lock()
+	wait := time.Until(q.lastSent.Add(time.Second / time.Duration(q.limits.GlobalPerSecond)))
+	q.mu.Unlock()
+
+	if wait > 0 {
+		lifecycle.Sleep(ctx, wait)
+	}
+}
+
+// send — одна попытка отправки; true, если сообщение доставлено
+func (q *Queue) send(msg storage.OutboundMessage) bool {
+	out := tgbotapi.NewMessage(msg.ChatID, msg.Text)
+	out.ParseMode = msg.ParseMode
+	_, err := q.bot.Send(out)
+
+	now := time.Now()
+	q.mu.Lock()
+	q.lastSent = now
+	q.chatNext[msg.ChatID] = now.Add(q.chatSendInterval(msg.ChatID))
+	q.mu.Unlock()
+
+	if err == nil {
+		metrics.Messages.WithLabelValues(msg.Kind, "sent").Inc()
+		if err := q.store.MarkMessageSent(msg.ID); err != nil {
+			slog.Error("Ошибка отметки сообщения отправленным", messageAttr(msg.ID), logging.Err(err))
+		}
+		return true
+	}
+
+	q.handleError(msg, err)
+	return false
+}
+
+// handleError — решить судьбу сообщения после ошибки отправки
+func (q *Queue) handleError(msg storage.OutboundMessage, err error) {
+	var tgErr *tgbotapi.Error
+	isAPIError := errors.As(err, &tgErr)
+	metrics.Messages.WithLabelValues(msg.Kind, "error").Inc()
+
+	switch {
+	case isAPIError && tgErr.RetryAfter > 0:
+		// 429: Telegram сам говорит, сколько ждать; это не ошибка сообщения, попытку не считаем
+		next := time.Now().Add(time.Duration(tgErr.RetryAfter) * time.Second)
+		q.mu.Lock()
+		q.chatNext[msg.ChatID] = next
+		q.mu.Unlock()
+
+		slog.Warn("Telegram ограничил отправку (429)", logging.Chat(msg.ChatID), "retry_after", tgErr.RetryAfter)
+		if err := q.store.RetryMessage(msg.ID, next, err.Error(), false); err != nil {
+			slog.Error("Ошибка переноса сообщения", messageAttr(msg.ID), logging.Err(err))
+		}
+
+	case isAPIError && tgErr.Code >= http.StatusBadRequest && tgErr.Code < http.StatusInternalServerError:
+		// 400/403 и прочие ошибки запроса повтором не исправить
+		markIfBlocked(q.store, msg.ChatID, err)
+		q.bury(msg, err)
+
+	case msg.Attempts+1 >= q.limits.MaxAttempts:
+		q.bury(msg, err)
+
+	default:
+		// Сеть или 5xx — повтор с экспоненциальной паузой
+		delay := time.Second << uint(msg.Attempts+1)
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		slog.Warn("Временная ошибка отправки, повтор", logging.Chat(msg.ChatID), messageAttr(msg.ID), "delay", delay, logging.Err(err))
+		if err := q.store.RetryMessage(msg.ID, time.Now().Add(delay), err.Error(), true); err != nil {
+			slog.Error("Ошибка переноса сообщения", messageAttr(msg.ID), logging.Err(err))
+		}
+	}
+}
+
+// bury — перенести сообщение в dead letter
+func (q *Queue) bury(msg storage.OutboundMessage, cause error) {
+	slog.Error("Сообщение не доставлено", logging.Chat(msg.ChatID), messageAttr(msg.ID), logging.Err(cause))
+	metrics.Messages.WithLabelValues(msg.Kind, "dead").Inc()
+	if err := q.store.MarkMessageDead(msg.ID, fmt.Sprintf("попытка %d: %v", msg.Attempts+1, cause)); err != nil {
+		slog.Error("Ошибка переноса сообщения в dead letter", messageAttr(msg.ID), logging.Err(err))
+	}
+}
+
+// chatSendInterval — минимальный интервал между сообщениями в чат
+func (q *Queue) chatSendInterval(chatID int64) time.Duration {
+	// У групп и каналов ID отрицательный
+	if chatID < 0 {
+		return q.limits.GroupInterval
+	}
+	return q.limits.PrivateInterval
+}
+
+// messageAttr — поле журнала с ID сообщения в очереди
+func messageAttr(id uint) slog.Attr {
+	return slog.Uint64("message_id", uint64(id))
+}
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const conversationTTL = 10 * time.Minute // Сколько бот ждёт ответа на вопрос из Ask
+
+// HandlerFunc — обработчик апдейта
+type HandlerFunc func(c *Context)
+
+// Middleware — обёртка над обработчиком
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Route — зарегистрированный маршрут с собственной цепочкой middleware
+type Route struct {
+	router     *Router
+	handler    HandlerFunc
+	middleware []Middleware
+}
+
+// Alias — дополнительное имя команды для того же маршрута
+func (rt *Route) Alias(names ...string) *Route {
+	for _, name := range names {
+		rt.router.commands[strings.ToLower(name)] = rt
+	}
+	return rt
+}
+
+// Use — middleware, применяемые только к этому маршруту
+func (rt *Route) Use(mw ...Middleware) *Route {
+	rt.middleware = append(rt.middleware, mw...)
+	return rt
+}
+
+// build — обработчик маршрута, обёрнутый в его middleware
+func (rt *Route) build() HandlerFunc {
+	return chain(rt.handler, rt.middleware)
+}
+
+// callbackRoute — маршрут нажатия inline-кнопки по префиксу data
+type callbackRoute struct {
+	prefix string
+	route  *Route
+}
+
+// Router — маршрутизация апдейтов по командам, кнопкам и тексту
+type Router struct {
+	bot     Sender
+	botName string
+	deps    *Deps
+
+	middleware []Middleware
+	commands   map[string]*Route
+	callbacks  []callbackRoute
+	text       *Route
+	notFound   *Route
+}
+
+// NewRouter — создать роутер для бота с набором зависимостей
+func NewRouter(bot Sender, botName string, deps *Deps) *Router {
+	return &Router{
+		bot:      bot,
+		botName:  botName,
+		deps:     deps,
+		commands: make(map[string]*Route),
+	}
+}
+
+// Use — глобальные middleware, применяются ко всем маршрутам в порядке добавления
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Command — обработчик команды /name
+func (r *Router) Command(name string, h HandlerFunc) *Route {
+	rt := &Route{router: r, handler: h}
+	r.commands[strings.ToLower(name)] = rt
+	return rt
+}
+
+// Callback — обработчик нажатия inline-кнопки, data которой начинается с prefix
+func (r *Router) Callback(prefix string, h HandlerFunc) *Route {
+	rt := &Route{router: r, handler: h}
+	r.callbacks = append(r.callbacks, callbackRoute{prefix: prefix, route: rt})
+	return rt
+}
+
+// Text — обработчик обычного текста в личном чате
+func (r *Router) Text(h HandlerFunc) *Route {
+	r.text = &Route{router: r, handler: h}
+	return r.text
+}
+
+// NotFound — обработчик неизвестной команды
+func (r *Router) NotFound(h HandlerFunc) *Route {
+	r.notFound = &Route{router: r, handler: h}
+	return r.notFound
+}
+
+// HandleUpdate — обработать один апдейт
+func (r *Router) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	NormalizeUpdate(&update)
+
+	c := &Context{
+		Context:  ctx,
+		Bot:      r.bot,
+		BotName:  r.botName,
+		Deps:     r.deps,
+		Update:   update,
+		Message:  update.Message,
+		Callback: update.CallbackQuery,
+		router:   r,
+	}
+	// До загрузки пользователя из базы отвечаем на языке его клиента Telegram
+	c.Lang = i18n.Default
+	if from := c.From(); from != nil {
+		c.Lang = i18n.Resolve(from.LanguageCode)
+	}
+
+	route := r.match(c)
+	if route == nil {
+		return
+	}
+	c.Log = slog.With(
+		slog.Int(logging.KeyRequestID, update.UpdateID),
+		logging.Chat(c.ChatID()),
+		logging.User(c.SenderID()),
+	)
+
+	chain(route.build(), r.middleware)(c)
+}
+
+// match — выбор маршрута для апдейта
+func (r *Router) match(c *Context) *Route {
+	if c.Callback != nil {
+		if c.Callback.Message != nil {
+			c.Message = c.Callback.Message
+		}
+		for _, cb := range r.callbacks {
+			if strings.HasPrefix(c.Callback.Data, cb.prefix) {
+				c.Args = strings.TrimPrefix(c.Callback.Data, cb.prefix)
+				return cb.route
+			}
+		}
+		return nil
+	}
+
+	if c.Message == nil {
+		return nil
+	}
+
+	if c.Message.IsCommand() {
+		name, ok := commandName(c.Message, r.botName)
+		if !ok {
+			// Команда другому боту в группе
+			return nil
+		}
+
+		// Новая команда отменяет ожидание ответа на предыдущую
+		r.endConversation(c.Message.Chat.ID, senderID(c.Message))
+
+		c.Command = name
+		c.Args = strings.TrimSpace(c.Message.CommandArguments())
+		if rt, ok := r.commands[name]; ok {
+			return rt
+		}
+		if c.Message.Chat.IsPrivate() {
+			return r.notFound
+		}
+		// Чужие команды в группах не комментируем
+		return nil
+	}
+
+	if rt := r.takeConversation(c); rt != nil {
+		return rt
+	}
+
+	if c.Message.Chat.IsPrivate() {
+		c.Args = strings.TrimSpace(c.Message.Text)
+		return r.text
+	}
+
+	// В группах и каналах реагируем только на команды, адресованные боту
+	return nil
+}
+
+// ask — запомнить, что следующий текст отправителя — ответ на вопрос команды c.Command
+func (r *Router) ask(c *Context) error {
+	return r.deps.Storage.SaveConversation(&storage.Conversation{
+		ChatID:    c.ChatID(),
+		SenderID:  c.SenderID(),
+		Command:   c.Command,
+		Args:      c.Args,
+		ExpiresAt: time.Now().Add(conversationTTL),
+	})
+}
+
+// takeConversation — маршрут команды, ожидающей ответа отправителя, если вопрос ещё актуален.
+// Ответ проходит через middleware команды заново, как и сама команда.
+func (r *Router) takeConversation(c *Context) *Route {
+	chatID, sender := c.Message.Chat.ID, senderID(c.Message)
+	conv, err := r.deps.Storage.TakeConversation(chatID, sender, time.Now())
+	if err != nil {
+		if !storage.IsNotFound(err) {
+			slog.Error("Ошибка получения вопроса, ожидающего ответа", logging.Chat(chatID), logging.User(sender), logging.Err(err))
+		}
+		return nil
+	}
+
+	rt, ok := r.commands[conv.Command]
+	if !ok {
+		return nil
+	}
+	c.Command = conv.Command
+	c.Args = conv.Args
+	c.Answer = strings.TrimSpace(c.Message.Text)
+	return rt
+}
+
+// endConversation — отменить ожидание ответа
+func (r *Router) endConversation(chatID, senderID int64) {
+	if err := r.deps.Storage.DeleteConversation(chatID, senderID); err != nil {
+		slog.Error("Ошибка отмены вопроса, ожидающего ответа", logging.Chat(chatID), logging.User(senderID), logging.Err(err))
+	}
+}
+
+// chain — обернуть обработчик в middleware так, чтобы первый в списке выполнялся первым
+func chain(h HandlerFunc, mw []Middleware) HandlerFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
+
+// commandName — имя команды без @botname; false, если команда адресована другому боту
+func commandName(msg *tgbotapi.Message, botName string) (string, bool) {
+	command := msg.CommandWithAt()
+	if i := strings.Index(command, "@"); i != -1 {
+		if !strings.EqualFold(command[i+1:], botName) {
+			return "", false
+		}
+		command = command[:i]
+	}
+	return strings.ToLower(command), true
+}
@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"postavkinBot/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeSender — Telegram API, который запоминает отправленные тексты и прочие запросы
+type fakeSender struct {
+	sent     []string
+	requests []tgbotapi.Chattable
+}
+
+func (s *fakeSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if msg, ok := c.(tgbotapi.MessageConfig); ok {
+		s.sent = append(s.sent, msg.Text)
+	}
+	return tgbotapi.Message{}, nil
+}
+
+func (s *fakeSender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	s.requests = append(s.requests, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (s *fakeSender) GetChatMember(tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	return tgbotapi.ChatMember{}, nil
+}
+
+// newTestRouter — роутер с хранилищем в памяти и без маршрутов
+func newTestRouter(t *testing.T) (*Router, *fakeSender) {
+	t.Helper()
+	st, err := storage.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	sender := &fakeSender{}
+	return NewRouter(sender, "testbot", &Deps{Storage: st}), sender
+}
+
+// message — апдейт с текстом от пользователя 42 в личном чате
+func message(text string) tgbotapi.Update {
+	msg := &tgbotapi.Message{
+		Text: text,
+		Chat: &tgbotapi.Chat{ID: 42, Type: "private"},
+		From: &tgbotapi.User{ID: 42},
+	}
+	if strings.HasPrefix(text, "/") {
+		length := len(text)
+		if i := strings.IndexByte(text, ' '); i >= 0 {
+			length = i
+		}
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Length: length}}
+	}
+	return tgbotapi.Update{Message: msg}
+}
+
+// record — middleware, которое пишет name в calls и передаёт апдейт дальше
+func record(calls *[]string, name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			*calls = append(*calls, name)
+			next(c)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	r, _ := newTestRouter(t)
+	var calls []string
+	r.Use(record(&calls, "global1"), record(&calls, "global2"))
+	r.Command("cmd", func(c *Context) {
+		calls = append(calls, "handler")
+	}).Use(record(&calls, "route1"), record(&calls, "route2"))
+
+	r.HandleUpdate(context.Background(), message("/cmd"))
+
+	want := "global1,global2,route1,route2,handler"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("порядок вызовов %q, ожидался %q", got, want)
+	}
+}
+
+func TestMiddlewareStopsChain(t *testing.T) {
+	r, _ := newTestRouter(t)
+	var calls []string
+	stop := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) { calls = append(calls, "stop") }
+	}
+	r.Use(record(&calls, "global"))
+	r.Command("cmd", func(c *Context) {
+		calls = append(calls, "handler")
+	}).Use(stop, record(&calls, "route"))
+
+	r.HandleUpdate(context.Background(), message("/cmd"))
+
+	if got := strings.Join(calls, ","); got != "global,stop" {
+		t.Errorf("вызовы %q: обработчик и следующие middleware не должны выполняться", got)
+	}
+}
+
+// askRoute — команда /ask: без ответа задаёт вопрос, с ответом записывает «аргументы|ответ»
+func askRoute(r *Router, answers *[]string) {
+	r.Command("ask", func(c *Context) {
+		if c.Answer == "" {
+			c.Ask("вопрос")
+			return
+		}
+		*answers = append(*answers, c.Args+"|"+c.Answer)
+	})
+	r.Text(func(c *Context) {
+		*answers = append(*answers, "text:"+c.Args)
+	})
+}
+
+func TestAskAnswer(t *testing.T) {
+	r, sender := newTestRouter(t)
+	var answers []string
+	askRoute(r, &answers)
+
+	r.HandleUpdate(context.Background(), message("/ask arg"))
+	r.HandleUpdate(context.Background(), message(" ответ "))
+	r.HandleUpdate(context.Background(), message("ещё текст"))
+
+	if got := strings.Join(answers, ","); got != "arg|ответ,text:ещё текст" {
+		t.Errorf("ответы %q", got)
+	}
+	if len(sender.sent) != 1 || sender.sent[0] != "вопрос" {
+		t.Errorf("отправлено %q, ожидался один вопрос", sender.sent)
+	}
+}
+
+func TestAskAnswerOnAnotherRouter(t *testing.T) {
+	first, _ := newTestRouter(t)
+	second := NewRouter(&fakeSender{}, "testbot", first.deps)
+	var answers []string
+	askRoute(first, &answers)
+	askRoute(second, &answers)
+
+	first.HandleUpdate(context.Background(), message("/ask"))
+	second.HandleUpdate(context.Background(), message("ответ"))
+
+	if got := strings.Join(answers, ","); got != "|ответ" {
+		t.Errorf("ответы %q: ответ должен дойти до команды на другом экземпляре", got)
+	}
+}
+
+func TestAskExpired(t *testing.T) {
+	r, _ := newTestRouter(t)
+	var answers []string
+	askRoute(r, &answers)
+
+	err := r.deps.Storage.SaveConversation(&storage.Conversation{
+		ChatID:    42,
+		SenderID:  42,
+		Command:   "ask",
+		ExpiresAt: time.Now().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.HandleUpdate(context.Background(), message("поздно"))
+
+	if got := strings.Join(answers, ","); got != "text:поздно" {
+		t.Errorf("ответы %q: истёкший вопрос не должен получать ответ", got)
+	}
+	if _, err := r.deps.Storage.TakeConversation(42, 42, time.Now().Add(-time.Hour)); !storage.IsNotFound(err) {
+		t.Errorf("истёкший вопрос остался в базе: %v", err)
+	}
+}
+
+func TestCommandCancelsAsk(t *testing.T) {
+	r, _ := newTestRouter(t)
+	var answers []string
+	askRoute(r, &answers)
+	r.Command("other", func(c *Context) {})
+
+	r.HandleUpdate(context.Background(), message("/ask"))
+	r.HandleUpdate(context.Background(), message("/other"))
+	r.HandleUpdate(context.Background(), message("текст"))
+
+	if got := strings.Join(answers, ","); got != "text:текст" {
+		t.Errorf("ответы %q: новая команда должна отменять вопрос", got)
+	}
+}
@@ -0,0 +1,58 @@
+package bot
+
+// RegisterRoutes — регистрация всех команд бота и общей цепочки middleware
+func RegisterRoutes(r *Router) {
+	r.Use(
+		Recover(),
+		Logging(),
+		Banned(),
+		RateLimit(r.deps.Config.Limits.UserRequests, r.deps.Config.Limits.UserPeriod),
+		Register(),
+	)
+
+	r.Command("start", HandleStart)
+	r.Command("help", HandleHelp)
+	r.Command("warehouses", HandleWarehouses)
+	r.Command("addwarehouse", HandleAddWarehouse).Alias("add").Use(ChatManager())
+	r.Callback("add:", HandleAddWarehouseCallback).Use(ChatManager())
+	r.Command("mywarehouses", HandleMyWarehouses).Alias("my")
+	r.Command("status", HandleStatus)
+	r.Command("removewarehouse", HandleRemoveWarehouse).Alias("remove").Use(ChatManager())
+	r.Command("setinterval", HandleSetInterval).Use(ChatManager())
+
+	// Командная работа
+	r.Command("newteam", HandleNewTeam).Use(PrivateOnly(), Registered())
+	r.Command("team", HandleTeam).Use(PrivateOnly(), Registered())
+	r.Command("invite", HandleInvite).Use(PrivateOnly(), Registered())
+	r.Command("teamadd", HandleTeamAddWarehouse).Use(PrivateOnly(), Registered())
+	r.Callback("teamadd:", HandleTeamAddWarehouseCallback).Use(PrivateOnly(), Registered())
+	r.Command("teamremove", HandleTeamRemoveWarehouse).Use(PrivateOnly(), Registered())
+	r.Command("setrole", HandleSetRole).Use(PrivateOnly(), Registered())
+	r.Command("kick", HandleKick).Use(PrivateOnly(), Registered())
+	r.Command("leaveteam", HandleLeaveTeam).Use(PrivateOnly(), Registered())
+
+	// Настройки уведомлений
+	r.Command("quiet", HandleQuiet).Use(PrivateOnly(), Registered())
+	r.Command("mute", HandleMute).Use(PrivateOnly(), Registered())
+	r.Command("unmute", HandleUnmute).Use(PrivateOnly(), Registered())
+	r.Command("template", HandleTemplate).Use(PrivateOnly(), Registered())
+	r.Command("apikey", HandleAPIKey) // Сама проверяет личный чат и регистрацию, чтобы сначала удалить ключ из группы
+	r.Command("webhook", HandleWebhook).Alias("webhooks").Use(PrivateOnly(), Registered())
+	r.Command("apitoken", HandleAPIToken).Use(PrivateOnly(), Registered())
+
+	// Язык интерфейса
+	r.Command("language", HandleLanguage).Alias("lang").Use(Registered())
+	r.Callback("lang:", HandleLanguageCallback).Use(Registered())
+
+	// Администрирование
+	r.Command("stats", HandleStats).Use(AdminOnly(), PrivateOnly())
+	r.Command("broadcast", HandleBroadcast).Use(AdminOnly(), PrivateOnly())
+	r.Callback("broadcast:", HandleBroadcastCallback).Use(AdminOnly())
+	r.Command("user", HandleUserInfo).Use(AdminOnly(), PrivateOnly())
+	r.Command("ban", HandleBan).Use(AdminOnly(), PrivateOnly())
+	r.Command("unban", HandleUnban).Use(AdminOnly(), PrivateOnly())
+	r.Command("pollinterval", HandlePollInterval).Use(AdminOnly(), PrivateOnly())
+
+	r.Text(HandleUnknown)
+	r.NotFound(HandleUnknown)
+}
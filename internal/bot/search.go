@@ -0,0 +1,263 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"postavkinBot/internal/wb"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxChoices — больше кандидатов кнопками не предлагаем, запрос лучше уточнить
+const maxChoices = 8
+
+// translit — кириллица латиницей, чтобы «Koledino» и «Коледино» давали один ключ поиска
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// searchKey — название для сравнения: нижний регистр, латиница, слова через один пробел
+func searchKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if latin, ok := translit[r]; ok {
+			b.WriteString(latin)
+		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// maxTypos — сколько опечаток прощаем в запросе такой длины
+func maxTypos(query string) int {
+	return min(len([]rune(query))/4, 3)
+}
+
+// levenshtein — число вставок, удалений и замен букв, превращающих a в b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// minPrefix — с какой длины запрос ищется как начало названия или его слова
+const minPrefix = 3
+
+// nameDistance — насколько название далеко от запроса: 0 — название или одно из его слов начинается
+// с запроса, иначе наименьшее расстояние до названия целиком или до одного из его слов
+func nameDistance(query, name string) int {
+	if len(query) >= minPrefix && strings.Contains(" "+name, " "+query) {
+		return 0
+	}
+	distance := levenshtein(query, name)
+	for _, word := range strings.Fields(name) {
+		distance = min(distance, levenshtein(query, word))
+	}
+	return distance
+}
+
+// searchWarehouses — склады по названию: точное совпадение, иначе ближайшие с учётом опечаток и транслитерации
+func searchWarehouses(warehouses []wb.Warehouse, query string) []wb.Warehouse {
+	q := searchKey(query)
+	if q == "" {
+		return nil
+	}
+
+	var exact []wb.Warehouse
+	for _, w := range warehouses {
+		if searchKey(w.Name) == q {
+			exact = append(exact, w)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+
+	type match struct {
+		warehouse wb.Warehouse
+		distance  int
+	}
+	var matches []match
+	tolerance := maxTypos(q)
+	for _, w := range warehouses {
+		if d := nameDistance(q, searchKey(w.Name)); d <= tolerance {
+			matches = append(matches, match{w, d})
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// Оставляем только лучших: опечатка в одну букву важнее совпадения с тремя
+	best := slices.MinFunc(matches, func(a, b match) int { return a.distance - b.distance }).distance
+	var found []wb.Warehouse
+	for _, m := range matches {
+		if m.distance == best {
+			found = append(found, m.warehouse)
+		}
+	}
+	slices.SortFunc(found, func(a, b wb.Warehouse) int { return strings.Compare(a.Name, b.Name) })
+	return found
+}
+
+// ambiguousWarehouse — запрос, под который подошли несколько складов
+type ambiguousWarehouse struct {
+	query      string
+	candidates []wb.Warehouse
+}
+
+// warehouseInput — склады из сообщения пользователя
+type warehouseInput struct {
+	found     []wb.Warehouse
+	unknown   []string
+	ambiguous []ambiguousWarehouse
+}
+
+// parseWarehouses — разбор списка складов через запятую, точку с запятой или с новой строки:
+// каждый элемент — ID или название склада из справочника
+func parseWarehouses(warehouses []wb.Warehouse, input string) warehouseInput {
+	var in warehouseInput
+	add := func(w wb.Warehouse) {
+		if !slices.ContainsFunc(in.found, func(f wb.Warehouse) bool { return f.ID == w.ID }) {
+			in.found = append(in.found, w)
+		}
+	}
+
+	queries := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ';' || r == '\n' })
+	for _, query := range queries {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			continue
+		}
+
+		if id, err := strconv.Atoi(strings.TrimPrefix(query, "#")); err == nil {
+			i := slices.IndexFunc(warehouses, func(w wb.Warehouse) bool { return w.ID == id })
+			if i < 0 {
+				in.unknown = append(in.unknown, query)
+				continue
+			}
+			add(warehouses[i])
+			continue
+		}
+
+		switch found := searchWarehouses(warehouses, query); len(found) {
+		case 0:
+			in.unknown = append(in.unknown, query)
+		case 1:
+			add(found[0])
+		default:
+			in.ambiguous = append(in.ambiguous, ambiguousWarehouse{query: query, candidates: found})
+		}
+	}
+	return in
+}
+
+// warehouseQuery — список складов из аргументов команды, иначе из ответа на вопрос; пусто — нужно спросить
+func warehouseQuery(c *Context) string {
+	if c.Args != "" {
+		return c.Args
+	}
+	return c.Answer
+}
+
+// addWarehouses — добавить склады из списка input через add и ответить итогом. Ненайденные и
+// не добавленные из-за ошибки перечисляются, для неоднозначных присылаются кнопки с data prefix+ID.
+func addWarehouses(c *Context, input, prefix, doneKey string, add func(w wb.Warehouse) error) {
+	warehouses := c.Catalog.All()
+	if len(warehouses) == 0 {
+		c.Reply(c.T("error.catalog"))
+		return
+	}
+
+	in := parseWarehouses(warehouses, input)
+	if len(in.found)+len(in.unknown)+len(in.ambiguous) == 0 {
+		c.Reply(c.T("error.invalid_warehouse"))
+		return
+	}
+
+	// Ошибка с одним складом не мешает добавить остальные: неудачные перечисляются после добавленных
+	var lines, failed []string
+	for _, w := range in.found {
+		if err := add(w); err != nil {
+			failed = append(failed, c.T("add.failed", w.Name, w.ID))
+			continue
+		}
+		lines = append(lines, c.T(doneKey, w.Name, w.ID))
+	}
+	lines = append(lines, failed...)
+	for _, query := range in.unknown {
+		lines = append(lines, c.T("add.not_found", query))
+	}
+	if len(in.unknown) > 0 {
+		lines = append(lines, "", c.T("add.search_hint"))
+	}
+	if len(lines) > 0 {
+		c.Reply(strings.Join(lines, "\n"))
+	}
+
+	for _, a := range in.ambiguous {
+		candidates := a.candidates
+		text := c.T("add.choose", a.query)
+		if len(candidates) > maxChoices {
+			candidates = candidates[:maxChoices]
+			text += "\n" + c.T("add.choose_more")
+		}
+
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for _, w := range candidates {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s (ID: %d)", w.Name, w.ID), prefix+strconv.Itoa(w.ID)),
+			))
+		}
+		msg := tgbotapi.NewMessage(c.ChatID(), text)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		c.Send(msg)
+	}
+}
+
+// callbackWarehouse — склад с нажатой кнопки выбора. Кнопки убираются сразу, чтобы склад не добавили дважды,
+// поэтому права проверяются до вызова: middleware маршрута или в самом обработчике.
+func callbackWarehouse(c *Context) (wb.Warehouse, bool) {
+	c.AnswerCallback("")
+	c.Send(tgbotapi.NewEditMessageReplyMarkup(c.ChatID(), c.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
+
+	id, err := strconv.Atoi(c.Args)
+	if err != nil {
+		return wb.Warehouse{}, false
+	}
+	warehouses := c.Catalog.All()
+	i := slices.IndexFunc(warehouses, func(w wb.Warehouse) bool { return w.ID == id })
+	if i < 0 {
+		c.Reply(c.T("add.not_found", c.Args))
+		return wb.Warehouse{}, false
+	}
+	return warehouses[i], true
+}
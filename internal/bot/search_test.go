@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	"postavkinBot/internal/wb"
+)
+
+// testWarehouses — справочник складов для тестов поиска
+var testWarehouses = []wb.Warehouse{
+	{ID: 507, Name: "Коледино"},
+	{ID: 117501, Name: "Подольск"},
+	{ID: 218623, Name: "Подольск 3"},
+	{ID: 117986, Name: "Казань"},
+	{ID: 117544, Name: "Санкт-Петербург Уткина Заводь"},
+	{ID: 206236, Name: "Санкт-Петербург Шушары"},
+	{ID: 120762, Name: "Электросталь"},
+}
+
+// ids — ID складов по порядку
+func ids(warehouses []wb.Warehouse) []int {
+	list := make([]int, len(warehouses))
+	for i, w := range warehouses {
+		list[i] = w.ID
+	}
+	return list
+}
+
+func TestSearchKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Коледино", "koledino"},
+		{"Koledino", "koledino"},
+		{"  ЭЛЕКТРОСТАЛЬ ", "elektrostal"},
+		{"Санкт-Петербург  Шушары", "sankt peterburg shushary"},
+		{"Подольск 3", "podolsk 3"},
+		{"Щёлково", "schelkovo"},
+		{"—", ""},
+	}
+	for _, tt := range tests {
+		if got := searchKey(tt.name); got != tt.want {
+			t.Errorf("searchKey(%q) = %q, ожидалось %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"koledino", "koledino", 0},
+		{"koledino", "koledno", 1},
+		{"koledino", "kolideno", 2},
+		{"kazan", "kazn", 1},
+		{"коледино", "колeдино", 1}, // Латинская e среди кириллицы
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, ожидалось %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSearchWarehouses(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"точное название", "Коледино", []int{507}},
+		{"регистр и пробелы", "  коледино ", []int{507}},
+		{"точное важнее начала названия", "Подольск", []int{117501}},
+		{"латиница вместо кириллицы", "Koledino", []int{507}},
+		{"латиница с опечаткой", "Kolidino", []int{507}},
+		{"опечатка", "Колидено", []int{507}},
+		{"пропущена буква", "Электростль", []int{120762}},
+		{"начало слова", "Шушар", []int{206236}},
+		{"неоднозначное название", "Санкт-Петербург", []int{117544, 206236}},
+		{"слишком много опечаток", "Клдн", nil},
+		{"неизвестный склад", "Новосибирск", nil},
+		{"пустой запрос", " - ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(searchWarehouses(testWarehouses, tt.query))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("searchWarehouses(%q) = %v, ожидалось %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWarehouses(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		found     []int
+		unknown   []string
+		ambiguous map[string][]int
+	}{
+		{
+			name:  "ID",
+			input: "507",
+			found: []int{507},
+		},
+		{
+			name:  "ID с решёткой",
+			input: "#117986",
+			found: []int{117986},
+		},
+		{
+			name:    "неизвестный ID",
+			input:   "1",
+			unknown: []string{"1"},
+		},
+		{
+			name:  "точное название",
+			input: "Казань",
+			found: []int{117986},
+		},
+		{
+			name:  "опечатка",
+			input: "Колидено",
+			found: []int{507},
+		},
+		{
+			name:  "латиница",
+			input: "Kazan",
+			found: []int{117986},
+		},
+		{
+			name:      "неоднозначное название",
+			input:     "Санкт-Петербург",
+			ambiguous: map[string][]int{"Санкт-Петербург": {117544, 206236}},
+		},
+		{
+			name:  "названия и ID вперемешку, повтор склада не дублируется",
+			input: "Коледино, Подольск, 507",
+			found: []int{507, 117501},
+		},
+		{
+			name:      "разделители и ошибки в одном списке",
+			input:     "Казань; Новосибирск\nСанкт,,999",
+			found:     []int{117986},
+			unknown:   []string{"Новосибирск", "999"},
+			ambiguous: map[string][]int{"Санкт": {117544, 206236}},
+		},
+		{
+			name:  "пустой ввод",
+			input: " , ;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := parseWarehouses(testWarehouses, tt.input)
+			if got := ids(in.found); !slices.Equal(got, tt.found) {
+				t.Errorf("найдены %v, ожидались %v", got, tt.found)
+			}
+			if !slices.Equal(in.unknown, tt.unknown) {
+				t.Errorf("не найдены %q, ожидались %q", in.unknown, tt.unknown)
+			}
+			if len(in.ambiguous) != len(tt.ambiguous) {
+				t.Fatalf("неоднозначные %+v, ожидались %v", in.ambiguous, tt.ambiguous)
+			}
+			for _, a := range in.ambiguous {
+				if want, ok := tt.ambiguous[a.query]; !ok || !slices.Equal(ids(a.candidates), want) {
+					t.Errorf("кандидаты для %q: %v, ожидались %v", a.query, ids(a.candidates), want)
+				}
+			}
+		})
+	}
+}
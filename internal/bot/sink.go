@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+)
+
+// TelegramSink — доставка уведомлений об открытой приёмке подписчикам через очередь сообщений
+type TelegramSink struct {
+	queue *Queue
+}
+
+// NewTelegramSink — создать получателя событий для Telegram
+func NewTelegramSink(queue *Queue) *TelegramSink {
+	return &TelegramSink{queue: queue}
+}
+
+// HandleEvent — поставить уведомление в очередь; события без получателя и закрытие приёмки пропускаются
+func (t *TelegramSink) HandleEvent(ctx context.Context, e events.Event) error {
+	if e.Recipient == nil || e.Kind != events.SlotOpened {
+		return nil
+	}
+
+	r := e.Recipient
+	format := alertFormat{Lang: r.Lang, Template: r.Template, ParseMode: r.ParseMode}
+	text, parseMode := format.Render(slog.With(logging.Chat(r.ChatID)), e.Coefficient)
+	return t.queue.Enqueue(r.ChatID, storage.KindAlert, text, parseMode)
+}
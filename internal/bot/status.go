@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/wb"
+)
+
+const (
+	statusDays       = 14 // Дней в сетке /status
+	cheapCoefficient = 3  // До этого коэффициента включительно приёмка считается недорогой
+)
+
+// HandleStatus — коэффициенты складов чата на ближайшие дни из последнего ответа WB
+func HandleStatus(c *Context) {
+	warehouseIDs, err := c.Storage.GetChatWarehouses(c.ChatID())
+	if err != nil {
+		c.Log.Error("Ошибка получения складов чата", logging.Err(err))
+		c.Reply(c.T("my.error"))
+		return
+	}
+	if len(warehouseIDs) == 0 {
+		c.Reply(c.T("my.empty"))
+		return
+	}
+
+	coefficients, fetchedAt, err := c.Scheduler.Snapshot()
+	if err != nil {
+		c.Log.Warn("Коэффициенты для /status недоступны", logging.Err(err))
+		c.Reply(c.T("status.unavailable"))
+		return
+	}
+
+	byWarehouse := make(map[int][]wb.Coefficient)
+	for _, coefficient := range coefficients {
+		byWarehouse[coefficient.WarehouseID] = append(byWarehouse[coefficient.WarehouseID], coefficient)
+	}
+
+	days := statusDates(time.Now())
+	const maxMessageSize = 4000
+	text := c.T("status.header", days[0].Format("02.01"), days[len(days)-1].Format("02.01")) + "\n"
+	for _, id := range warehouseIDs {
+		name := c.Catalog.Name(id)
+		if name == "" {
+			name = c.T("warehouse.unknown_with_id", id)
+		}
+		block := fmt.Sprintf("\n🏭 %s (ID: %d)\n", name, id)
+		if rows := statusGrid(byWarehouse[id], days); len(rows) > 0 {
+			block += strings.Join(rows, "\n") + "\n"
+		} else {
+			block += c.T("status.no_data") + "\n"
+		}
+
+		if len(text)+len(block) > maxMessageSize {
+			c.Reply(text)
+			text = ""
+		}
+		text += block
+	}
+
+	text += "\n" + c.T("status.legend", cheapCoefficient, cheapCoefficient) + "\n" + statusAge(c, time.Since(fetchedAt))
+	c.Reply(text)
+}
+
+// statusDates — даты сетки начиная с сегодняшней; WB отдаёт даты как полночь UTC
+func statusDates(now time.Time) []time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := make([]time.Time, statusDays)
+	for i := range days {
+		days[i] = today.AddDate(0, 0, i)
+	}
+	return days
+}
+
+// statusGrid — строки «тип поставки: отметки по дням», типы по возрастанию ID
+func statusGrid(coefficients []wb.Coefficient, days []time.Time) []string {
+	byBoxType := make(map[int]map[string]wb.Coefficient)
+	names := make(map[int]string)
+	for _, c := range coefficients {
+		if byBoxType[c.BoxTypeID] == nil {
+			byBoxType[c.BoxTypeID] = make(map[string]wb.Coefficient)
+		}
+		// Дата приходит как 2024-09-04T00:00:00Z, сравниваем только день
+		byBoxType[c.BoxTypeID][dateKey(c.Date)] = c
+		names[c.BoxTypeID] = c.BoxTypeName
+	}
+
+	var rows []string
+	for _, boxTypeID := range slices.Sorted(maps.Keys(byBoxType)) {
+		var marks strings.Builder
+		for i, day := range days {
+			if i > 0 && i%7 == 0 {
+				marks.WriteByte(' ')
+			}
+			c, ok := byBoxType[boxTypeID][day.Format(time.DateOnly)]
+			marks.WriteString(statusMark(c, ok))
+		}
+		rows = append(rows, fmt.Sprintf("%s: %s", names[boxTypeID], marks.String()))
+	}
+	return rows
+}
+
+// dateKey — день из даты коэффициента в формате 2006-01-02
+func dateKey(date string) string {
+	if len(date) > len(time.DateOnly) {
+		return date[:len(time.DateOnly)]
+	}
+	return date
+}
+
+// statusMark — отметка дня: бесплатно, недорого, дорого или закрыто
+func statusMark(c wb.Coefficient, ok bool) string {
+	switch {
+	case !ok || !c.AllowUnload || c.Coefficient < 0:
+		return "⚫"
+	case c.Coefficient == 0:
+		return "🟢"
+	case c.Coefficient <= cheapCoefficient:
+		return "🟡"
+	default:
+		return "🔴"
+	}
+}
+
+// statusAge — сколько минут назад получены коэффициенты
+func statusAge(c *Context, age time.Duration) string {
+	minutes := int(age.Minutes())
+	if minutes < 1 {
+		return c.T("status.age_now")
+	}
+	return c.N("status.age", minutes)
+}
@@ -0,0 +1,389 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/i18n"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const inviteTTL = 7 * 24 * time.Hour // Срок действия ссылки-приглашения
+
+func HandleNewTeam(c *Context) {
+	name := strings.TrimSpace(c.Args)
+	if name == "" {
+		c.Reply(c.T("team.name_required"))
+		return
+	}
+
+	team, err := c.Storage.CreateTeam(name, c.From().ID)
+	if errors.Is(err, storage.ErrAlreadyInTeam) {
+		c.Reply(c.T("team.already_member"))
+		return
+	}
+	if err != nil {
+		c.Log.Error("Ошибка создания команды", logging.Err(err))
+		c.Reply(c.T("team.create_error"))
+		return
+	}
+
+	c.Reply(c.T("team.created", team.Name))
+}
+
+func HandleTeam(c *Context) {
+	member, team, ok := requireTeamMember(c)
+	if !ok {
+		return
+	}
+
+	members, err := c.Storage.GetTeamMembers(team.ID)
+	if err != nil {
+		c.Log.Error("Ошибка получения участников команды", teamAttr(team.ID), logging.Err(err))
+		c.Reply(c.T("team.fetch_error"))
+		return
+	}
+
+	warehouseIDs, err := c.Storage.GetTeamWarehouses(team.ID)
+	if err != nil {
+		c.Log.Error("Ошибка получения складов команды", teamAttr(team.ID), logging.Err(err))
+		c.Reply(c.T("team.fetch_error"))
+		return
+	}
+
+	text := c.T("team.header", team.Name, c.T("role."+member.Role)) + c.N("team.members", len(members))
+	for _, m := range members {
+		name := fmt.Sprint(m.TelegramID)
+		if user, err := c.Storage.GetUserByTelegramID(m.TelegramID); err == nil && user.Username != "" {
+			name = "@" + user.Username
+		}
+		text += fmt.Sprintf("- %s (ID: %d) — %s\n", name, m.TelegramID, c.T("role."+m.Role))
+	}
+
+	text += c.T("team.warehouses")
+	if len(warehouseIDs) == 0 {
+		text += c.T("team.no_warehouses")
+	}
+	for _, id := range warehouseIDs {
+		name := c.Catalog.Name(id)
+		if name == "" {
+			name = c.T("warehouse.unknown")
+		}
+		text += fmt.Sprintf("- %s (ID: %d)\n", name, id)
+	}
+
+	c.Reply(text)
+}
+
+func HandleInvite(c *Context) {
+	member, team, ok := requireTeamMember(c)
+	if !ok {
+		return
+	}
+	if member.Role != storage.RoleOwner {
+		c.Reply(c.T("team.invite_owner_only"))
+		return
+	}
+
+	role := strings.ToLower(strings.TrimSpace(c.Args))
+	if role == "" {
+		role = storage.RoleViewer
+	}
+	if !storage.IsValidRole(role) {
+		c.Reply(c.T("team.invite_bad_role"))
+		return
+	}
+
+	token, err := c.Storage.CreateTeamInvite(team.ID, role, inviteTTL)
+	if err != nil {
+		c.Log.Error("Ошибка создания приглашения в команду", teamAttr(team.ID), logging.Err(err))
+		c.Reply(c.T("team.invite_error"))
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s", c.BotName, token)
+	c.Reply(c.T("team.invite_link", team.Name, c.T("role."+role), link))
+}
+
+// handleJoinTeam — вступление в команду по токену из deep-link /start <token>
+func handleJoinTeam(c *Context, token string) {
+	team, role, err := c.Storage.AcceptTeamInvite(token, c.From().ID)
+	switch {
+	case errors.Is(err, storage.ErrInviteExpired):
+		c.Reply(c.T("team.invite_expired"))
+		return
+	case errors.Is(err, storage.ErrAlreadyInTeam):
+		c.Reply(c.T("team.already_member"))
+		return
+	case err != nil:
+		c.Log.Error("Ошибка вступления в команду", logging.Err(err))
+		c.Reply(c.T("team.join_error"))
+		return
+	}
+
+	c.Reply(c.T("team.joined", team.Name, c.T("role."+role)))
+
+	if c.From().UserName != "" {
+		// Владельцу пишем на его языке, а не на языке вступившего
+		lang := i18n.Default
+		if owner, err := c.Storage.GetUserByTelegramID(team.OwnerID); err == nil {
+			lang = userLanguage(*owner)
+		}
+		text := i18n.T(lang, "team.member_joined", c.From().UserName, team.Name, i18n.T(lang, "role."+role))
+		c.Send(tgbotapi.NewMessage(team.OwnerID, text))
+	}
+}
+
+func HandleTeamAddWarehouse(c *Context) {
+	_, team, ok := requireTeamEditor(c)
+	if !ok {
+		return
+	}
+
+	input := warehouseQuery(c)
+	if input == "" {
+		c.Ask(c.T("team.add_prompt"))
+		return
+	}
+
+	addWarehouses(c, input, "teamadd:", "team.add_done", func(w wb.Warehouse) error {
+		err := c.Storage.AddTeamSubscription(team.ID, w.ID)
+		if err != nil {
+			c.Log.Error("Ошибка добавления склада в команду", teamAttr(team.ID), logging.Warehouse(w.ID), logging.Err(err))
+		}
+		return err
+	})
+}
+
+// HandleTeamAddWarehouseCallback — выбор склада для общего списка кнопкой
+func HandleTeamAddWarehouseCallback(c *Context) {
+	// Без прав кнопки остаются: их может нажать редактор команды
+	_, team, ok := requireTeamEditor(c)
+	if !ok {
+		c.AnswerCallback("")
+		return
+	}
+	w, ok := callbackWarehouse(c)
+	if !ok {
+		return
+	}
+
+	if err := c.Storage.AddTeamSubscription(team.ID, w.ID); err != nil {
+		c.Log.Error("Ошибка добавления склада в команду", teamAttr(team.ID), logging.Warehouse(w.ID), logging.Err(err))
+		c.Reply(c.T("add.error"))
+		return
+	}
+
+	c.Reply(c.T("team.add_done", w.Name, w.ID))
+}
+
+func HandleTeamRemoveWarehouse(c *Context) {
+	_, team, ok := requireTeamEditor(c)
+	if !ok {
+		return
+	}
+
+	if c.Answer == "" {
+		c.Ask(c.T("team.remove_prompt"))
+		return
+	}
+
+	warehouseID, ok := parseWarehouseID(c.Answer)
+	if !ok {
+		c.Reply(c.T("error.invalid_warehouse"))
+		return
+	}
+
+	if err := c.Storage.RemoveTeamSubscription(team.ID, warehouseID); err != nil {
+		c.Log.Error("Ошибка удаления склада из команды", teamAttr(team.ID), logging.Warehouse(warehouseID), logging.Err(err))
+		c.Reply(c.T("remove.error"))
+		return
+	}
+
+	c.Reply(c.T("team.remove_done", warehouseID))
+}
+
+func HandleSetRole(c *Context) {
+	member, team, ok := requireTeamMember(c)
+	if !ok {
+		return
+	}
+	if member.Role != storage.RoleOwner {
+		c.Reply(c.T("team.role_owner_only"))
+		return
+	}
+
+	var telegramID int64
+	var role string
+	if _, err := fmt.Sscanf(c.Args, "%d %s", &telegramID, &role); err != nil || !storage.IsValidRole(role) {
+		c.Reply(c.T("team.setrole_usage"))
+		return
+	}
+
+	err := c.Storage.SetTeamMemberRole(team.ID, telegramID, role)
+	if storage.IsNotFound(err) {
+		c.Reply(c.T("team.member_not_found"))
+		return
+	}
+	if err != nil {
+		c.Log.Error("Ошибка смены роли участника", teamAttr(team.ID), "member_id", telegramID, logging.Err(err))
+		c.Reply(c.T("team.setrole_error"))
+		return
+	}
+
+	c.Reply(c.T("team.role_changed", telegramID, c.T("role."+role)))
+}
+
+func HandleKick(c *Context) {
+	member, team, ok := requireTeamMember(c)
+	if !ok {
+		return
+	}
+	if member.Role != storage.RoleOwner {
+		c.Reply(c.T("team.kick_owner_only"))
+		return
+	}
+
+	var telegramID int64
+	if _, err := fmt.Sscanf(c.Args, "%d", &telegramID); err != nil || telegramID == member.TelegramID {
+		c.Reply(c.T("team.kick_usage"))
+		return
+	}
+
+	err := c.Storage.RemoveTeamMember(team.ID, telegramID)
+	if storage.IsNotFound(err) {
+		c.Reply(c.T("team.member_not_found"))
+		return
+	}
+	if err != nil {
+		c.Log.Error("Ошибка исключения участника", teamAttr(team.ID), "member_id", telegramID, logging.Err(err))
+		c.Reply(c.T("team.kick_error"))
+		return
+	}
+
+	c.Reply(c.T("team.kicked", telegramID))
+}
+
+func HandleLeaveTeam(c *Context) {
+	member, team, ok := requireTeamMember(c)
+	if !ok {
+		return
+	}
+
+	if member.Role == storage.RoleOwner {
+		// Команда без владельца никому не подконтрольна, поэтому удаляем её целиком
+		if err := c.Storage.DeleteTeam(team.ID); err != nil {
+			c.Log.Error("Ошибка удаления команды", teamAttr(team.ID), logging.Err(err))
+			c.Reply(c.T("team.delete_error"))
+			return
+		}
+		c.Reply(c.T("team.deleted", team.Name))
+		return
+	}
+
+	if err := c.Storage.RemoveTeamMember(team.ID, member.TelegramID); err != nil {
+		c.Log.Error("Ошибка выхода из команды", teamAttr(team.ID), logging.Err(err))
+		c.Reply(c.T("team.leave_error"))
+		return
+	}
+
+	c.Reply(c.T("team.left", team.Name))
+}
+
+func HandleQuiet(c *Context) {
+	args := strings.TrimSpace(c.Args)
+	from, to := 0, 0
+	if args != "off" {
+		if _, err := fmt.Sscanf(args, "%d-%d", &from, &to); err != nil || from < 0 || from > 23 || to < 0 || to > 23 {
+			c.Reply(c.T("quiet.usage"))
+			return
+		}
+	}
+
+	if err := c.Storage.SetQuietHours(c.From().ID, from, to); err != nil {
+		c.Log.Error("Ошибка сохранения тихих часов", logging.Err(err))
+		c.Reply(c.T("quiet.error"))
+		return
+	}
+
+	if from == to {
+		c.Reply(c.T("quiet.off"))
+		return
+	}
+	c.Reply(c.T("quiet.on", from, to))
+}
+
+func HandleMute(c *Context) {
+	hours := 1
+	if args := strings.TrimSpace(c.Args); args != "" {
+		if _, err := fmt.Sscanf(args, "%d", &hours); err != nil || hours <= 0 {
+			c.Reply(c.T("mute.usage"))
+			return
+		}
+	}
+
+	until := time.Now().Add(time.Duration(hours) * time.Hour)
+	if err := c.Storage.SetMutedUntil(c.From().ID, &until); err != nil {
+		c.Log.Error("Ошибка выключения уведомлений", logging.Err(err))
+		c.Reply(c.T("mute.error"))
+		return
+	}
+
+	c.Reply(c.T("mute.on", until.In(mskLocation).Format("02.01 15:04")))
+}
+
+func HandleUnmute(c *Context) {
+	if err := c.Storage.SetMutedUntil(c.From().ID, nil); err != nil {
+		c.Log.Error("Ошибка включения уведомлений", logging.Err(err))
+		c.Reply(c.T("unmute.error"))
+		return
+	}
+
+	c.Reply(c.T("unmute.done"))
+}
+
+// requireTeamMember — получить команду отправителя или ответить, что он не в команде
+func requireTeamMember(c *Context) (*storage.TeamMember, *storage.Team, bool) {
+	member, err := c.Storage.GetTeamMember(c.From().ID)
+	if storage.IsNotFound(err) {
+		c.Reply(c.T("team.not_member"))
+		return nil, nil, false
+	}
+	if err != nil {
+		c.Log.Error("Ошибка получения команды пользователя", logging.Err(err))
+		return nil, nil, false
+	}
+
+	team, err := c.Storage.GetTeam(member.TeamID)
+	if err != nil {
+		c.Log.Error("Ошибка получения команды", teamAttr(member.TeamID), logging.Err(err))
+		return nil, nil, false
+	}
+
+	return member, team, true
+}
+
+// requireTeamEditor — то же, что requireTeamMember, но только для владельца и редакторов
+func requireTeamEditor(c *Context) (*storage.TeamMember, *storage.Team, bool) {
+	member, team, ok := requireTeamMember(c)
+	if !ok {
+		return nil, nil, false
+	}
+	if !member.CanEdit() {
+		c.Reply(c.T("team.editor_only"))
+		return nil, nil, false
+	}
+	return member, team, true
+}
+
+// teamAttr — поле журнала с ID команды
+func teamAttr(id uint) slog.Attr {
+	return slog.Uint64("team_id", uint64(id))
+}
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"strings"
+
+	"postavkinBot/internal/alert"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// templateScope — чей шаблон настраивается: личный или команды
+type templateScope struct {
+	team   *storage.Team // nil — личный шаблон
+	format alertFormat
+}
+
+func HandleTemplate(c *Context) {
+	args := strings.Fields(c.Args)
+
+	scope := templateScope{format: userAlertFormat(*c.User)}
+	if len(args) > 0 && strings.EqualFold(args[0], "team") {
+		member, team, ok := requireTeamMember(c)
+		if !ok {
+			return
+		}
+		args = args[1:]
+		if len(args) > 0 && !strings.EqualFold(args[0], "preview") && !member.CanEdit() {
+			c.Reply(c.T("team.editor_only"))
+			return
+		}
+		scope.team = team
+		if team.AlertTemplate != "" {
+			scope.format.Template, scope.format.ParseMode = team.AlertTemplate, team.AlertFormat
+		}
+	}
+
+	if len(args) == 0 {
+		showTemplate(c, scope)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "preview":
+		previewTemplate(c, scope)
+	case "set":
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		parseMode, ok := alert.ParseMode(name)
+		if !ok {
+			c.Reply(c.T("template.bad_format"))
+			return
+		}
+		if c.Answer == "" {
+			c.Ask(c.T("template.prompt"))
+			return
+		}
+		if _, err := alert.Parse(c.Answer, parseMode); err != nil {
+			c.Reply(c.T("template.invalid", err))
+			return
+		}
+		if err := saveTemplate(c, scope, c.Answer, parseMode); err != nil {
+			c.Log.Error("Ошибка сохранения шаблона уведомлений", logging.Err(err))
+			c.Reply(c.T("template.error"))
+			return
+		}
+		scope.format.Template, scope.format.ParseMode = c.Answer, parseMode
+		c.Reply(c.T("template.saved"))
+		previewTemplate(c, scope)
+	case "reset":
+		if err := saveTemplate(c, scope, "", ""); err != nil {
+			c.Log.Error("Ошибка сброса шаблона уведомлений", logging.Err(err))
+			c.Reply(c.T("template.error"))
+			return
+		}
+		c.Reply(c.T("template.reset"))
+	default:
+		c.Reply(c.T("template.usage"))
+	}
+}
+
+// showTemplate — текущий шаблон без разметки и список полей
+func showTemplate(c *Context, scope templateScope) {
+	owner := c.T("template.scope.personal")
+	if scope.team != nil {
+		owner = c.T("template.scope.team", scope.team.Name)
+	}
+
+	current, format := c.T("template.default"), c.T("template.format.text")
+	if scope.format.Template != "" {
+		current = scope.format.Template
+		if scope.format.ParseMode != alert.ParseModeText {
+			format = scope.format.ParseMode
+		}
+	}
+
+	c.Reply(c.T("template.show", owner, format, current))
+}
+
+// saveTemplate — сохранить шаблон в выбранной области
+func saveTemplate(c *Context, scope templateScope, template, parseMode string) error {
+	if scope.team != nil {
+		return c.Storage.SetTeamAlertTemplate(scope.team.ID, template, parseMode)
+	}
+	return c.Storage.SetUserAlertTemplate(c.User.TelegramID, template, parseMode)
+}
+
+// previewTemplate — отправить пример уведомления по текущим коэффициентам
+func previewTemplate(c *Context, scope templateScope) {
+	text, parseMode := scope.format.Render(c.Log, previewCoefficient(c, scope))
+
+	msg := tgbotapi.NewMessage(c.ChatID(), text)
+	msg.ParseMode = parseMode
+	if _, err := c.Bot.Send(msg); err != nil {
+		c.Log.Error("Ошибка отправки примера шаблона", logging.Err(err))
+		c.Reply(c.T("template.preview_error", err))
+	}
+}
+
+// previewCoefficient — коэффициент по одному из отслеживаемых складов, иначе любой или тестовый
+func previewCoefficient(c *Context, scope templateScope) wb.Coefficient {
+	coefficients, err := c.WB.GetAcceptanceCoefficients()
+	if err != nil || len(coefficients) == 0 {
+		if err != nil {
+			c.Log.Error("Ошибка получения коэффициентов для примера шаблона", logging.Err(err))
+		}
+		return alert.Sample()
+	}
+
+	var warehouseIDs []int
+	if scope.team != nil {
+		warehouseIDs, err = c.Storage.GetTeamWarehouses(scope.team.ID)
+	} else {
+		warehouseIDs, err = c.Storage.GetChatWarehouses(c.ChatID())
+	}
+	if err == nil {
+		for _, id := range warehouseIDs {
+			if coefficient := findCoefficient(coefficients, id); coefficient != nil {
+				return *coefficient
+			}
+		}
+	}
+	return coefficients[0]
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"postavkinBot/internal/logging"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// secretTokenHeader — заголовок, в котором Telegram передаёт secret_token из setWebhook
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookConfig — настройки режима webhook
+type WebhookConfig struct {
+	URL         string // Публичный адрес бота без секретного пути, например https://bot.example.com
+	Listen      string // Адрес HTTP-сервера, например :8443
+	PathSecret  string // Секретная часть пути, апдейты принимаются на /<PathSecret>
+	SecretToken string // Значение заголовка X-Telegram-Bot-Api-Secret-Token
+	CertFile    string // Сертификат для TLS (необязательно)
+	KeyFile     string // Ключ сертификата для TLS
+	UploadCert  bool   // Передать сертификат в setWebhook (для самоподписанных)
+	// Удалять webhook в Stop. По умолчанию намеренно выключено: webhook один на все экземпляры бота,
+	// и остановка одного из них не должна отключать приём апдейтов у остальных. Единственному
+	// экземпляру стоит включить, чтобы Telegram не слал апдейты на остановленный сервер.
+	DeleteOnStop bool
+}
+
+// path — путь, на котором сервер принимает апдейты
+func (c WebhookConfig) path() string {
+	if c.PathSecret == "" {
+		return "/webhook"
+	}
+	return "/" + strings.Trim(c.PathSecret, "/")
+}
+
+// UpdateSource — источник апдейтов: long polling или webhook
+type UpdateSource interface {
+	Stop(ctx context.Context) error
+}
+
+// pollingSource — получение апдейтов через getUpdates
+type pollingSource struct {
+	bot *tgbotapi.BotAPI
+}
+
+// StartPolling — запуск long polling, апдейты пишутся в out
+func StartPolling(bot *tgbotapi.BotAPI, out chan<- tgbotapi.Update) (UpdateSource, error) {
+	// getUpdates не работает, пока у бота установлен webhook
+	if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return nil, fmt.Errorf("ошибка удаления webhook: %w", err)
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	updates := bot.GetUpdatesChan(u)
+
+	go func() {
+		for update := range updates {
+			out <- update
+		}
+	}()
+
+	return &pollingSource{bot: bot}, nil
+}
+
+// Stop — остановка long polling
+func (p *pollingSource) Stop(ctx context.Context) error {
+	p.bot.StopReceivingUpdates()
+	return nil
+}
+
+// webhookSource — HTTP-сервер, принимающий апдейты от Telegram
+type webhookSource struct {
+	bot    *tgbotapi.BotAPI
+	cfg    WebhookConfig
+	server *http.Server
+	out    chan<- tgbotapi.Update
+	done   chan struct{} // Закрывается в Stop: апдейты больше некому обрабатывать
+}
+
+// StartWebhook — запуск HTTP-сервера и регистрация webhook в Telegram, апдейты пишутся в out.
+// Если сервер перестал слушать, например порт занят, ошибка передаётся в fail.
+func StartWebhook(bot *tgbotapi.BotAPI, cfg WebhookConfig, out chan<- tgbotapi.Update, fail func(error)) (UpdateSource, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("не задан публичный адрес webhook")
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("для TLS нужны и сертификат, и ключ")
+	}
+
+	w := &webhookSource{bot: bot, cfg: cfg, out: out, done: make(chan struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(cfg.path(), w.handle)
+	w.server = &http.Server{
+		Addr:     cfg.Listen,
+		Handler:  mux,
+		ErrorLog: logging.StdLogger(slog.Default(), slog.LevelWarn),
+	}
+
+	go func() {
+		var err error
+		if cfg.CertFile != "" {
+			err = w.server.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+		} else {
+			err = w.server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fail(fmt.Errorf("ошибка HTTP-сервера webhook: %w", err))
+		}
+	}()
+
+	if err := w.setWebhook(); err != nil {
+		w.server.Close()
+		return nil, err
+	}
+
+	slog.Info("Webhook слушает", "listen", cfg.Listen, "path", cfg.path())
+	return w, nil
+}
+
+// setWebhook — регистрация адреса в Telegram.
+// secret_token в WebhookConfig библиотеки нет, поэтому параметры собираются вручную.
+func (w *webhookSource) setWebhook() error {
+	params := tgbotapi.Params{
+		"url": strings.TrimRight(w.cfg.URL, "/") + w.cfg.path(),
+	}
+	params.AddNonEmpty("secret_token", w.cfg.SecretToken)
+
+	var err error
+	if w.cfg.UploadCert && w.cfg.CertFile != "" {
+		_, err = w.bot.UploadFiles("setWebhook", params, []tgbotapi.RequestFile{{
+			Name: "certificate",
+			Data: tgbotapi.FilePath(w.cfg.CertFile),
+		}})
+	} else {
+		_, err = w.bot.MakeRequest("setWebhook", params)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка установки webhook: %w", err)
+	}
+
+	return nil
+}
+
+// handle — приём одного апдейта
+func (w *webhookSource) handle(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Пустой секрет конфигурация не пропускает, но и здесь без него не принимаем ничего
+	got := r.Header.Get(secretTokenHeader)
+	if w.cfg.SecretToken == "" || subtle.ConstantTimeCompare([]byte(got), []byte(w.cfg.SecretToken)) != 1 {
+		http.Error(rw, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(rw, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case w.out <- update:
+		rw.WriteHeader(http.StatusOK)
+	case <-w.done:
+		// Бот останавливается: Telegram повторит доставку, возможно, другому экземпляру
+		http.Error(rw, "shutting down", http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+		// Telegram повторит доставку, если не получит ответ
+	}
+}
+
+// Stop — остановка HTTP-сервера. Webhook удаляется из Telegram только с DeleteOnStop, иначе остаётся
+// зарегистрированным, чтобы апдейты получали другие экземпляры; апдейты, пришедшие, пока экземпляров
+// нет, Telegram повторит после запуска.
+func (w *webhookSource) Stop(ctx context.Context) error {
+	// Обработчики, ждущие очереди апдейтов, отвечают сразу: иначе Shutdown ждал бы их до таймаута
+	close(w.done)
+	if w.cfg.DeleteOnStop {
+		if _, err := w.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			slog.Error("Ошибка удаления webhook", logging.Err(err))
+		}
+	} else {
+		slog.Info("Webhook остаётся зарегистрированным для других экземпляров, удаление — telegram.webhook.delete_on_stop")
+	}
+	return w.server.Shutdown(ctx)
+}
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookHandle(t *testing.T) {
+	out := make(chan tgbotapi.Update, 1)
+	w := &webhookSource{cfg: WebhookConfig{SecretToken: "secret"}, out: out, done: make(chan struct{})}
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		body   string
+		code   int
+	}{
+		{"апдейт", http.MethodPost, "secret", `{"update_id":1}`, http.StatusOK},
+		{"без секрета", http.MethodPost, "", `{"update_id":2}`, http.StatusForbidden},
+		{"чужой секрет", http.MethodPost, "other", `{"update_id":3}`, http.StatusForbidden},
+		{"не JSON", http.MethodPost, "secret", `{`, http.StatusBadRequest},
+		{"GET", http.MethodGet, "secret", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set(secretTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			w.handle(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("код %d, ожидался %d", rec.Code, tt.code)
+			}
+		})
+	}
+	if update := <-out; update.UpdateID != 1 {
+		t.Errorf("в очередь попал апдейт %d", update.UpdateID)
+	}
+}
+
+func TestWebhookStopReleasesHandlers(t *testing.T) {
+	// Очередь без читателя: обработчики апдейтов остановились раньше сервера
+	w := &webhookSource{
+		cfg:    WebhookConfig{SecretToken: "secret"},
+		server: &http.Server{},
+		out:    make(chan tgbotapi.Update),
+		done:   make(chan struct{}),
+	}
+
+	codes := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`))
+		req.Header.Set(secretTokenHeader, "secret")
+		rec := httptest.NewRecorder()
+		w.handle(rec, req)
+		codes <- rec.Code
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case code := <-codes:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("код %d, ожидался 503, чтобы Telegram повторил доставку", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("обработчик не завершился после Stop")
+	}
+}
@@ -0,0 +1,225 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/webhook"
+)
+
+// webhookLogSize — сколько последних доставок показывает /webhook log
+const webhookLogSize = 10
+
+// HandleWebhook — исходящие вебхуки: /webhook, /webhook add <url>, /webhook team <url>,
+// /webhook delete|enable|log <ID>
+func HandleWebhook(c *Context) {
+	if c.Keys == nil {
+		c.Reply(c.T("webhook.disabled"))
+		return
+	}
+
+	command, arg, _ := strings.Cut(strings.TrimSpace(c.Args), " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(command) {
+	case "":
+		listWebhooks(c)
+	case "add":
+		addWebhook(c, arg, nil)
+	case "team":
+		if _, team, ok := requireTeamEditor(c); ok {
+			addWebhook(c, arg, team)
+		}
+	case "delete", "remove":
+		if w, ok := ownWebhook(c, arg, true); ok {
+			if err := c.Storage.DeleteWebhook(w.ID); err != nil {
+				c.Log.Error("Ошибка удаления вебхука", webhookAttr(w.ID), logging.Err(err))
+				c.Reply(c.T("webhook.error"))
+				return
+			}
+			c.Reply(c.T("webhook.deleted", w.ID))
+		}
+	case "enable", "on":
+		if w, ok := ownWebhook(c, arg, true); ok {
+			if err := c.Storage.EnableWebhook(w.ID); err != nil {
+				c.Log.Error("Ошибка включения вебхука", webhookAttr(w.ID), logging.Err(err))
+				c.Reply(c.T("webhook.error"))
+				return
+			}
+			c.Reply(c.T("webhook.enabled", w.ID))
+		}
+	case "log":
+		if w, ok := ownWebhook(c, arg, false); ok {
+			webhookLog(c, w)
+		}
+	default:
+		c.Reply(c.T("webhook.usage"))
+	}
+}
+
+// listWebhooks — личные вебхуки и вебхуки команды отправителя
+func listWebhooks(c *Context) {
+	var teamID uint
+	if member, err := c.Storage.GetTeamMember(c.From().ID); err == nil {
+		teamID = member.TeamID
+	}
+
+	webhooks, err := c.Storage.GetWebhooks(c.From().ID, teamID)
+	if err != nil {
+		c.Log.Error("Ошибка получения вебхуков", logging.Err(err))
+		c.Reply(c.T("webhook.error"))
+		return
+	}
+	if len(webhooks) == 0 {
+		c.Reply(c.T("webhook.empty") + "\n\n" + c.T("webhook.usage"))
+		return
+	}
+
+	text := c.T("webhook.header")
+	for _, w := range webhooks {
+		status := "✅"
+		if !w.Enabled {
+			status = "⛔"
+		}
+		text += fmt.Sprintf("\n%s #%d %s", status, w.ID, w.URL)
+		if w.TeamID != 0 {
+			text += " " + c.T("webhook.team_mark")
+		}
+		if !w.Enabled && w.LastError != "" {
+			text += "\n   " + c.T("webhook.last_error", w.LastError)
+		}
+	}
+	c.Reply(text + "\n\n" + c.T("webhook.usage"))
+}
+
+// addWebhook — добавить вебхук чата отправителя или команды team и показать секрет подписи
+func addWebhook(c *Context, url string, team *storage.Team) {
+	if url == "" {
+		c.Reply(c.T("webhook.usage"))
+		return
+	}
+	if err := webhook.ValidateURL(url, c.Config.Webhooks.AllowPrivate); err != nil {
+		if errors.Is(err, webhook.ErrPrivateAddress) {
+			c.Reply(c.T("webhook.private_url"))
+		} else {
+			c.Reply(c.T("webhook.invalid_url"))
+		}
+		return
+	}
+
+	w := &storage.Webhook{ChatID: c.From().ID, CreatedBy: c.From().ID, URL: url, Enabled: true}
+	var teamID uint
+	if team != nil {
+		w.ChatID, w.TeamID, teamID = 0, team.ID, team.ID
+	}
+	existing, err := c.Storage.GetWebhooks(w.ChatID, teamID)
+	if err != nil {
+		c.Log.Error("Ошибка получения вебхуков", logging.Err(err))
+		c.Reply(c.T("webhook.error"))
+		return
+	}
+	owned := 0
+	for _, other := range existing {
+		if other.ChatID == w.ChatID && other.TeamID == w.TeamID {
+			owned++
+		}
+	}
+	if owned >= c.Config.Webhooks.MaxPerOwner {
+		c.Reply(c.N("webhook.limit", c.Config.Webhooks.MaxPerOwner))
+		return
+	}
+
+	secretKey := webhook.NewSecret()
+	if w.Secret, err = c.Keys.Encrypt(secretKey); err != nil {
+		c.Log.Error("Ошибка шифрования секрета вебхука", logging.Err(err))
+		c.Reply(c.T("webhook.error"))
+		return
+	}
+	if err := c.Storage.CreateWebhook(w); err != nil {
+		c.Log.Error("Ошибка сохранения вебхука", logging.Err(err))
+		c.Reply(c.T("webhook.error"))
+		return
+	}
+
+	c.Log.Info("Добавлен вебхук", webhookAttr(w.ID), teamAttr(w.TeamID))
+	c.Reply(c.T("webhook.added", w.ID, secretKey))
+}
+
+// ownWebhook — вебхук по ID из аргумента, если он личный у отправителя или принадлежит его команде.
+// edit — нужно право менять: у командного вебхука это владелец и редакторы.
+func ownWebhook(c *Context, arg string, edit bool) (*storage.Webhook, bool) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(arg, "#"), 10, 0)
+	if err != nil {
+		c.Reply(c.T("webhook.usage"))
+		return nil, false
+	}
+
+	w, err := c.Storage.GetWebhook(uint(id))
+	if err != nil && !storage.IsNotFound(err) {
+		c.Log.Error("Ошибка получения вебхука", webhookAttr(uint(id)), logging.Err(err))
+		c.Reply(c.T("webhook.error"))
+		return nil, false
+	}
+	if w != nil && w.TeamID == 0 && w.ChatID == c.From().ID {
+		return w, true
+	}
+	if w != nil && w.TeamID != 0 {
+		if member, err := c.Storage.GetTeamMember(c.From().ID); err == nil && member.TeamID == w.TeamID {
+			if edit && !member.CanEdit() {
+				c.Reply(c.T("team.editor_only"))
+				return nil, false
+			}
+			return w, true
+		}
+	}
+	c.Reply(c.T("webhook.not_found", id))
+	return nil, false
+}
+
+// webhookLog — последние доставки вебхука
+func webhookLog(c *Context, w *storage.Webhook) {
+	deliveries, err := c.Storage.GetWebhookDeliveries(w.ID, webhookLogSize)
+	if err != nil {
+		c.Log.Error("Ошибка получения доставок вебхука", webhookAttr(w.ID), logging.Err(err))
+		c.Reply(c.T("webhook.error"))
+		return
+	}
+	if len(deliveries) == 0 {
+		c.Reply(c.T("webhook.log_empty", w.ID))
+		return
+	}
+
+	text := c.T("webhook.log_header", w.ID, w.URL)
+	for _, d := range deliveries {
+		var status string
+		switch d.Status {
+		case storage.DeliveryDelivered:
+			status = c.T("webhook.log_delivered", d.ResponseCode)
+		case storage.DeliveryDead:
+			status = c.T("webhook.log_dead", d.LastError)
+		default:
+			status = c.T("webhook.log_pending", d.Attempts)
+			if d.LastError != "" {
+				status += ": " + d.LastError
+			}
+		}
+		text += fmt.Sprintf("\n%s %s #%d — %s", d.CreatedAt.In(mskLocation).Format("02.01 15:04"), d.Event, d.WarehouseID, status)
+	}
+	c.Reply(text)
+}
+
+// NotifyWebhookDisabled — сообщить добавившему вебхук, что он отключён после серии ошибок
+func NotifyWebhookDisabled(deps *Deps) func(w storage.Webhook) {
+	return func(w storage.Webhook) {
+		notifyUser(deps, w.CreatedBy, "webhook.auto_disabled", w.ID, w.URL, w.LastError)
+	}
+}
+
+// webhookAttr — поле журнала с ID вебхука
+func webhookAttr(id uint) slog.Attr {
+	return slog.Uint64("webhook_id", uint64(id))
+}
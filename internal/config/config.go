@@ -0,0 +1,443 @@
+// Package config — настройки бота: значения по умолчанию, файл YAML или TOML
+// и переопределения из переменных окружения (имена указаны в тегах env).
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"postavkinBot/internal/secret"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFiles — где искать файл настроек, если путь не указан явно
+var DefaultFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// Config — все настройки бота
+type Config struct {
+	Telegram  Telegram  `yaml:"telegram" toml:"telegram"`
+	Database  Database  `yaml:"database" toml:"database"`
+	WB        WB        `yaml:"wb" toml:"wb"`
+	Scheduler Scheduler `yaml:"scheduler" toml:"scheduler"`
+	Limits    Limits    `yaml:"limits" toml:"limits"`
+	Log       Log       `yaml:"log" toml:"log"`
+	Metrics   Metrics   `yaml:"metrics" toml:"metrics"`
+	Leader    Leader    `yaml:"leader" toml:"leader"`
+	Webhooks  Webhooks  `yaml:"webhooks" toml:"webhooks"`
+	API       API       `yaml:"api" toml:"api"`
+	Retention Retention `yaml:"retention" toml:"retention"`
+
+	Admins          []int64       `yaml:"admins" toml:"admins" env:"ADMIN_IDS"`                            // Telegram ID администраторов бота
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"` // Сколько ждать завершения работы
+}
+
+// Telegram — подключение к Bot API
+type Telegram struct {
+	Token   string  `yaml:"token" toml:"token" env:"TELEGRAM_BOT_TOKEN"`
+	Mode    string  `yaml:"mode" toml:"mode" env:"BOT_MODE"` // polling или webhook
+	Webhook Webhook `yaml:"webhook" toml:"webhook"`
+}
+
+// Webhook — приём апдейтов через webhook
+type Webhook struct {
+	URL         string `yaml:"url" toml:"url" env:"WEBHOOK_URL"`
+	Listen      string `yaml:"listen" toml:"listen" env:"WEBHOOK_LISTEN"`
+	PathSecret  string `yaml:"path_secret" toml:"path_secret" env:"WEBHOOK_PATH_SECRET"`
+	SecretToken string `yaml:"secret_token" toml:"secret_token" env:"WEBHOOK_SECRET_TOKEN"`
+	CertFile    string `yaml:"cert_file" toml:"cert_file" env:"WEBHOOK_CERT_FILE"`
+	KeyFile     string `yaml:"key_file" toml:"key_file" env:"WEBHOOK_KEY_FILE"`
+	UploadCert  bool   `yaml:"upload_cert" toml:"upload_cert" env:"WEBHOOK_UPLOAD_CERT"`
+	// Удалять webhook при остановке. Только для единственного экземпляра: при нескольких
+	// остановка одного отключила бы приём апдейтов у всех остальных.
+	DeleteOnStop bool `yaml:"delete_on_stop" toml:"delete_on_stop" env:"WEBHOOK_DELETE_ON_STOP"`
+}
+
+// Database — хранилище
+type Database struct {
+	DSN  string `yaml:"dsn" toml:"dsn" env:"DATABASE_URL"` // postgres://… для PostgreSQL, важнее Path
+	Path string `yaml:"path" toml:"path" env:"DB_PATH"`    // Файл SQLite
+}
+
+// Source — DSN для storage.NewStorage: DSN, если задан, иначе путь к файлу SQLite
+func (d Database) Source() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+	return d.Path
+}
+
+// WB — API Wildberries
+type WB struct {
+	BaseURL string        `yaml:"base_url" toml:"base_url" env:"WB_BASE_URL"`
+	APIKey  string        `yaml:"api_key" toml:"api_key" env:"WB_API_KEY"`
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"WB_TIMEOUT"`
+	// MasterKey — 32 байта в base64 или hex для шифрования ключей пользователей; пусто — /apikey выключена
+	MasterKey string `yaml:"master_key" toml:"master_key" env:"WB_MASTER_KEY"`
+	// KeyExpiryWarning — за сколько до истечения ключа WB предупреждать администраторов и владельцев ключей
+	KeyExpiryWarning time.Duration `yaml:"key_expiry_warning" toml:"key_expiry_warning" env:"WB_KEY_EXPIRY_WARNING"`
+}
+
+// Scheduler — проверка коэффициентов
+type Scheduler struct {
+	CheckInterval     time.Duration `yaml:"check_interval" toml:"check_interval" env:"CHECK_INTERVAL"`
+	RepeatNotifyDelay time.Duration `yaml:"repeat_notify_delay" toml:"repeat_notify_delay" env:"REPEAT_NOTIFY_DELAY"`
+	RateLimitPause    time.Duration `yaml:"rate_limit_pause" toml:"rate_limit_pause" env:"WB_RATE_LIMIT_PAUSE"` // Пауза после 429 от WB
+	Workers           int           `yaml:"workers" toml:"workers" env:"SCHEDULER_WORKERS"`                     // Сколько складов проверять параллельно
+}
+
+// Limits — ограничения частоты запросов к боту и отправки сообщений
+type Limits struct {
+	UserRequests        int           `yaml:"user_requests" toml:"user_requests" env:"USER_RATE_LIMIT"`
+	UserPeriod          time.Duration `yaml:"user_period" toml:"user_period" env:"USER_RATE_PERIOD"`
+	GlobalPerSecond     int           `yaml:"global_per_second" toml:"global_per_second" env:"SEND_GLOBAL_PER_SECOND"`
+	PrivateSendInterval time.Duration `yaml:"private_send_interval" toml:"private_send_interval" env:"SEND_PRIVATE_INTERVAL"`
+	GroupSendInterval   time.Duration `yaml:"group_send_interval" toml:"group_send_interval" env:"SEND_GROUP_INTERVAL"`
+	MaxSendAttempts     int           `yaml:"max_send_attempts" toml:"max_send_attempts" env:"SEND_MAX_ATTEMPTS"`
+}
+
+// Metrics — HTTP-сервер с /metrics, /healthz и /readyz
+type Metrics struct {
+	Listen       string        `yaml:"listen" toml:"listen" env:"METRICS_LISTEN"` // Пусто — сервер не запускается
+	ReadyMaxAge  time.Duration `yaml:"ready_max_age" toml:"ready_max_age" env:"METRICS_READY_MAX_AGE"`
+	HealthMaxAge time.Duration `yaml:"health_max_age" toml:"health_max_age" env:"METRICS_HEALTH_MAX_AGE"`
+}
+
+// Leader — выбор ведущего экземпляра: только он опрашивает WB и отправляет уведомления
+type Leader struct {
+	LeaseTTL   time.Duration `yaml:"lease_ttl" toml:"lease_ttl" env:"LEADER_LEASE_TTL"` // Сколько живёт аренда без продления
+	InstanceID string        `yaml:"instance_id" toml:"instance_id" env:"INSTANCE_ID"`  // Пусто — имя хоста, PID и случайный суффикс
+}
+
+// Webhooks — исходящие вебхуки о событиях по складам (приём апдейтов Telegram — Telegram.Webhook).
+// Секреты подписи шифруются ключом wb.master_key: без него вебхуки выключены.
+type Webhooks struct {
+	Timeout      time.Duration `yaml:"timeout" toml:"timeout" env:"OUTBOUND_WEBHOOK_TIMEOUT"`                   // Ожидание ответа на одну доставку
+	MaxAttempts  int           `yaml:"max_attempts" toml:"max_attempts" env:"OUTBOUND_WEBHOOK_MAX_ATTEMPTS"`    // Попыток доставки одного события
+	DisableAfter int           `yaml:"disable_after" toml:"disable_after" env:"OUTBOUND_WEBHOOK_DISABLE_AFTER"` // Неудачных попыток подряд до отключения вебхука
+	MaxPerOwner  int           `yaml:"max_per_owner" toml:"max_per_owner" env:"OUTBOUND_WEBHOOK_MAX_PER_OWNER"` // Вебхуков у пользователя или команды
+	AllowPrivate bool          `yaml:"allow_private" toml:"allow_private" env:"OUTBOUND_WEBHOOK_ALLOW_PRIVATE"` // Разрешить адреса локальной сети, только для отладки
+}
+
+// API — REST API для пользователей с токенами из /apitoken
+type API struct {
+	Listen    string `yaml:"listen" toml:"listen" env:"API_LISTEN"`             // Пусто — API выключено
+	MaxTokens int    `yaml:"max_tokens" toml:"max_tokens" env:"API_MAX_TOKENS"` // Токенов у одного пользователя
+
+	TokenRequests int           `yaml:"token_requests" toml:"token_requests" env:"API_TOKEN_RATE_LIMIT"` // Запросов по одному токену за token_period
+	TokenPeriod   time.Duration `yaml:"token_period" toml:"token_period" env:"API_TOKEN_RATE_PERIOD"`
+}
+
+// Retention — сколько хранить служебные записи; старые удаляет ведущий экземпляр раз в час
+type Retention struct {
+	Messages   time.Duration `yaml:"messages" toml:"messages" env:"RETENTION_MESSAGES"`               // Отправленные и недоставленные сообщения очереди
+	Events     time.Duration `yaml:"events" toml:"events" env:"RETENTION_EVENTS"`                     // История событий по складам
+	Deliveries time.Duration `yaml:"deliveries" toml:"deliveries" env:"RETENTION_WEBHOOK_DELIVERIES"` // Доставленные и недоставленные события вебхуков
+}
+
+// Log — журналирование
+type Log struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`    // debug, info, warn или error; debug включает отладку запросов к Telegram
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"` // json или text
+}
+
+// Default — настройки по умолчанию
+func Default() Config {
+	return Config{
+		Telegram: Telegram{
+			Mode:    "polling",
+			Webhook: Webhook{Listen: ":8443"},
+		},
+		Database: Database{Path: "data.db"},
+		WB: WB{
+			BaseURL:          "https://supplies-api.wildberries.ru",
+			Timeout:          30 * time.Second,
+			KeyExpiryWarning: 7 * 24 * time.Hour,
+		},
+		Scheduler: Scheduler{
+			CheckInterval:     15 * time.Second,
+			RepeatNotifyDelay: time.Minute,
+			RateLimitPause:    time.Minute,
+			Workers:           8,
+		},
+		Limits: Limits{
+			UserRequests:        20,
+			UserPeriod:          time.Minute,
+			GlobalPerSecond:     30,
+			PrivateSendInterval: time.Second,
+			GroupSendInterval:   3 * time.Second,
+			MaxSendAttempts:     8,
+		},
+		Webhooks: Webhooks{
+			Timeout:      10 * time.Second,
+			MaxAttempts:  6,
+			DisableAfter: 20,
+			MaxPerOwner:  5,
+		},
+		API: API{MaxTokens: 5, TokenRequests: 60, TokenPeriod: time.Minute},
+		Retention: Retention{
+			Messages:   30 * 24 * time.Hour,
+			Events:     90 * 24 * time.Hour,
+			Deliveries: 14 * 24 * time.Hour,
+		},
+		Log: Log{Level: "info", Format: "json"},
+		Metrics: Metrics{
+			ReadyMaxAge:  2 * time.Minute,
+			HealthMaxAge: 10 * time.Minute,
+		},
+		Leader:          Leader{LeaseTTL: 30 * time.Second},
+		ShutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Load — собрать настройки: значения по умолчанию, затем файл path (пустой путь —
+// первый найденный из DefaultFiles, если есть), затем переменные окружения.
+// Результат проверяется Validate.
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read — как Load, но без Validate: служебным командам вроде migrate токены не нужны
+func Read(path string) (*Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		for _, name := range DefaultFiles {
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+	}
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// loadFile — прочитать YAML или TOML по расширению файла
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("чтение файла настроек: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		_, err = toml.Decode(string(data), cfg)
+	default:
+		return fmt.Errorf("неизвестный формат файла настроек %s (ожидается .yaml, .yml или .toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("разбор %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv — переопределить поля с тегом env значениями непустых переменных окружения
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+
+		name := field.Tag.Get("env")
+		if name == "" {
+			if value.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+				if err := applyEnv(value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("переменная %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setValue — записать строковое значение переменной окружения в поле
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		// Списки чисел через запятую, например ADMIN_IDS=1,2,3
+		parts := strings.Split(raw, ",")
+		list := reflect.MakeSlice(v.Type(), 0, len(parts))
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			item := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(item, part); err != nil {
+				return err
+			}
+			list = reflect.Append(list, item)
+		}
+		v.Set(list)
+	default:
+		return fmt.Errorf("неподдерживаемый тип %s", v.Type())
+	}
+	return nil
+}
+
+// Validate — проверить настройки, все ошибки возвращаются разом
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Telegram.Token != "", "не задан токен бота (telegram.token или TELEGRAM_BOT_TOKEN)")
+	check(c.WB.APIKey != "", "не задан ключ WB API (wb.api_key или WB_API_KEY)")
+	check(c.Database.Source() != "", "не задана база (database.dsn или DATABASE_URL, database.path или DB_PATH)")
+
+	if u, err := url.Parse(c.WB.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("некорректный wb.base_url: %q", c.WB.BaseURL))
+	}
+	check(c.WB.Timeout > 0, "wb.timeout должен быть больше нуля")
+	check(c.WB.KeyExpiryWarning >= 0, "wb.key_expiry_warning не может быть отрицательным")
+	if c.WB.MasterKey != "" {
+		if _, err := secret.ParseKey(c.WB.MasterKey); err != nil {
+			errs = append(errs, fmt.Errorf("некорректный wb.master_key: %w", err))
+		}
+	}
+
+	switch c.Telegram.Mode {
+	case "polling":
+	case "webhook":
+		check(c.Telegram.Webhook.URL != "", "для режима webhook нужен telegram.webhook.url")
+		check(c.Telegram.Webhook.Listen != "", "для режима webhook нужен telegram.webhook.listen")
+		// Без секрета любой, кто достучится до порта, сможет прислать поддельный апдейт от имени администратора
+		check(c.Telegram.Webhook.SecretToken != "", "для режима webhook нужен telegram.webhook.secret_token")
+		check(validSecretToken(c.Telegram.Webhook.SecretToken),
+			"telegram.webhook.secret_token: до 256 символов A-Z, a-z, 0-9, _ и -")
+		check((c.Telegram.Webhook.CertFile == "") == (c.Telegram.Webhook.KeyFile == ""),
+			"telegram.webhook.cert_file и key_file задаются только вместе")
+	default:
+		errs = append(errs, fmt.Errorf("неизвестный telegram.mode %q (ожидается polling или webhook)", c.Telegram.Mode))
+	}
+
+	check(c.Scheduler.CheckInterval >= time.Second, "scheduler.check_interval должен быть не меньше 1s")
+	check(c.Scheduler.RepeatNotifyDelay >= 0, "scheduler.repeat_notify_delay не может быть отрицательным")
+	check(c.Scheduler.RateLimitPause > 0, "scheduler.rate_limit_pause должен быть больше нуля")
+	check(c.Scheduler.Workers > 0, "scheduler.workers должен быть больше нуля")
+
+	check(c.Limits.UserRequests > 0 && c.Limits.UserPeriod > 0, "limits.user_requests и limits.user_period должны быть больше нуля")
+	check(c.Limits.GlobalPerSecond > 0 && c.Limits.GlobalPerSecond <= 30, "limits.global_per_second должен быть от 1 до 30")
+	check(c.Limits.PrivateSendInterval > 0 && c.Limits.GroupSendInterval > 0, "интервалы отправки должны быть больше нуля")
+	check(c.Limits.MaxSendAttempts > 0, "limits.max_send_attempts должен быть больше нуля")
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("неизвестный log.level %q (ожидается debug, info, warn или error)", c.Log.Level))
+	}
+	check(c.Log.Format == "json" || c.Log.Format == "text", "неизвестный log.format %q (ожидается json или text)", c.Log.Format)
+
+	if c.Metrics.Listen != "" {
+		check(c.Metrics.ReadyMaxAge > c.Scheduler.CheckInterval, "metrics.ready_max_age должен быть больше scheduler.check_interval")
+		check(c.Metrics.HealthMaxAge >= c.Metrics.ReadyMaxAge, "metrics.health_max_age не может быть меньше ready_max_age")
+	}
+
+	check(c.Leader.LeaseTTL >= 3*time.Second, "leader.lease_ttl должен быть не меньше 3s")
+
+	check(c.Retention.Messages >= 24*time.Hour, "retention.messages должен быть не меньше 24h: по ним считается /stats за день")
+	check(c.Retention.Events >= 24*time.Hour, "retention.events должен быть не меньше 24h")
+	check(c.Retention.Deliveries >= 24*time.Hour, "retention.deliveries должен быть не меньше 24h")
+
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout должен быть больше нуля")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts должен быть больше нуля")
+	check(c.Webhooks.DisableAfter > 0, "webhooks.disable_after должен быть больше нуля")
+	check(c.Webhooks.MaxPerOwner > 0, "webhooks.max_per_owner должен быть больше нуля")
+	check(c.API.MaxTokens > 0, "api.max_tokens должен быть больше нуля")
+	check(c.API.TokenRequests > 0, "api.token_requests должен быть больше нуля")
+	check(c.API.TokenPeriod > 0, "api.token_period должен быть больше нуля")
+	if c.API.Listen != "" && c.Metrics.Listen == c.API.Listen {
+		errs = append(errs, errors.New("api.listen и metrics.listen должны отличаться"))
+	}
+
+	for _, id := range c.Admins {
+		check(id > 0, "некорректный ID администратора: %d", id)
+	}
+	check(c.ShutdownTimeout > 0, "shutdown_timeout должен быть больше нуля")
+
+	return errors.Join(errs...)
+}
+
+// IsAdmin — является ли пользователь администратором бота
+func (c *Config) IsAdmin(telegramID int64) bool {
+	for _, id := range c.Admins {
+		if id == telegramID {
+			return true
+		}
+	}
+	return false
+}
+
+// validSecretToken — секрет webhook в формате, который принимает Telegram
+func validSecretToken(token string) bool {
+	if len(token) > 256 {
+		return false
+	}
+	for _, r := range token {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv — сбросить все переменные настроек, чтобы окружение машины не влияло на тест
+func clearEnv(t *testing.T, typ reflect.Type) {
+	t.Helper()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if name := field.Tag.Get("env"); name != "" {
+			t.Setenv(name, "")
+		} else if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			clearEnv(t, field.Type)
+		}
+	}
+}
+
+// writeFile — файл настроек name с содержимым data во временном каталоге
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		env     map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			data: "telegram:\n  token: file-token\nscheduler:\n  check_interval: 30s\nadmins: [1, 2]\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Telegram.Token != "file-token" || cfg.Scheduler.CheckInterval != 30*time.Second || !reflect.DeepEqual(cfg.Admins, []int64{1, 2}) {
+					t.Errorf("настройки из файла не применились: %+v", cfg)
+				}
+				if cfg.Scheduler.Workers != Default().Scheduler.Workers {
+					t.Errorf("незаданное в файле значение %d, ожидалось по умолчанию", cfg.Scheduler.Workers)
+				}
+			},
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			data: "admins = [1, 2]\n\n[telegram]\ntoken = \"file-token\"\n\n[scheduler]\ncheck_interval = \"30s\"\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Telegram.Token != "file-token" || cfg.Scheduler.CheckInterval != 30*time.Second || !reflect.DeepEqual(cfg.Admins, []int64{1, 2}) {
+					t.Errorf("настройки из файла не применились: %+v", cfg)
+				}
+			},
+		},
+		{
+			name: "окружение важнее файла",
+			file: "config.yaml",
+			data: "telegram:\n  token: file-token\n  mode: polling\nscheduler:\n  check_interval: 30s\nadmins: [1]\n",
+			env: map[string]string{
+				"TELEGRAM_BOT_TOKEN":             "env-token",
+				"CHECK_INTERVAL":                 "1m",
+				"ADMIN_IDS":                      "3, 4",
+				"OUTBOUND_WEBHOOK_ALLOW_PRIVATE": "true",
+				"BOT_MODE":                       "",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Telegram.Token != "env-token" || cfg.Scheduler.CheckInterval != time.Minute || !reflect.DeepEqual(cfg.Admins, []int64{3, 4}) {
+					t.Errorf("окружение не переопределило файл: %+v", cfg)
+				}
+				if !cfg.Webhooks.AllowPrivate {
+					t.Error("логическое значение из окружения не применилось")
+				}
+				if cfg.Telegram.Mode != "polling" {
+					t.Errorf("пустая переменная стёрла значение из файла: %q", cfg.Telegram.Mode)
+				}
+			},
+		},
+		{
+			name:    "некорректная переменная",
+			file:    "config.yaml",
+			data:    "log:\n  level: info\n",
+			env:     map[string]string{"CHECK_INTERVAL": "часто"},
+			wantErr: "CHECK_INTERVAL",
+		},
+		{
+			name:    "неизвестный формат",
+			file:    "config.json",
+			data:    "{}",
+			wantErr: "неизвестный формат",
+		},
+		{
+			name:    "ошибка разбора",
+			file:    "config.yaml",
+			data:    "telegram: [",
+			wantErr: "разбор",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, reflect.TypeOf(Config{}))
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Read(writeFile(t, tt.file, tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ошибка %v, ожидалась с %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestReadExample(t *testing.T) {
+	clearEnv(t, reflect.TypeOf(Config{}))
+	if _, err := Read(filepath.Join("..", "..", "config.example.yaml")); err != nil {
+		t.Fatalf("пример настроек не читается: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Config {
+		cfg := Default()
+		cfg.Telegram.Token = "123:token"
+		cfg.WB.APIKey = "wb-key"
+		return cfg
+	}
+	webhook := func(cfg *Config) {
+		cfg.Telegram.Mode = "webhook"
+		cfg.Telegram.Webhook.URL = "https://bot.example.com"
+		cfg.Telegram.Webhook.SecretToken = "secret_token-1"
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{"по умолчанию с токенами", func(cfg *Config) {}, ""},
+		{"webhook", webhook, ""},
+		{"без токена бота", func(cfg *Config) { cfg.Telegram.Token = "" }, "токен бота"},
+		{"webhook без адреса прослушивания", func(cfg *Config) {
+			webhook(cfg)
+			cfg.Telegram.Webhook.Listen = ""
+		}, "telegram.webhook.listen"},
+		{"webhook без секрета", func(cfg *Config) {
+			webhook(cfg)
+			cfg.Telegram.Webhook.SecretToken = ""
+		}, "telegram.webhook.secret_token"},
+		{"секрет webhook с недопустимыми символами", func(cfg *Config) {
+			webhook(cfg)
+			cfg.Telegram.Webhook.SecretToken = "секрет"
+		}, "A-Z, a-z"},
+		{"сертификат без ключа", func(cfg *Config) {
+			webhook(cfg)
+			cfg.Telegram.Webhook.CertFile = "cert.pem"
+		}, "cert_file и key_file"},
+		{"неизвестный режим", func(cfg *Config) { cfg.Telegram.Mode = "push" }, "telegram.mode"},
+		{"некорректный мастер-ключ", func(cfg *Config) { cfg.WB.MasterKey = "short" }, "wb.master_key"},
+		{"слишком частая проверка", func(cfg *Config) { cfg.Scheduler.CheckInterval = time.Millisecond }, "scheduler.check_interval"},
+		{"API и метрики на одном адресе", func(cfg *Config) {
+			cfg.API.Listen, cfg.Metrics.Listen = ":9090", ":9090"
+		}, "api.listen и metrics.listen"},
+		{"некорректный администратор", func(cfg *Config) { cfg.Admins = []int64{0} }, "ID администратора"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("корректные настройки отклонены: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ошибка %v, ожидалась с %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	clearEnv(t, reflect.TypeOf(Config{}))
+	path := writeFile(t, "config.yaml", "telegram:\n  token: t\n  mode: webhook\n  webhook:\n    url: https://bot.example.com\n    listen: \"\"\n    secret_token: s\nwb:\n  api_key: k\n")
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "telegram.webhook.listen") {
+		t.Errorf("Load = %v, ожидалась ошибка про telegram.webhook.listen", err)
+	}
+	if _, err := Read(path); err != nil {
+		t.Errorf("Read проверять настройки не должен: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/metrics"
+)
+
+// Sink — получатель событий
+type Sink interface {
+	HandleEvent(ctx context.Context, e Event) error
+}
+
+// SinkFunc — функция как получатель событий
+type SinkFunc func(ctx context.Context, e Event) error
+
+// HandleEvent — вызвать функцию
+func (f SinkFunc) HandleEvent(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+// syncSink — получатель, которого Publish вызывает сразу
+type syncSink struct {
+	name string
+	sink Sink
+}
+
+// asyncSink — получатель со своей очередью и горутиной
+type asyncSink struct {
+	name  string
+	sink  Sink
+	queue chan Event
+}
+
+// Bus — шина событий. Получатели подписываются до запуска Run.
+type Bus struct {
+	syncSinks  []syncSink
+	asyncSinks []*asyncSink
+}
+
+// NewBus — создать шину
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// SubscribeSync — получатель, которого Publish вызывает в своей горутине; его ошибки возвращает Publish.
+// Publish вызывают параллельно, поэтому получатель должен быть потокобезопасным и быстрым.
+func (b *Bus) SubscribeSync(name string, sink Sink) {
+	b.syncSinks = append(b.syncSinks, syncSink{name: name, sink: sink})
+}
+
+// Subscribe — получатель с очередью на buffer событий, обрабатывает их по одному в своей горутине.
+// Если очередь заполнена, новые события для него отбрасываются: медленный получатель не тормозит проверку.
+func (b *Bus) Subscribe(name string, sink Sink, buffer int) {
+	b.asyncSinks = append(b.asyncSinks, &asyncSink{name: name, sink: sink, queue: make(chan Event, buffer)})
+}
+
+// Publish — передать событие всем получателям
+func (b *Bus) Publish(ctx context.Context, e Event) error {
+	var errs []error
+	for _, s := range b.syncSinks {
+		if err := s.sink.HandleEvent(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+
+	for _, s := range b.asyncSinks {
+		select {
+		case s.queue <- e:
+		default:
+			metrics.EventsDropped.WithLabelValues(s.name).Inc()
+			slog.Warn("Очередь получателя событий заполнена, событие отброшено",
+				"sink", s.name, "kind", e.Kind, logging.Warehouse(e.Coefficient.WarehouseID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run — обрабатывать очереди получателей до отмены ctx
+func (b *Bus) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, s := range b.asyncSinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.run(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// run — обработка очереди одного получателя
+func (s *asyncSink) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.queue:
+			if err := s.sink.HandleEvent(ctx, e); err != nil {
+				slog.Error("Ошибка обработки события", "sink", s.name, "kind", e.Kind,
+					logging.Warehouse(e.Coefficient.WarehouseID), logging.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"time"
+
+	"postavkinBot/internal/wb"
+)
+
+// supply — склад и тип поставки
+type supply struct {
+	warehouseID int
+	boxTypeID   int
+}
+
+// Detector — поиск изменений между ответами WB. Состояние склада по типу поставки — первый
+// коэффициент этого типа в ответе. Не потокобезопасен: вызывается из одного цикла проверки.
+type Detector struct {
+	previous map[supply]wb.Coefficient // nil — ответов ещё не было
+	first    map[int]supply            // Склад -> первый тип поставки в прошлом ответе
+}
+
+// NewDetector — создать детектор
+func NewDetector() *Detector {
+	return &Detector{}
+}
+
+// Detect — события по складам относительно прошлого ответа. Первый ответ только запоминается:
+// после запуска бота не известно, что было до него.
+//
+// Вид события считается по порогу по умолчанию (Open), но событие есть при любом изменении
+// коэффициента или выгрузки: получатели с другим порогом пересчитывают вид через OpenFor.
+func (d *Detector) Detect(coefficients []wb.Coefficient, now time.Time) []Event {
+	current := make(map[supply]wb.Coefficient, len(coefficients))
+	order := make([]supply, 0, len(coefficients))
+	first := make(map[int]supply) // Склад -> первый тип поставки в ответе
+	for _, c := range coefficients {
+		key := supply{c.WarehouseID, c.BoxTypeID}
+		if _, ok := current[key]; !ok {
+			current[key] = c
+			order = append(order, key)
+		}
+		if _, ok := first[c.WarehouseID]; !ok {
+			first[c.WarehouseID] = key
+		}
+	}
+
+	previous, previousFirst := d.previous, d.first
+	d.previous, d.first = current, first
+	if previous == nil {
+		return nil
+	}
+	known := make(map[int]bool) // Склады из прошлого ответа
+	for key := range previous {
+		known[key.warehouseID] = true
+	}
+
+	var events []Event
+	for _, key := range order {
+		c := current[key]
+		isFirst := first[key.warehouseID] == key
+		prev, existed := previous[key]
+		if !existed {
+			if !known[key.warehouseID] && isFirst {
+				events = append(events, Event{Kind: WarehouseAdded, Coefficient: c, First: true, At: now})
+			}
+			switch {
+			case Open(c):
+				events = append(events, Event{Kind: SlotOpened, Coefficient: c, First: isFirst, At: now})
+			case c.AllowUnload && c.Coefficient >= 0:
+				// Закрыто при пороге по умолчанию, но может быть открыто при более высоком
+				events = append(events, Event{Kind: CoefficientChanged, Coefficient: c, First: isFirst, At: now})
+			}
+			continue
+		}
+
+		p := prev
+		e := Event{Coefficient: c, Previous: &p, First: isFirst, At: now}
+		switch wasOpen, open := Open(prev), Open(c); {
+		case !wasOpen && open:
+			e.Kind = SlotOpened
+		case wasOpen && !open:
+			e.Kind = SlotClosed
+		case prev.Coefficient != c.Coefficient || prev.AllowUnload != c.AllowUnload:
+			e.Kind = CoefficientChanged
+		default:
+			continue
+		}
+		events = append(events, e)
+	}
+
+	// Пропавший из ответа тип поставки считается закрытым, если приёмка была открыта хоть при каком-то пороге
+	for key, prev := range previous {
+		if _, ok := current[key]; ok || !prev.AllowUnload || prev.Coefficient < 0 {
+			continue
+		}
+		p := prev
+		events = append(events, Event{Kind: SlotClosed, Coefficient: prev, Previous: &p, First: previousFirst[key.warehouseID] == key, Removed: true, At: now})
+	}
+	return events
+}
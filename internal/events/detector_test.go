@@ -0,0 +1,163 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"postavkinBot/internal/wb"
+)
+
+// coef — коэффициент склада по типу поставки
+func coef(warehouseID, boxTypeID, coefficient int, allowUnload bool) wb.Coefficient {
+	return wb.Coefficient{WarehouseID: warehouseID, BoxTypeID: boxTypeID, Coefficient: coefficient, AllowUnload: allowUnload}
+}
+
+// summary — событие в виде строки для сравнения: вид, склад/тип поставки и признаки
+func summary(e Event) string {
+	s := fmt.Sprintf("%s %d/%d", e.Kind, e.Coefficient.WarehouseID, e.Coefficient.BoxTypeID)
+	if e.First {
+		s += " first"
+	}
+	if e.Removed {
+		s += " removed"
+	}
+	if e.Previous == nil {
+		s += " new"
+	}
+	return s
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshots [][]wb.Coefficient // Ответы WB по порядку, события проверяются по последнему
+		want      []string
+	}{
+		{
+			name:      "первый ответ только запоминается",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 0, true), coef(507, 5, 3, true)}},
+		},
+		{
+			name:      "без изменений",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 3, true)}, {coef(507, 2, 3, true)}},
+		},
+		{
+			name:      "приёмка открылась",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true)}, {coef(507, 2, 0, true)}},
+			want:      []string{"slot_opened 507/2 first"},
+		},
+		{
+			name:      "выгрузку разрешили",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 0, false)}, {coef(507, 2, 0, true)}},
+			want:      []string{"slot_opened 507/2 first"},
+		},
+		{
+			name:      "приёмка закрылась",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 1, true)}, {coef(507, 2, -1, true)}},
+			want:      []string{"slot_closed 507/2 first"},
+		},
+		{
+			name:      "коэффициент вырос выше порога",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 1, true)}, {coef(507, 2, 2, true)}},
+			want:      []string{"slot_closed 507/2 first"},
+		},
+		{
+			name:      "коэффициент изменился при закрытой приёмке",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 3, true)}, {coef(507, 2, 5, true)}},
+			want:      []string{"coefficient_changed 507/2 first"},
+		},
+		{
+			name:      "коэффициент изменился при открытой приёмке",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 0, true)}, {coef(507, 2, 1, true)}},
+			want:      []string{"coefficient_changed 507/2 first"},
+		},
+		{
+			name:      "изменился не первый тип поставки",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true), coef(507, 5, 5, true)}, {coef(507, 2, 5, true), coef(507, 5, 0, true)}},
+			want:      []string{"slot_opened 507/5"},
+		},
+		{
+			name:      "считается только первая дата типа поставки",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true), coef(507, 2, 0, true)}, {coef(507, 2, 5, true), coef(507, 2, 1, true)}},
+		},
+		{
+			name:      "новый склад с открытой приёмкой",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true)}, {coef(507, 2, 5, true), coef(117986, 2, 0, true)}},
+			want:      []string{"warehouse_added 117986/2 first new", "slot_opened 117986/2 first new"},
+		},
+		{
+			name:      "новый склад с приёмкой выше порога",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true)}, {coef(507, 2, 5, true), coef(117986, 2, 4, true)}},
+			want:      []string{"warehouse_added 117986/2 first new", "coefficient_changed 117986/2 first new"},
+		},
+		{
+			name:      "новый склад без приёмки",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true)}, {coef(507, 2, 5, true), coef(117986, 2, -1, false)}},
+			want:      []string{"warehouse_added 117986/2 first new"},
+		},
+		{
+			name:      "новый тип поставки известного склада",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 5, true)}, {coef(507, 2, 5, true), coef(507, 6, 0, true)}},
+			want:      []string{"slot_opened 507/6 new"},
+		},
+		{
+			name:      "пропал склад с открытой приёмкой",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 0, true), coef(117986, 2, 5, true)}, {coef(117986, 2, 5, true)}},
+			want:      []string{"slot_closed 507/2 first removed"},
+		},
+		{
+			name:      "пропал склад с приёмкой выше порога",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, 0, true), coef(507, 5, 7, true)}, {}},
+			want:      []string{"slot_closed 507/2 first removed", "slot_closed 507/5 removed"},
+		},
+		{
+			name:      "пропал склад без приёмки",
+			snapshots: [][]wb.Coefficient{{coef(507, 2, -1, true), coef(507, 5, 0, false)}, {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector()
+			now := time.Now()
+			var events []Event
+			for _, snapshot := range tt.snapshots {
+				events = d.Detect(snapshot, now)
+			}
+
+			got := make([]string, len(events))
+			for i, e := range events {
+				got[i] = summary(e)
+				if !e.At.Equal(now) {
+					t.Errorf("%s: время %v, ожидалось %v", got[i], e.At, now)
+				}
+			}
+			// Пропавшие склады перебираются из map, поэтому их порядок не важен
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("события %q, ожидались %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDetectKeepsLatestSnapshot(t *testing.T) {
+	d := NewDetector()
+	now := time.Now()
+	d.Detect([]wb.Coefficient{coef(507, 2, 5, true)}, now)
+	d.Detect([]wb.Coefficient{coef(507, 2, 0, true)}, now)
+
+	events := d.Detect([]wb.Coefficient{coef(507, 2, 0, true)}, now)
+	if len(events) != 0 {
+		t.Errorf("повторный ответ дал события: %v", events)
+	}
+
+	events = d.Detect([]wb.Coefficient{coef(507, 2, 3, true)}, now)
+	if len(events) != 1 || events[0].Kind != SlotClosed || events[0].Previous == nil || events[0].Previous.Coefficient != 0 {
+		t.Errorf("события %+v, ожидалось закрытие после коэффициента 0", events)
+	}
+}
@@ -0,0 +1,52 @@
+// Package events — шина событий о приёмке на складах WB. Планировщик находит изменения
+// коэффициентов и публикует события, а получатели (Telegram, история, метрики, вебхуки)
+// обрабатывают их независимо друг от друга.
+package events
+
+import (
+	"time"
+
+	"postavkinBot/internal/wb"
+)
+
+// Kind — вид события
+type Kind string
+
+const (
+	SlotOpened         Kind = "slot_opened"         // Приёмка открылась
+	CoefficientChanged Kind = "coefficient_changed" // Коэффициент изменился, приёмка не открылась и не закрылась
+	SlotClosed         Kind = "slot_closed"         // Приёмка закрылась или склад пропал из ответа WB
+	WarehouseAdded     Kind = "warehouse_added"     // Склад впервые появился в ответе WB
+)
+
+// Recipient — получатель уведомления о складе из его подписок
+type Recipient struct {
+	ChatID    int64
+	TeamID    uint   // Команда, чей общий список сработал, 0 — подписка самого чата
+	Lang      string // Язык уведомления
+	Template  string // Шаблон уведомления, пусто — стандартный текст
+	ParseMode string
+}
+
+// Event — событие о складе. Без Recipient — изменение склада для всех (история, метрики, вебхуки),
+// с Recipient — уведомление конкретному подписчику (Telegram).
+type Event struct {
+	Kind        Kind
+	Coefficient wb.Coefficient  // Текущий коэффициент, для SlotClosed — последний известный
+	Previous    *wb.Coefficient // Предыдущий коэффициент, nil — склада раньше не было
+	Recipient   *Recipient
+	Repeat      bool // Напоминание об уже открытой приёмке после scheduler.repeat_notify_delay
+	First       bool // Тип поставки первый у склада в ответе WB — его отслеживают подписки без типа поставки
+	Removed     bool // Тип поставки пропал из ответа WB, Coefficient — последний известный
+	At          time.Time
+}
+
+// Open — открыта ли приёмка при пороге по умолчанию: бесплатно или с коэффициентом 1 и выгрузка разрешена
+func Open(c wb.Coefficient) bool {
+	return OpenFor(c, 1)
+}
+
+// OpenFor — открыта ли приёмка для подписки с порогом maxCoefficient
+func OpenFor(c wb.Coefficient, maxCoefficient int) bool {
+	return c.Coefficient >= 0 && c.Coefficient <= maxCoefficient && c.AllowUnload
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+
+	"postavkinBot/internal/metrics"
+	"postavkinBot/internal/storage"
+)
+
+// MetricsSink — счётчик событий по складам по видам
+func MetricsSink() Sink {
+	return SinkFunc(func(ctx context.Context, e Event) error {
+		if e.Recipient == nil {
+			metrics.SlotEvents.WithLabelValues(string(e.Kind)).Inc()
+		}
+		return nil
+	})
+}
+
+// HistoryStore — хранилище истории событий
+type HistoryStore interface {
+	RecordSlotEvent(e *storage.SlotEvent) error
+}
+
+// HistorySink — запись в историю изменений коэффициентов и отправленных подписчикам уведомлений
+func HistorySink(store HistoryStore) Sink {
+	return SinkFunc(func(ctx context.Context, e Event) error {
+		if e.Recipient != nil && e.Kind != SlotOpened {
+			return nil
+		}
+
+		record := &storage.SlotEvent{
+			Kind:          string(e.Kind),
+			WarehouseID:   e.Coefficient.WarehouseID,
+			WarehouseName: e.Coefficient.WarehouseName,
+			BoxTypeID:     e.Coefficient.BoxTypeID,
+			Date:          e.Coefficient.Date,
+			Coefficient:   e.Coefficient.Coefficient,
+			AllowUnload:   e.Coefficient.AllowUnload,
+			CreatedAt:     e.At,
+		}
+		if e.Previous != nil {
+			previous := e.Previous.Coefficient
+			record.Previous = &previous
+		}
+		if e.Recipient != nil {
+			record.ChatID, record.TeamID = e.Recipient.ChatID, e.Recipient.TeamID
+		}
+		return store.RecordSlotEvent(record)
+	})
+}
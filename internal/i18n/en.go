@@ -0,0 +1,249 @@
+package i18n
+
+var en = map[string]string{
+	// Общие ошибки
+	"error.internal":            "An internal error occurred. Please try again later.",
+	"error.rate_limited":        "⏳ Too many requests, please wait a moment.",
+	"error.private_only":        "This command is only available in a private chat with the bot.",
+	"error.users_only":          "This command is only available to users. Send /start to the bot in a private chat.",
+	"error.not_chat_admin":      "⛔ Only administrators can change warehouses in this chat.",
+	"error.invalid_warehouse":   "Error: please enter a warehouse ID or name.",
+	"error.catalog":             "Failed to load the warehouse list. Please try again later.",
+	"error.unknown_command":     "Unknown command. Send /help to see the available commands.",
+	"warehouse.unknown":         "Unknown warehouse",
+	"warehouse.unknown_with_id": "Unknown warehouse (ID: %d)",
+
+	// Старт и помощь
+	"start.group":        "The bot is connected to this chat! 🎉 Limit alerts will be posted here. Administrators can manage warehouses.",
+	"start.welcome":      "You are registered! 🎉 Welcome!",
+	"start.welcome_back": "Welcome back! 👋",
+	"help": "📋 Available commands:\n" +
+		"/start - Get started\n" +
+		"/help - Help\n" +
+		"/warehouses - List all warehouses\n" +
+		"/addwarehouse - Start tracking a warehouse\n" +
+		"/mywarehouses - Show my warehouses\n" +
+		"/status - Coefficients of my warehouses for 14 days\n" +
+		"/removewarehouse - Stop tracking a warehouse\n" +
+		"/setinterval - Set the limit check interval\n" +
+		"/language - Bot language\n\n" +
+		"👥 Teams:\n" +
+		"/newteam - Create a team\n" +
+		"/team - My team and shared warehouses\n" +
+		"/invite - Invite link (editor or viewer)\n" +
+		"/teamadd - Add a warehouse to the shared list\n" +
+		"/teamremove - Remove a warehouse from the shared list\n" +
+		"/setrole - Change a member's role\n" +
+		"/kick - Remove a member\n" +
+		"/leaveteam - Leave the team\n\n" +
+		"🔕 Notifications:\n" +
+		"/quiet - Quiet hours, e.g. /quiet 23-8\n" +
+		"/mute - Mute notifications for N hours\n" +
+		"/unmute - Unmute notifications\n" +
+		"/template - Notification template\n" +
+		"/apikey - Your own WB API key\n" +
+		"/webhook - Webhooks for warehouse events\n" +
+		"/apitoken - REST API tokens",
+
+	// Склады
+	"warehouses.error":    "Failed to load warehouses. Please try again later.",
+	"warehouses.header":   "📦 Available warehouses:\n",
+	"add.prompt":          "Enter the ID or name of the warehouse you want to track. Separate several with commas, e.g.: Koledino, Podolsk, 507",
+	"add.error":           "Failed to add the warehouse.",
+	"add.failed":          "❌ Failed to add warehouse %s (ID: %d), please try again later.",
+	"add.done":            "✅ Warehouse %s (ID: %d) added!",
+	"add.not_found":       "❓ Warehouse “%s” not found.",
+	"add.search_hint":     "Check the name or find the warehouse in the list: /warehouses",
+	"add.choose":          "🔎 Several warehouses match “%s”, choose one:",
+	"add.choose_more":     "Not all matches are shown — refine the query if yours is missing.",
+	"my.error":            "Failed to load your warehouses.",
+	"my.empty":            "You are not tracking any warehouses yet. Add one with /addwarehouse.",
+	"my.header.one":       "📦 You are tracking %d warehouse:\n",
+	"my.header.other":     "📦 You are tracking %d warehouses:\n",
+	"status.unavailable":  "WB coefficients are not available yet. Try again in a couple of minutes.",
+	"status.header":       "📊 Acceptance from %s to %s:",
+	"status.no_data":      "no WB data",
+	"status.legend":       "🟢 free  🟡 ×1–×%d  🔴 above ×%d  ⚫ closed",
+	"status.age_now":      "🕒 Data fetched less than a minute ago.",
+	"status.age.one":      "🕒 Data fetched %d minute ago.",
+	"status.age.other":    "🕒 Data fetched %d minutes ago.",
+	"remove.prompt":       "Enter the ID of the warehouse you want to stop tracking:",
+	"remove.error":        "Failed to remove the warehouse.",
+	"remove.done":         "✅ Warehouse %d is no longer tracked!",
+	"interval.prompt":     "Enter the check interval in minutes (e.g. 5, 10, 15):",
+	"interval.invalid":    "Error: please enter a positive number.",
+	"interval.error":      "Failed to save the interval.",
+	"interval.done.one":   "✅ Interval updated! Limits will now be checked every %d minute.",
+	"interval.done.other": "✅ Interval updated! Limits will now be checked every %d minutes.",
+
+	// Уведомления о лимитах
+	"alert.slot": "📦 Limit at warehouse: %s (ID: %d)\n📈 Coefficient: %d\n🗓 Date: %s",
+
+	// Командная работа
+	"role.owner":             "owner",
+	"role.editor":            "editor",
+	"role.viewer":            "viewer",
+	"team.name_required":     "Please provide a team name: /newteam <name>",
+	"team.already_member":    "You are already in a team. Leave it first with /leaveteam.",
+	"team.create_error":      "Failed to create the team.",
+	"team.created":           "✅ Team “%s” created! Invite colleagues with /invite editor or /invite viewer.",
+	"team.fetch_error":       "Failed to load the team.",
+	"team.header":            "👥 Team “%s”\nYour role: %s\n\n",
+	"team.members.one":       "%d member:\n",
+	"team.members.other":     "%d members:\n",
+	"team.warehouses":        "\n📦 Shared warehouses:\n",
+	"team.no_warehouses":     "none yet, add one with /teamadd\n",
+	"team.invite_owner_only": "⛔ Only the owner can invite people to the team.",
+	"team.invite_bad_role":   "The role must be editor or viewer: /invite editor",
+	"team.invite_error":      "Failed to create the invite.",
+	"team.invite_link":       "🔗 Invite link to team “%s” (role: %s), valid for 7 days:\n%s",
+	"team.invite_expired":    "The invite is invalid or has expired. Ask the team owner for a new one.",
+	"team.join_error":        "Failed to join the team.",
+	"team.joined":            "✅ You joined team “%s” (role: %s). Details: /team",
+	"team.member_joined":     "👋 @%s joined team “%s” (role: %s).",
+	"team.add_prompt":        "Enter the ID or name of the warehouse to add to the team's shared list. Separate several with commas:",
+	"team.add_done":          "✅ Warehouse %s (ID: %d) added to the team's shared list!",
+	"team.remove_prompt":     "Enter the ID of the warehouse to remove from the team's shared list:",
+	"team.remove_done":       "✅ Warehouse %d removed from the team's shared list!",
+	"team.role_owner_only":   "⛔ Only the team owner can change roles.",
+	"team.setrole_usage":     "Usage: /setrole <member ID> <editor|viewer>",
+	"team.member_not_found":  "Member not found in your team.",
+	"team.setrole_error":     "Failed to change the role.",
+	"team.role_changed":      "✅ Member %d is now “%s”.",
+	"team.kick_owner_only":   "⛔ Only the team owner can remove members.",
+	"team.kick_usage":        "Usage: /kick <member ID>",
+	"team.kick_error":        "Failed to remove the member.",
+	"team.kicked":            "✅ Member %d removed from the team.",
+	"team.delete_error":      "Failed to delete the team.",
+	"team.deleted":           "✅ Team “%s” deleted.",
+	"team.leave_error":       "Failed to leave the team.",
+	"team.left":              "✅ You left team “%s”.",
+	"team.not_member":        "You are not in a team. Create one with /newteam <name> or ask for an invite.",
+	"team.editor_only":       "⛔ Only the owner and editors can change the team's warehouses.",
+
+	// Тихие часы и пауза
+	"quiet.usage":  "Usage: /quiet 23-8 (Moscow time) or /quiet off",
+	"quiet.error":  "Failed to save quiet hours.",
+	"quiet.off":    "🔔 Quiet hours are off.",
+	"quiet.on":     "🌙 Quiet hours: from %02d:00 to %02d:00 Moscow time.",
+	"mute.usage":   "Usage: /mute <hours>, e.g. /mute 3",
+	"mute.error":   "Failed to mute notifications.",
+	"mute.on":      "🔕 Notifications are muted until %s Moscow time. Unmute earlier: /unmute",
+	"unmute.error": "Failed to unmute notifications.",
+	"unmute.done":  "🔔 Notifications are on again.",
+
+	// Язык
+	"language.choose": "🌐 Choose the bot language:",
+	"language.auto":   "Same as Telegram",
+	"language.set":    "✅ Bot language: %s",
+	"language.reset":  "✅ The bot language will follow your Telegram settings.",
+	"language.error":  "Failed to save the language.",
+
+	// Шаблоны уведомлений
+	"template.scope.personal": "personal",
+	"template.scope.team":     "team “%s”",
+	"template.default":        "default text",
+	"template.format.text":    "plain text",
+	"template.show":           "🧩 Notification template (%s), markup: %s\n\n%s\n\nFields: {{.WarehouseName}}, {{.WarehouseID}}, {{.Date}}, {{.Coefficient}}, {{.BoxType}}, {{.BoxTypeID}}, {{.AllowUnload}}, {{.SortingCenter}}, {{.Link}}\n\n/template preview — sample notification\n/template set html|markdown|text — set the template\n/template reset — back to the default text\nFor the team template: /template team …",
+	"template.usage":          "Usage: /template [team] [preview|set html|markdown|text|reset]",
+	"template.bad_format":     "Markup must be html, markdown or text: /template set html",
+	"template.prompt":         "Send the template text, e.g.:\n📦 {{.WarehouseName}}: coefficient {{.Coefficient}} on {{.Date}}",
+	"template.invalid":        "❌ Template error: %v",
+	"template.error":          "Failed to save the template.",
+	"template.saved":          "✅ Template saved. This is how a notification will look:",
+	"template.reset":          "✅ Notifications use the default text again.",
+	"template.preview_error":  "❌ Telegram rejected the rendered message: %v",
+
+	// Ключи WB API
+	"apikey.disabled":    "Connecting your own WB API keys is disabled by the administrator.",
+	"apikey.status_none": "🔑 Alerts are checked with the bot's shared key.\n\nTo make WB requests count against your own quota, create a token with access to the \"Supplies\" category in your WB seller account and send:\n/apikey <token>\nThe bot will delete the message with the token right away.",
+	"apikey.status_set":  "🔑 Your WB API key is connected (%s).\nReplace: /apikey <token>\nRemove: /apikey delete",
+	"apikey.invalid":     "❌ WB rejected the key: make sure it is valid and has access to \"Supplies\".",
+	"apikey.check_error": "Failed to verify the key with WB. Please try again later.",
+	"apikey.error":       "Failed to save the key.",
+	"apikey.saved":       "✅ WB API key (%s) verified and stored encrypted. Coefficients for your alerts are now requested with it.",
+	"apikey.removed":     "✅ Key removed, alerts are checked with the bot's shared key.",
+	"apikey.malformed":   "❌ This does not look like a WB API token. Copy the whole token from your WB seller account.",
+	"apikey.no_scope":    "❌ The token has no access to the \"Supplies\" category, so the bot cannot get acceptance coefficients. Create a token with this category.",
+	"apikey.expired":     "❌ The token expired on %s. Create a new token in your WB seller account.",
+	"apikey.expires":     "Valid until %s (MSK).",
+	"apikey.expiring":    "⚠️ The token expires on %s (MSK) — create a new one in advance and send it with /apikey.",
+
+	// Сроки ключей WB
+	"keys.shared_expiring": "⚠️ The bot's shared WB API key expires on %s (MSK). Replace WB_API_KEY or the bot will stop getting coefficients.",
+	"keys.shared_expired":  "⛔ The bot's shared WB API key expired on %s (MSK). The bot is not getting coefficients — replace WB_API_KEY.",
+	"keys.user_expiring":   "⚠️ Your WB API key expires on %s (MSK). Create a new token and send it with /apikey, otherwise alerts will be checked with the bot's shared key.",
+	"keys.user_expired":    "⛔ Your WB API key expired on %s (MSK); alerts are checked with the bot's shared key. Send a new token with /apikey.",
+
+	// Исходящие вебхуки
+	"webhook.disabled":      "Webhooks are disabled by the administrator.",
+	"webhook.usage":         "Webhooks receive a JSON POST for every acceptance opening, closing and coefficient change at your warehouses.\n\n/webhook add <https://…> — personal webhook for your warehouses\n/webhook team <https://…> — webhook for your team's warehouses\n/webhook log <ID> — recent deliveries\n/webhook enable <ID> — enable again\n/webhook delete <ID> — delete",
+	"webhook.error":         "Webhook error. Please try again later.",
+	"webhook.empty":         "🔗 No webhooks yet.",
+	"webhook.header":        "🔗 Your webhooks:",
+	"webhook.team_mark":     "(team)",
+	"webhook.last_error":    "disabled: %s",
+	"webhook.invalid_url":   "❌ The address must start with https:// and contain a host.",
+	"webhook.private_url":   "❌ Local network addresses are not supported.",
+	"webhook.limit.one":     "You can add at most %d webhook.",
+	"webhook.limit.other":   "You can add at most %d webhooks.",
+	"webhook.added":         "✅ Webhook #%d added.\n\nSigning secret (shown only once):\n%s\n\nHeader X-Webhook-Signature = sha256=HMAC-SHA256(secret, X-Webhook-Timestamp + \".\" + request body).",
+	"webhook.deleted":       "✅ Webhook #%d deleted.",
+	"webhook.enabled":       "✅ Webhook #%d enabled.",
+	"webhook.not_found":     "Webhook #%d not found.",
+	"webhook.log_empty":     "No deliveries to webhook #%d yet.",
+	"webhook.log_header":    "📜 Recent deliveries #%d (%s):",
+	"webhook.log_delivered": "✅ %d",
+	"webhook.log_dead":      "❌ %s",
+	"webhook.log_pending":   "⏳ attempts: %d",
+	"webhook.auto_disabled": "⛔ Webhook #%d (%s) was disabled after repeated failed deliveries. Last error: %s\n\nFix the endpoint and enable it: /webhook enable %[1]d",
+
+	// Токены REST API
+	"apitoken.disabled":    "The REST API is disabled by the administrator.",
+	"apitoken.usage":       "Pass the token in the Authorization: Bearer <token> header.\n\n/apitoken new [name] — issue a token\n/apitoken revoke <ID> — revoke",
+	"apitoken.error":       "Token error. Please try again later.",
+	"apitoken.empty":       "🔐 No API tokens yet.",
+	"apitoken.header":      "🔐 Your API tokens:",
+	"apitoken.item":        "issued %s, last used: %s",
+	"apitoken.never":       "never",
+	"apitoken.limit.one":   "You can issue at most %d token. Revoke one you no longer need: /apitoken revoke <ID>",
+	"apitoken.limit.other": "You can issue at most %d tokens. Revoke one you no longer need: /apitoken revoke <ID>",
+	"apitoken.created":     "✅ Token #%d issued. Save it now — it will not be shown again:\n\n%s",
+	"apitoken.revoked":     "✅ Token #%d revoked.",
+	"apitoken.not_found":   "Token #%d not found.",
+
+	// Изменения подписок через REST API
+	"api.subscription_added":   "🔌 Warehouse %s (ID: %d) was added to tracking via the REST API.",
+	"api.subscription_updated": "🔌 Conditions for warehouse %s (ID: %d) were changed via the REST API.",
+	"api.subscription_removed": "🔌 Warehouse %s (ID: %d) was removed from tracking via the REST API.",
+	"api.subscription_filter":  "Alerts at a coefficient up to %d, supply type: %s.",
+	"api.box_type_any":         "any",
+
+	// Администрирование
+	"admin.stats":               "📊 Statistics\n\n👤 Users: %d (blocked the bot: %d, banned: %d)\n📦 Subscriptions: %d\n🔔 Alerts today: %d delivered, %d failed\n🌐 WB API: %d requests, %d errors (%.1f%%)\n⏱ Check interval: %s\n\n🏆 Most tracked warehouses:\n",
+	"admin.stats_error":         "Failed to collect statistics.",
+	"admin.top_empty":           "no subscriptions yet\n",
+	"admin.broadcast_usage":     "Usage: /broadcast <text>",
+	"admin.broadcast_preview":   "📣 This is how the broadcast will look. Recipients: %d.",
+	"admin.broadcast_send":      "✅ Send",
+	"admin.broadcast_cancel":    "✖️ Cancel",
+	"admin.broadcast_error":     "Failed to prepare the broadcast.",
+	"admin.broadcast_queued":    "✅ Broadcast queued for %d recipients.",
+	"admin.broadcast_cancelled": "Broadcast cancelled.",
+	"admin.broadcast_expired":   "Broadcast draft not found: it has already been sent or cancelled.",
+	"admin.user_usage":          "Usage: /user <Telegram ID>",
+	"admin.user_not_found":      "User %d not found.",
+	"admin.user_error":          "Failed to load the user.",
+	"admin.user_info":           "👤 %s (ID: %d)\nLanguage: %s\nLast seen: %s\nBlocked the bot: %s\nBanned: %s\nTeam: %s\n\n📦 Warehouses:\n",
+	"admin.yes":                 "yes",
+	"admin.no":                  "no",
+	"admin.ban_usage":           "Usage: /%s <Telegram ID>",
+	"admin.ban_admin":           "Administrators cannot be banned.",
+	"admin.ban_error":           "Failed to change the ban.",
+	"admin.banned":              "⛔ User %d is banned: the bot will not reply or send alerts to them.",
+	"admin.unbanned":            "✅ User %d is unbanned.",
+	"admin.interval_current":    "⏱ Coefficient check interval: %s.\nChange for all instances: /pollinterval <seconds> (%d to %d)",
+	"admin.interval_invalid":    "The interval must be a number of seconds from %d to %d.",
+	"admin.interval_set":        "✅ Coefficient check interval: %s. The leader instance applies it from the next check.",
+	"admin.interval_error":      "Failed to save the interval.",
+}
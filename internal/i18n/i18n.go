@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default — язык по умолчанию и запасной язык для отсутствующих переводов
+const Default = "ru"
+
+// catalogues — тексты бота по языкам. Ключи с формами множественного числа
+// хранятся с суффиксами .one, .few, .many и .other.
+var catalogues = map[string]map[string]string{
+	"ru": ru,
+	"en": en,
+	"kk": kk,
+	"uz": uz,
+}
+
+// names — названия языков на них самих
+var names = map[string]string{
+	"ru": "Русский",
+	"en": "English",
+	"kk": "Қазақша",
+	"uz": "Oʻzbekcha",
+}
+
+// Supported — поддерживаемые языки в порядке показа
+var Supported = []string{"ru", "en", "kk", "uz"}
+
+// Resolve — первый поддерживаемый язык из переданных кодов (override, language_code Telegram и т.д.).
+// Коды вида "en-US" сводятся к "en". Если ничего не подошло — Default.
+func Resolve(codes ...string) string {
+	for _, code := range codes {
+		code = strings.ToLower(code)
+		if i := strings.IndexAny(code, "-_"); i != -1 {
+			code = code[:i]
+		}
+		if _, ok := catalogues[code]; ok {
+			return code
+		}
+	}
+	return Default
+}
+
+// IsSupported — есть ли каталог для языка
+func IsSupported(lang string) bool {
+	_, ok := catalogues[lang]
+	return ok
+}
+
+// Name — название языка на нём самом
+func Name(lang string) string {
+	return names[lang]
+}
+
+// T — перевод ключа с подстановкой аргументов через fmt.Sprintf
+func T(lang, key string, args ...interface{}) string {
+	text := lookup(lang, key)
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N — перевод ключа с выбором формы множественного числа по n.
+// Без дополнительных аргументов в строку подставляется n.
+func N(lang, key string, n int, args ...interface{}) string {
+	lang = Resolve(lang)
+	text := lookup(lang, key+"."+pluralForm(lang, n))
+	if len(args) == 0 {
+		args = []interface{}{n}
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// lookup — текст по ключу с откатом на язык по умолчанию, а затем на сам ключ
+func lookup(lang, key string) string {
+	if text, ok := catalogues[lang][key]; ok {
+		return text
+	}
+	if text, ok := catalogues[Default][key]; ok {
+		return text
+	}
+	// Для форм множественного числа в языках без few/many
+	if i := strings.LastIndex(key, "."); i != -1 {
+		if text, ok := catalogues[lang][key[:i]+".other"]; ok {
+			return text
+		}
+	}
+	return key
+}
+
+// pluralForm — форма множественного числа по правилам CLDR
+func pluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case "ru":
+		mod10, mod100 := n%10, n%100
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		// en, kk и uz различают только единственное и множественное число
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
@@ -0,0 +1,123 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPluralForm(t *testing.T) {
+	tests := []struct {
+		lang  string
+		forms map[int]string // Число -> форма
+	}{
+		{"ru", map[int]string{0: "many", 1: "one", 2: "few", 4: "few", 5: "many", 11: "many", 12: "many", 14: "many", 21: "one", 22: "few", 25: "many", 101: "one", 111: "many", 112: "many", -1: "one", -2: "few"}},
+		{"en", map[int]string{0: "other", 1: "one", 2: "other", 5: "other", 11: "other", 21: "other", -1: "one"}},
+		{"kk", map[int]string{0: "other", 1: "one", 2: "other", 5: "other", 11: "other", 21: "other"}},
+		{"uz", map[int]string{0: "other", 1: "one", 2: "other", 5: "other", 11: "other", 21: "other"}},
+	}
+	for _, tt := range tests {
+		for n, want := range tt.forms {
+			if got := pluralForm(tt.lang, n); got != want {
+				t.Errorf("pluralForm(%q, %d) = %q, ожидалось %q", tt.lang, n, got, want)
+			}
+		}
+	}
+}
+
+func TestN(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"ru", 1, "📦 Вы отслеживаете 1 склад:\n"},
+		{"ru", 2, "📦 Вы отслеживаете 2 склада:\n"},
+		{"ru", 5, "📦 Вы отслеживаете 5 складов:\n"},
+		{"ru", 11, "📦 Вы отслеживаете 11 складов:\n"},
+		{"ru", 21, "📦 Вы отслеживаете 21 склад:\n"},
+		{"en", 1, "📦 You are tracking 1 warehouse:\n"},
+		{"en", 2, "📦 You are tracking 2 warehouses:\n"},
+		{"en", 5, "📦 You are tracking 5 warehouses:\n"},
+		{"en", 11, "📦 You are tracking 11 warehouses:\n"},
+		{"en", 21, "📦 You are tracking 21 warehouses:\n"},
+		{"en-US", 21, "📦 You are tracking 21 warehouses:\n"},
+	}
+	for _, tt := range tests {
+		if got := N(tt.lang, "my.header", tt.n); got != tt.want {
+			t.Errorf("N(%q, my.header, %d) = %q, ожидалось %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+
+	// В kk и uz у форм одна запись на число, важно, что подставляется число и не теряется перевод
+	for _, lang := range []string{"kk", "uz"} {
+		for _, n := range []int{1, 2, 5, 11, 21} {
+			got := N(lang, "my.header", n)
+			if !strings.Contains(got, fmt.Sprint(n)) || strings.HasPrefix(got, "my.header") || got == N("ru", "my.header", n) {
+				t.Errorf("N(%q, my.header, %d) = %q", lang, n, got)
+			}
+		}
+	}
+}
+
+func TestPluralKeysComplete(t *testing.T) {
+	forms := map[string][]string{
+		"ru": {"one", "few", "many"},
+		"en": {"one", "other"},
+		"kk": {"one", "other"},
+		"uz": {"one", "other"},
+	}
+
+	// Ключи с формами множественного числа — по русскому каталогу
+	var keys []string
+	for key := range ru {
+		if base, ok := strings.CutSuffix(key, ".one"); ok {
+			keys = append(keys, base)
+		}
+	}
+	if len(keys) == 0 {
+		t.Fatal("в русском каталоге нет ключей с формами множественного числа")
+	}
+
+	for lang, need := range forms {
+		for _, key := range keys {
+			for _, form := range need {
+				if _, ok := catalogues[lang][key+"."+form]; !ok {
+					t.Errorf("%s: нет формы %s.%s", lang, key, form)
+				}
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		codes []string
+		want  string
+	}{
+		{nil, Default},
+		{[]string{"en"}, "en"},
+		{[]string{"EN-us"}, "en"},
+		{[]string{"uz_Latn"}, "uz"},
+		{[]string{"", "kk"}, "kk"},
+		{[]string{"de", "fr"}, Default},
+		{[]string{"uz", "en"}, "uz"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.codes...); got != tt.want {
+			t.Errorf("Resolve(%q) = %q, ожидалось %q", tt.codes, got, tt.want)
+		}
+	}
+}
+
+func TestTFallback(t *testing.T) {
+	if got := T("de", "help"); got != T(Default, "help") {
+		t.Errorf("неподдерживаемый язык должен получать текст на %s", Default)
+	}
+	if got := T("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("отсутствующий ключ: %q", got)
+	}
+	if got := T("en", "remove.done", 507); !strings.Contains(got, "507") {
+		t.Errorf("аргументы не подставлены: %q", got)
+	}
+}
@@ -0,0 +1,249 @@
+package i18n
+
+var kk = map[string]string{
+	// Общие ошибки
+	"error.internal":            "Ішкі қате орын алды. Кейінірек қайталап көріңіз.",
+	"error.rate_limited":        "⏳ Сұраулар тым көп, сәл күте тұрыңыз.",
+	"error.private_only":        "Бұл команда тек ботпен жеке чатта қолжетімді.",
+	"error.users_only":          "Бұл команда тек пайдаланушыларға қолжетімді. Ботқа жеке чатта /start деп жазыңыз.",
+	"error.not_chat_admin":      "⛔ Бұл чатта қоймаларды тек әкімшілер өзгерте алады.",
+	"error.invalid_warehouse":   "Қате: қойманың ID-ін немесе атауын енгізіңіз.",
+	"error.catalog":             "Қоймалар тізімін алу кезінде қате шықты. Кейінірек қайталап көріңіз.",
+	"error.unknown_command":     "Белгісіз команда. Қолжетімді командалар тізімі үшін /help енгізіңіз.",
+	"warehouse.unknown":         "Белгісіз қойма",
+	"warehouse.unknown_with_id": "Белгісіз қойма (ID: %d)",
+
+	// Старт и помощь
+	"start.group":        "Бот чатқа қосылды! 🎉 Лимиттер туралы хабарламалар осында келеді. Қоймаларды әкімшілер басқара алады.",
+	"start.welcome":      "Сіз тіркелдіңіз! 🎉 Қош келдіңіз!",
+	"start.welcome_back": "Қайта оралуыңызбен! 👋",
+	"help": "📋 Қолжетімді командалар:\n" +
+		"/start - Жұмысты бастау\n" +
+		"/help - Анықтама\n" +
+		"/warehouses - Барлық қоймалар тізімі\n" +
+		"/addwarehouse - Қойманы бақылауға қосу\n" +
+		"/mywarehouses - Менің қоймаларым\n" +
+		"/status - Менің қоймаларымның 14 күндік коэффициенттері\n" +
+		"/removewarehouse - Қойманы бақылаудан алып тастау\n" +
+		"/setinterval - Лимиттерді тексеру аралығын орнату\n" +
+		"/language - Бот тілі\n\n" +
+		"👥 Топтар:\n" +
+		"/newteam - Топ құру\n" +
+		"/team - Менің тобым және ортақ қоймалар\n" +
+		"/invite - Шақыру сілтемесі (editor немесе viewer)\n" +
+		"/teamadd - Ортақ тізімге қойма қосу\n" +
+		"/teamremove - Ортақ тізімнен қойманы алып тастау\n" +
+		"/setrole - Қатысушының рөлін өзгерту\n" +
+		"/kick - Қатысушыны шығару\n" +
+		"/leaveteam - Топтан шығу\n\n" +
+		"🔕 Хабарламалар:\n" +
+		"/quiet - Тыныш сағаттар, мысалы /quiet 23-8\n" +
+		"/mute - Хабарламаларды N сағатқа өшіру\n" +
+		"/unmute - Хабарламаларды қосу\n" +
+		"/template - Хабарлама үлгісі\n" +
+		"/apikey - Өз WB API кілтіңіз\n" +
+		"/webhook - Қоймалар оқиғалары туралы вебхуктар\n" +
+		"/apitoken - REST API токендері",
+
+	// Склады
+	"warehouses.error":    "Қоймаларды алу кезінде қате шықты. Кейінірек қайталап көріңіз.",
+	"warehouses.header":   "📦 Қолжетімді қоймалар тізімі:\n",
+	"add.prompt":          "Бақылауға қосқыңыз келетін қойманың ID-ін немесе атауын енгізіңіз. Бірнешеуін үтір арқылы жазуға болады, мысалы: Коледино, Подольск, 507",
+	"add.error":           "Қойманы қосу кезінде қате шықты.",
+	"add.failed":          "❌ %s қоймасын (ID: %d) қосу мүмкін болмады, кейінірек қайталап көріңіз.",
+	"add.done":            "✅ %s қоймасы (ID: %d) сәтті қосылды!",
+	"add.not_found":       "❓ «%s» қоймасы табылмады.",
+	"add.search_hint":     "Атауын тексеріңіз немесе қойманы тізімнен табыңыз: /warehouses",
+	"add.choose":          "🔎 «%s» сұрауына бірнеше қойма сәйкес келеді, керегін таңдаңыз:",
+	"add.choose_more":     "Барлығы көрсетілмеген — керегі болмаса, сұрауды нақтылаңыз.",
+	"my.error":            "Қоймаларыңызды алу кезінде қате шықты.",
+	"my.empty":            "Әзірге бақылауда қоймалар жоқ. Оларды /addwarehouse арқылы қосыңыз.",
+	"my.header.one":       "📦 Сіз %d қойманы бақылап отырсыз:\n",
+	"my.header.other":     "📦 Сіз %d қойманы бақылап отырсыз:\n",
+	"status.unavailable":  "WB коэффициенттері әзірге қолжетімсіз. Бірнеше минуттан кейін қайталаңыз.",
+	"status.header":       "📊 %s – %s аралығындағы қабылдау:",
+	"status.no_data":      "WB деректері жоқ",
+	"status.legend":       "🟢 тегін  🟡 ×1–×%d  🔴 ×%d жоғары  ⚫ жабық",
+	"status.age_now":      "🕒 Деректер бір минуттан аз уақыт бұрын алынды.",
+	"status.age.one":      "🕒 Деректер %d минут бұрын алынды.",
+	"status.age.other":    "🕒 Деректер %d минут бұрын алынды.",
+	"remove.prompt":       "Бақылаудан алып тастағыңыз келетін қойманың ID-ін енгізіңіз:",
+	"remove.error":        "Қойманы алып тастау кезінде қате шықты.",
+	"remove.done":         "✅ ID %d қоймасы бақылаудан алынды!",
+	"interval.prompt":     "Тексеру аралығын минутпен енгізіңіз (мысалы 5, 10, 15):",
+	"interval.invalid":    "Қате: оң сан енгізіңіз.",
+	"interval.error":      "Аралықты сақтау кезінде қате шықты.",
+	"interval.done.one":   "✅ Аралық жаңартылды! Енді лимиттер әр %d минут сайын тексеріледі.",
+	"interval.done.other": "✅ Аралық жаңартылды! Енді лимиттер әр %d минут сайын тексеріледі.",
+
+	// Уведомления о лимитах
+	"alert.slot": "📦 Қоймадағы лимит: %s (ID: %d)\n📈 Коэффициент: %d\n🗓 Күні: %s",
+
+	// Командная работа
+	"role.owner":             "иесі",
+	"role.editor":            "редактор",
+	"role.viewer":            "бақылаушы",
+	"team.name_required":     "Топ атауын көрсетіңіз: /newteam <атауы>",
+	"team.already_member":    "Сіз топтың мүшесісіз. Алдымен /leaveteam арқылы одан шығыңыз.",
+	"team.create_error":      "Топты құру кезінде қате шықты.",
+	"team.created":           "✅ «%s» тобы құрылды! Әріптестеріңізді /invite editor немесе /invite viewer арқылы шақырыңыз.",
+	"team.fetch_error":       "Топты алу кезінде қате шықты.",
+	"team.header":            "👥 «%s» тобы\nСіздің рөліңіз: %s\n\n",
+	"team.members.one":       "%d қатысушы:\n",
+	"team.members.other":     "%d қатысушы:\n",
+	"team.warehouses":        "\n📦 Ортақ қоймалар:\n",
+	"team.no_warehouses":     "әзірге жоқ, /teamadd арқылы қосыңыз\n",
+	"team.invite_owner_only": "⛔ Топқа тек иесі шақыра алады.",
+	"team.invite_bad_role":   "Рөл editor немесе viewer болуы керек: /invite editor",
+	"team.invite_error":      "Шақыру жасау кезінде қате шықты.",
+	"team.invite_link":       "🔗 «%s» тобына шақыру сілтемесі (рөлі: %s), 7 күн жарамды:\n%s",
+	"team.invite_expired":    "Шақыру жарамсыз немесе мерзімі өтіп кеткен. Топ иесінен жаңасын сұраңыз.",
+	"team.join_error":        "Топқа кіру кезінде қате шықты.",
+	"team.joined":            "✅ Сіз «%s» тобына қосылдыңыз (рөлі: %s). Толығырақ: /team",
+	"team.member_joined":     "👋 @%s «%s» тобына қосылды (рөлі: %s).",
+	"team.add_prompt":        "Топтың ортақ тізіміне қосатын қойманың ID-ін немесе атауын енгізіңіз. Бірнешеуін үтір арқылы жазуға болады:",
+	"team.add_done":          "✅ %s қоймасы (ID: %d) топтың ортақ тізіміне қосылды!",
+	"team.remove_prompt":     "Топтың ортақ тізімінен алып тастайтын қойманың ID-ін енгізіңіз:",
+	"team.remove_done":       "✅ ID %d қоймасы топтың ортақ тізімінен алынды!",
+	"team.role_owner_only":   "⛔ Рөлдерді тек топ иесі өзгерте алады.",
+	"team.setrole_usage":     "Қолдану: /setrole <қатысушы ID> <editor|viewer>",
+	"team.member_not_found":  "Қатысушы сіздің топта табылмады.",
+	"team.setrole_error":     "Рөлді өзгерту кезінде қате шықты.",
+	"team.role_changed":      "✅ %d қатысушысының рөлі «%s» болып өзгертілді.",
+	"team.kick_owner_only":   "⛔ Қатысушыларды тек топ иесі шығара алады.",
+	"team.kick_usage":        "Қолдану: /kick <қатысушы ID>",
+	"team.kick_error":        "Қатысушыны шығару кезінде қате шықты.",
+	"team.kicked":            "✅ %d қатысушысы топтан шығарылды.",
+	"team.delete_error":      "Топты жою кезінде қате шықты.",
+	"team.deleted":           "✅ «%s» тобы жойылды.",
+	"team.leave_error":       "Топтан шығу кезінде қате шықты.",
+	"team.left":              "✅ Сіз «%s» тобынан шықтыңыз.",
+	"team.not_member":        "Сіз ешбір топта емессіз. /newteam <атауы> арқылы топ құрыңыз немесе шақыру сұраңыз.",
+	"team.editor_only":       "⛔ Топ қоймаларын тек иесі мен редакторлар өзгерте алады.",
+
+	// Тихие часы и пауза
+	"quiet.usage":  "Қолдану: /quiet 23-8 (Мәскеу уақытымен) немесе /quiet off",
+	"quiet.error":  "Тыныш сағаттарды сақтау кезінде қате шықты.",
+	"quiet.off":    "🔔 Тыныш сағаттар өшірілді.",
+	"quiet.on":     "🌙 Тыныш сағаттар: Мәскеу уақытымен %02d:00-ден %02d:00-ге дейін.",
+	"mute.usage":   "Қолдану: /mute <сағат>, мысалы /mute 3",
+	"mute.error":   "Хабарламаларды өшіру кезінде қате шықты.",
+	"mute.on":      "🔕 Хабарламалар Мәскеу уақытымен %s дейін өшірілді. Ертерек қосу: /unmute",
+	"unmute.error": "Хабарламаларды қосу кезінде қате шықты.",
+	"unmute.done":  "🔔 Хабарламалар қайта қосылды.",
+
+	// Язык
+	"language.choose": "🌐 Бот тілін таңдаңыз:",
+	"language.auto":   "Telegram-дағыдай",
+	"language.set":    "✅ Бот тілі: %s",
+	"language.reset":  "✅ Бот тілі Telegram баптаулары бойынша таңдалады.",
+	"language.error":  "Тілді сақтау кезінде қате шықты.",
+
+	// Шаблоны уведомлений
+	"template.scope.personal": "жеке",
+	"template.scope.team":     "«%s» тобының",
+	"template.default":        "стандартты мәтін",
+	"template.format.text":    "белгілеусіз",
+	"template.show":           "🧩 Хабарлама үлгісі (%s), белгілеу: %s\n\n%s\n\nӨрістер: {{.WarehouseName}}, {{.WarehouseID}}, {{.Date}}, {{.Coefficient}}, {{.BoxType}}, {{.BoxTypeID}}, {{.AllowUnload}}, {{.SortingCenter}}, {{.Link}}\n\n/template preview — хабарлама үлгісі\n/template set html|markdown|text — үлгіні орнату\n/template reset — стандартты мәтінді қайтару\nТоп үлгісі үшін: /template team …",
+	"template.usage":          "Қолдану: /template [team] [preview|set html|markdown|text|reset]",
+	"template.bad_format":     "Белгілеу html, markdown немесе text болуы керек: /template set html",
+	"template.prompt":         "Үлгі мәтінін жіберіңіз, мысалы:\n📦 {{.WarehouseName}}: коэффициент {{.Coefficient}}, күні {{.Date}}",
+	"template.invalid":        "❌ Үлгідегі қате: %v",
+	"template.error":          "Үлгіні сақтау кезінде қате шықты.",
+	"template.saved":          "✅ Үлгі сақталды. Хабарлама осылай көрінеді:",
+	"template.reset":          "✅ Хабарламалар қайтадан стандартты мәтінмен келеді.",
+	"template.preview_error":  "❌ Telegram үлгі бойынша хабарламаны қабылдамады: %v",
+
+	// Ключи WB API
+	"apikey.disabled":    "Өз WB API кілттерін қосуды әкімші өшірген.",
+	"apikey.status_none": "🔑 Хабарламалар боттың ортақ кілтімен тексеріледі.\n\nWB сұраулары сіздің лимитіңізбен жүруі үшін WB жеке кабинетінде «Жеткізілімдер» санатына рұқсаты бар токен жасап, жіберіңіз:\n/apikey <токен>\nТокені бар хабарламаны бот бірден өшіреді.",
+	"apikey.status_set":  "🔑 Сіздің WB API кілтіңіз қосылған (%s).\nАуыстыру: /apikey <токен>\nӨшіру: /apikey delete",
+	"apikey.invalid":     "❌ WB кілтті қабылдамады: оның жарамды екенін және «Жеткізілімдерге» рұқсаты барын тексеріңіз.",
+	"apikey.check_error": "Кілтті WB-да тексеру мүмкін болмады. Кейінірек қайталаңыз.",
+	"apikey.error":       "Кілтті сақтау кезінде қате шықты.",
+	"apikey.saved":       "✅ WB API кілті (%s) тексеріліп, шифрланған түрде сақталды. Енді хабарламаларыңыз үшін коэффициенттер осы кілтпен сұралады.",
+	"apikey.removed":     "✅ Кілт өшірілді, хабарламалар боттың ортақ кілтімен тексеріледі.",
+	"apikey.malformed":   "❌ Бұл WB API токеніне ұқсамайды. Токенді WB жеке кабинетінен толық көшіріңіз.",
+	"apikey.no_scope":    "❌ Токеннің «Жеткізілімдер» санатына рұқсаты жоқ — онсыз бот қабылдау коэффициенттерін ала алмайды. Осы санаты бар токен жасаңыз.",
+	"apikey.expired":     "❌ Токеннің мерзімі %s өтіп кеткен. WB жеке кабинетінде жаңа токен жасаңыз.",
+	"apikey.expires":     "%s дейін жарамды (ММУ).",
+	"apikey.expiring":    "⚠️ Токеннің мерзімі %s (ММУ) бітеді — жаңасын алдын ала жасап, /apikey арқылы жіберіңіз.",
+
+	// Сроки ключей WB
+	"keys.shared_expiring": "⚠️ Боттың ортақ WB API кілтінің мерзімі %s (ММУ) бітеді. WB_API_KEY ауыстырыңыз, әйтпесе бот коэффициенттерді ала алмайды.",
+	"keys.shared_expired":  "⛔ Боттың ортақ WB API кілтінің мерзімі %s (ММУ) бітті. Бот коэффициенттерді алмай тұр — WB_API_KEY ауыстырыңыз.",
+	"keys.user_expiring":   "⚠️ Сіздің WB API кілтіңіздің мерзімі %s (ММУ) бітеді. Жаңа токен жасап, /apikey арқылы жіберіңіз, әйтпесе хабарламалар боттың ортақ кілтімен тексеріледі.",
+	"keys.user_expired":    "⛔ Сіздің WB API кілтіңіздің мерзімі %s (ММУ) бітті, хабарламалар боттың ортақ кілтімен тексеріледі. Жаңа токенді /apikey арқылы жіберіңіз.",
+
+	// Исходящие вебхуки
+	"webhook.disabled":      "Вебхуктарды әкімші өшірген.",
+	"webhook.usage":         "Вебхуктар қоймаларыңыздағы қабылдаудың әр ашылуы, жабылуы және коэффициенттің өзгеруі туралы JSON бар POST алады.\n\n/webhook add <https://…> — қоймаларыңыз бойынша жеке вебхук\n/webhook team <https://…> — команда қоймалары бойынша вебхук\n/webhook log <ID> — соңғы жеткізулер\n/webhook enable <ID> — қайта қосу\n/webhook delete <ID> — жою",
+	"webhook.error":         "Вебхуктармен жұмыс кезінде қате. Кейінірек қайталаңыз.",
+	"webhook.empty":         "🔗 Әзірге вебхуктар жоқ.",
+	"webhook.header":        "🔗 Сіздің вебхуктарыңыз:",
+	"webhook.team_mark":     "(команда)",
+	"webhook.last_error":    "өшірілген: %s",
+	"webhook.invalid_url":   "❌ Мекенжай https:// деп басталып, хосты болуы керек.",
+	"webhook.private_url":   "❌ Жергілікті желі мекенжайларына қолдау жоқ.",
+	"webhook.limit.one":     "%d вебхуктан артық қосуға болмайды.",
+	"webhook.limit.other":   "%d вебхуктан артық қосуға болмайды.",
+	"webhook.added":         "✅ #%d вебхугы қосылды.\n\nҚолтаңба құпиясы (бір рет қана көрсетіледі):\n%s\n\nX-Webhook-Signature тақырыбы = sha256=HMAC-SHA256(құпия, X-Webhook-Timestamp + \".\" + сұрау денесі).",
+	"webhook.deleted":       "✅ #%d вебхугы жойылды.",
+	"webhook.enabled":       "✅ #%d вебхугы қосылды.",
+	"webhook.not_found":     "#%d вебхугы табылмады.",
+	"webhook.log_empty":     "#%d вебхугына әлі жеткізу болған жоқ.",
+	"webhook.log_header":    "📜 #%d соңғы жеткізулері (%s):",
+	"webhook.log_delivered": "✅ %d",
+	"webhook.log_dead":      "❌ %s",
+	"webhook.log_pending":   "⏳ әрекеттер: %d",
+	"webhook.auto_disabled": "⛔ #%d вебхугы (%s) бірнеше сәтсіз жеткізуден кейін өшірілді. Соңғы қате: %s\n\nМекенжайды түзетіп, қайта қосыңыз: /webhook enable %[1]d",
+
+	// Токены REST API
+	"apitoken.disabled":    "REST API-ді әкімші өшірген.",
+	"apitoken.usage":       "Токен Authorization: Bearer <токен> тақырыбында беріледі.\n\n/apitoken new [атауы] — токен шығару\n/apitoken revoke <ID> — кері қайтару",
+	"apitoken.error":       "Токендермен жұмыс кезінде қате. Кейінірек қайталаңыз.",
+	"apitoken.empty":       "🔐 Әзірге API токендері жоқ.",
+	"apitoken.header":      "🔐 Сіздің API токендеріңіз:",
+	"apitoken.item":        "шығарылды %s, соңғы қолданылуы: %s",
+	"apitoken.never":       "ешқашан",
+	"apitoken.limit.one":   "%d токеннен артық шығаруға болмайды. Қажетсізін кері қайтарыңыз: /apitoken revoke <ID>",
+	"apitoken.limit.other": "%d токеннен артық шығаруға болмайды. Қажетсізін кері қайтарыңыз: /apitoken revoke <ID>",
+	"apitoken.created":     "✅ #%d токені шығарылды. Оны сақтаңыз — ол қайта көрсетілмейді:\n\n%s",
+	"apitoken.revoked":     "✅ #%d токені кері қайтарылды.",
+	"apitoken.not_found":   "#%d токені табылмады.",
+
+	// Изменения подписок через REST API
+	"api.subscription_added":   "🔌 REST API арқылы %s қоймасы (ID: %d) бақылауға қосылды.",
+	"api.subscription_updated": "🔌 REST API арқылы %s қоймасының (ID: %d) шарттары өзгертілді.",
+	"api.subscription_removed": "🔌 REST API арқылы %s қоймасы (ID: %d) бақылаудан алынды.",
+	"api.subscription_filter":  "Коэффициент %d дейін болғанда хабарланады, жеткізу түрі: %s.",
+	"api.box_type_any":         "кез келген",
+
+	// Администрирование
+	"admin.stats":               "📊 Статистика\n\n👤 Пайдаланушылар: %d (ботты бұғаттағандар: %d, тыйым салынғандар: %d)\n📦 Жазылымдар: %d\n🔔 Бүгінгі хабарламалар: %d жеткізілді, %d жеткізілмеді\n🌐 WB API: %d сұрау, %d қате (%.1f%%)\n⏱ Тексеру аралығы: %s\n\n🏆 Танымал қоймалар:\n",
+	"admin.stats_error":         "Статистиканы жинау кезінде қате шықты.",
+	"admin.top_empty":           "әзірге жазылымдар жоқ\n",
+	"admin.broadcast_usage":     "Қолдану: /broadcast <мәтін>",
+	"admin.broadcast_preview":   "📣 Тарату осылай көрінеді. Алушылар: %d.",
+	"admin.broadcast_send":      "✅ Жіберу",
+	"admin.broadcast_cancel":    "✖️ Бас тарту",
+	"admin.broadcast_error":     "Таратуды дайындау кезінде қате шықты.",
+	"admin.broadcast_queued":    "✅ Тарату кезекке қойылды: %d алушы.",
+	"admin.broadcast_cancelled": "Тарату тоқтатылды.",
+	"admin.broadcast_expired":   "Тарату жобасы табылмады: ол жіберілген немесе тоқтатылған.",
+	"admin.user_usage":          "Қолдану: /user <Telegram ID>",
+	"admin.user_not_found":      "%d пайдаланушысы табылмады.",
+	"admin.user_error":          "Пайдаланушыны алу кезінде қате шықты.",
+	"admin.user_info":           "👤 %s (ID: %d)\nТіл: %s\nСоңғы кіру: %s\nБотты бұғаттаған: %s\nТыйым салынған: %s\nТоп: %s\n\n📦 Қоймалар:\n",
+	"admin.yes":                 "иә",
+	"admin.no":                  "жоқ",
+	"admin.ban_usage":           "Қолдану: /%s <Telegram ID>",
+	"admin.ban_admin":           "Әкімшіге тыйым салуға болмайды.",
+	"admin.ban_error":           "Тыйымды өзгерту кезінде қате шықты.",
+	"admin.banned":              "⛔ %d пайдаланушысына тыйым салынды: бот оған жауап бермейді және хабарлама жібермейді.",
+	"admin.unbanned":            "✅ %d пайдаланушысынан тыйым алынды.",
+	"admin.interval_current":    "⏱ Коэффициенттерді тексеру аралығы: %s.\nБарлық даналар үшін өзгерту: /pollinterval <секунд> (%d-ден %d-ге дейін)",
+	"admin.interval_invalid":    "Аралық %d-ден %d-ге дейінгі секунд саны болуы керек.",
+	"admin.interval_set":        "✅ Коэффициенттерді тексеру аралығы: %s. Жетекші дана оны келесі тексеруден бастап қолданады.",
+	"admin.interval_error":      "Аралықты сақтау кезінде қате.",
+}
@@ -0,0 +1,255 @@
+package i18n
+
+var ru = map[string]string{
+	// Общие ошибки
+	"error.internal":            "Произошла внутренняя ошибка. Попробуйте позже.",
+	"error.rate_limited":        "⏳ Слишком много запросов, подождите немного.",
+	"error.private_only":        "Эта команда доступна только в личном чате с ботом.",
+	"error.users_only":          "Эта команда доступна только пользователям. Напишите боту /start в личном чате.",
+	"error.not_chat_admin":      "⛔ Изменять склады в этом чате могут только администраторы.",
+	"error.invalid_warehouse":   "Ошибка: введите ID или название склада.",
+	"error.catalog":             "Ошибка при получении списка складов. Попробуйте позже.",
+	"error.unknown_command":     "Неизвестная команда. Введите /help для списка доступных команд.",
+	"warehouse.unknown":         "Неизвестный склад",
+	"warehouse.unknown_with_id": "Неизвестный склад (ID: %d)",
+
+	// Старт и помощь
+	"start.group":        "Бот подключён к чату! 🎉 Уведомления о лимитах будут приходить сюда. Управлять складами могут администраторы.",
+	"start.welcome":      "Вы зарегистрированы! 🎉 Добро пожаловать!",
+	"start.welcome_back": "С возвращением! 👋",
+	"help": "📋 Доступные команды:\n" +
+		"/start - Начало работы\n" +
+		"/help - Помощь\n" +
+		"/warehouses - Список всех складов\n" +
+		"/addwarehouse - Добавить склад в отслеживание\n" +
+		"/mywarehouses - Показать мои склады\n" +
+		"/status - Коэффициенты моих складов на 14 дней\n" +
+		"/removewarehouse - Удалить склад из отслеживания\n" +
+		"/setinterval - Установить интервал проверки лимитов\n" +
+		"/language - Язык бота\n\n" +
+		"👥 Команды:\n" +
+		"/newteam - Создать команду\n" +
+		"/team - Моя команда и общие склады\n" +
+		"/invite - Ссылка-приглашение (editor или viewer)\n" +
+		"/teamadd - Добавить склад в общий список\n" +
+		"/teamremove - Удалить склад из общего списка\n" +
+		"/setrole - Изменить роль участника\n" +
+		"/kick - Исключить участника\n" +
+		"/leaveteam - Выйти из команды\n\n" +
+		"🔕 Уведомления:\n" +
+		"/quiet - Тихие часы, например /quiet 23-8\n" +
+		"/mute - Выключить уведомления на N часов\n" +
+		"/unmute - Включить уведомления\n" +
+		"/template - Шаблон уведомлений\n" +
+		"/apikey - Свой ключ WB API\n" +
+		"/webhook - Вебхуки о событиях по складам\n" +
+		"/apitoken - Токены REST API",
+
+	// Склады
+	"warehouses.error":   "Ошибка при получении складов. Попробуйте позже.",
+	"warehouses.header":  "📦 Список доступных складов:\n",
+	"add.prompt":         "Введите ID или название склада, который хотите добавить в отслеживание. Можно несколько через запятую, например: Коледино, Подольск, 507",
+	"add.error":          "Ошибка при добавлении склада.",
+	"add.failed":         "❌ Не удалось добавить склад %s (ID: %d), попробуйте ещё раз позже.",
+	"add.done":           "✅ Склад %s (ID: %d) добавлен в отслеживание!",
+	"add.not_found":      "❓ Склад «%s» не найден.",
+	"add.search_hint":    "Проверьте название или найдите склад в списке: /warehouses",
+	"add.choose":         "🔎 По запросу «%s» подходят несколько складов, выберите нужный:",
+	"add.choose_more":    "Показаны не все — уточните запрос, если нужного нет.",
+	"my.error":           "Ошибка при получении ваших складов.",
+	"my.empty":           "У вас пока нет складов в отслеживании. Добавьте их через /addwarehouse.",
+	"my.header.one":      "📦 Вы отслеживаете %d склад:\n",
+	"my.header.few":      "📦 Вы отслеживаете %d склада:\n",
+	"my.header.many":     "📦 Вы отслеживаете %d складов:\n",
+	"status.unavailable": "Коэффициенты WB пока недоступны. Попробуйте через пару минут.",
+	"status.header":      "📊 Приёмка с %s по %s:",
+	"status.no_data":     "нет данных WB",
+	"status.legend":      "🟢 бесплатно  🟡 ×1–×%d  🔴 дороже ×%d  ⚫ закрыто",
+	"status.age_now":     "🕒 Данные получены меньше минуты назад.",
+	"status.age.one":     "🕒 Данные получены %d минуту назад.",
+	"status.age.few":     "🕒 Данные получены %d минуты назад.",
+	"status.age.many":    "🕒 Данные получены %d минут назад.",
+	"remove.prompt":      "Введите ID склада, который хотите удалить из отслеживания:",
+	"remove.error":       "Ошибка при удалении склада.",
+	"remove.done":        "✅ Склад с ID %d успешно удалён из отслеживания!",
+	"interval.prompt":    "Введите интервал проверки в минутах (например 5, 10, 15):",
+	"interval.invalid":   "Ошибка: пожалуйста, введите положительное число.",
+	"interval.error":     "Ошибка при сохранении интервала.",
+	"interval.done.one":  "✅ Интервал обновлён! Теперь лимиты будут проверяться раз в %d минуту.",
+	"interval.done.few":  "✅ Интервал обновлён! Теперь лимиты будут проверяться раз в %d минуты.",
+	"interval.done.many": "✅ Интервал обновлён! Теперь лимиты будут проверяться раз в %d минут.",
+
+	// Уведомления о лимитах
+	"alert.slot": "📦 Лимит на складе: %s (ID: %d)\n📈 Коэффициент: %d\n🗓 Дата: %s",
+
+	// Командная работа
+	"role.owner":             "владелец",
+	"role.editor":            "редактор",
+	"role.viewer":            "наблюдатель",
+	"team.name_required":     "Укажите название команды: /newteam <название>",
+	"team.already_member":    "Вы уже состоите в команде. Сначала выйдите из неё через /leaveteam.",
+	"team.create_error":      "Ошибка при создании команды.",
+	"team.created":           "✅ Команда «%s» создана! Пригласите коллег через /invite editor или /invite viewer.",
+	"team.fetch_error":       "Ошибка при получении команды.",
+	"team.header":            "👥 Команда «%s»\nВаша роль: %s\n\n",
+	"team.members.one":       "%d участник:\n",
+	"team.members.few":       "%d участника:\n",
+	"team.members.many":      "%d участников:\n",
+	"team.warehouses":        "\n📦 Общие склады:\n",
+	"team.no_warehouses":     "пока нет, добавьте через /teamadd\n",
+	"team.invite_owner_only": "⛔ Приглашать в команду может только владелец.",
+	"team.invite_bad_role":   "Роль должна быть editor или viewer: /invite editor",
+	"team.invite_error":      "Ошибка при создании приглашения.",
+	"team.invite_link":       "🔗 Ссылка-приглашение в команду «%s» (роль: %s), действует 7 дней:\n%s",
+	"team.invite_expired":    "Приглашение недействительно или истекло. Попросите владельца команды прислать новое.",
+	"team.join_error":        "Ошибка при вступлении в команду.",
+	"team.joined":            "✅ Вы вступили в команду «%s» (роль: %s). Подробности: /team",
+	"team.member_joined":     "👋 @%s вступил(а) в команду «%s» (роль: %s).",
+	"team.add_prompt":        "Введите ID или название склада для общего списка команды. Можно несколько через запятую:",
+	"team.add_done":          "✅ Склад %s (ID: %d) добавлен в общий список команды!",
+	"team.remove_prompt":     "Введите ID склада, который хотите удалить из общего списка команды:",
+	"team.remove_done":       "✅ Склад с ID %d удалён из общего списка команды!",
+	"team.role_owner_only":   "⛔ Менять роли может только владелец команды.",
+	"team.setrole_usage":     "Использование: /setrole <ID участника> <editor|viewer>",
+	"team.member_not_found":  "Участник не найден в вашей команде.",
+	"team.setrole_error":     "Ошибка при смене роли.",
+	"team.role_changed":      "✅ Роль участника %d изменена на «%s».",
+	"team.kick_owner_only":   "⛔ Исключать участников может только владелец команды.",
+	"team.kick_usage":        "Использование: /kick <ID участника>",
+	"team.kick_error":        "Ошибка при исключении участника.",
+	"team.kicked":            "✅ Участник %d исключён из команды.",
+	"team.delete_error":      "Ошибка при удалении команды.",
+	"team.deleted":           "✅ Команда «%s» удалена.",
+	"team.leave_error":       "Ошибка при выходе из команды.",
+	"team.left":              "✅ Вы вышли из команды «%s».",
+	"team.not_member":        "Вы не состоите в команде. Создайте её через /newteam <название> или попросите приглашение.",
+	"team.editor_only":       "⛔ Менять склады команды могут только владелец и редакторы.",
+
+	// Тихие часы и пауза
+	"quiet.usage":  "Использование: /quiet 23-8 (часы по МСК) или /quiet off",
+	"quiet.error":  "Ошибка при сохранении тихих часов.",
+	"quiet.off":    "🔔 Тихие часы выключены.",
+	"quiet.on":     "🌙 Тихие часы: с %02d:00 до %02d:00 по МСК.",
+	"mute.usage":   "Использование: /mute <часы>, например /mute 3",
+	"mute.error":   "Ошибка при выключении уведомлений.",
+	"mute.on":      "🔕 Уведомления выключены до %s по МСК. Включить раньше: /unmute",
+	"unmute.error": "Ошибка при включении уведомлений.",
+	"unmute.done":  "🔔 Уведомления снова включены.",
+
+	// Язык
+	"language.choose": "🌐 Выберите язык бота:",
+	"language.auto":   "Как в Telegram",
+	"language.set":    "✅ Язык бота: %s",
+	"language.reset":  "✅ Язык бота будет выбираться по настройкам Telegram.",
+	"language.error":  "Ошибка при сохранении языка.",
+
+	// Шаблоны уведомлений
+	"template.scope.personal": "личный",
+	"template.scope.team":     "команды «%s»",
+	"template.default":        "стандартный текст",
+	"template.format.text":    "без разметки",
+	"template.show":           "🧩 Шаблон уведомлений (%s), разметка: %s\n\n%s\n\nПоля: {{.WarehouseName}}, {{.WarehouseID}}, {{.Date}}, {{.Coefficient}}, {{.BoxType}}, {{.BoxTypeID}}, {{.AllowUnload}}, {{.SortingCenter}}, {{.Link}}\n\n/template preview — пример уведомления\n/template set html|markdown|text — задать шаблон\n/template reset — вернуть стандартный текст\nДля шаблона команды: /template team …",
+	"template.usage":          "Использование: /template [team] [preview|set html|markdown|text|reset]",
+	"template.bad_format":     "Режим разметки должен быть html, markdown или text: /template set html",
+	"template.prompt":         "Отправьте текст шаблона, например:\n📦 {{.WarehouseName}}: коэффициент {{.Coefficient}} на {{.Date}}",
+	"template.invalid":        "❌ Ошибка в шаблоне: %v",
+	"template.error":          "Ошибка при сохранении шаблона.",
+	"template.saved":          "✅ Шаблон сохранён. Так будет выглядеть уведомление:",
+	"template.reset":          "✅ Уведомления снова приходят стандартным текстом.",
+	"template.preview_error":  "❌ Telegram не принял сообщение по шаблону: %v",
+
+	// Ключи WB API
+	"apikey.disabled":    "Подключение своих ключей WB API выключено администратором.",
+	"apikey.status_none": "🔑 Уведомления проверяются по общему ключу бота.\n\nЧтобы запросы к WB шли по вашему лимиту, создайте в личном кабинете WB токен с доступом к категории «Поставки» и отправьте:\n/apikey <токен>\nСообщение с токеном бот сразу удалит.",
+	"apikey.status_set":  "🔑 Подключён ваш ключ WB API (%s).\nЗаменить: /apikey <токен>\nУдалить: /apikey delete",
+	"apikey.invalid":     "❌ WB не принял ключ: проверьте, что он действующий и с доступом к «Поставкам».",
+	"apikey.check_error": "Не удалось проверить ключ в WB. Попробуйте позже.",
+	"apikey.error":       "Ошибка при сохранении ключа.",
+	"apikey.saved":       "✅ Ключ WB API (%s) проверен и сохранён в зашифрованном виде. Коэффициенты для ваших уведомлений теперь запрашиваются по нему.",
+	"apikey.removed":     "✅ Ключ удалён, уведомления проверяются по общему ключу бота.",
+	"apikey.malformed":   "❌ Это не похоже на токен WB API. Скопируйте токен целиком из личного кабинета WB.",
+	"apikey.no_scope":    "❌ У токена нет доступа к категории «Поставки» — без неё бот не получит коэффициенты приёмки. Создайте токен с этой категорией.",
+	"apikey.expired":     "❌ Срок действия токена истёк %s. Создайте новый токен в личном кабинете WB.",
+	"apikey.expires":     "Действует до %s (МСК).",
+	"apikey.expiring":    "⚠️ Токен истекает %s (МСК) — заранее создайте новый и отправьте его через /apikey.",
+
+	// Сроки ключей WB
+	"keys.shared_expiring": "⚠️ Общий ключ WB API бота истекает %s (МСК). Замените WB_API_KEY, иначе бот перестанет получать коэффициенты.",
+	"keys.shared_expired":  "⛔ Общий ключ WB API бота истёк %s (МСК). Бот не получает коэффициенты — замените WB_API_KEY.",
+	"keys.user_expiring":   "⚠️ Ваш ключ WB API истекает %s (МСК). Создайте новый токен и отправьте его через /apikey, иначе уведомления будут проверяться по общему ключу бота.",
+	"keys.user_expired":    "⛔ Ваш ключ WB API истёк %s (МСК), уведомления проверяются по общему ключу бота. Отправьте новый токен через /apikey.",
+
+	// Исходящие вебхуки
+	"webhook.disabled":      "Вебхуки выключены администратором.",
+	"webhook.usage":         "Вебхуки получают POST с JSON о каждом открытии и закрытии приёмки и смене коэффициента на ваших складах.\n\n/webhook add <https://…> — личный вебхук по вашим складам\n/webhook team <https://…> — вебхук по складам команды\n/webhook log <ID> — последние доставки\n/webhook enable <ID> — включить снова\n/webhook delete <ID> — удалить",
+	"webhook.error":         "Ошибка при работе с вебхуками. Попробуйте позже.",
+	"webhook.empty":         "🔗 Вебхуков пока нет.",
+	"webhook.header":        "🔗 Ваши вебхуки:",
+	"webhook.team_mark":     "(команда)",
+	"webhook.last_error":    "отключён: %s",
+	"webhook.invalid_url":   "❌ Адрес должен начинаться с https:// и содержать хост.",
+	"webhook.private_url":   "❌ Адреса локальной сети не поддерживаются.",
+	"webhook.limit.one":     "Можно добавить не больше %d вебхука.",
+	"webhook.limit.few":     "Можно добавить не больше %d вебхуков.",
+	"webhook.limit.many":    "Можно добавить не больше %d вебхуков.",
+	"webhook.added":         "✅ Вебхук #%d добавлен.\n\nСекрет подписи (показывается один раз):\n%s\n\nЗаголовок X-Webhook-Signature = sha256=HMAC-SHA256(секрет, X-Webhook-Timestamp + \".\" + тело запроса).",
+	"webhook.deleted":       "✅ Вебхук #%d удалён.",
+	"webhook.enabled":       "✅ Вебхук #%d включён.",
+	"webhook.not_found":     "Вебхук #%d не найден.",
+	"webhook.log_empty":     "Доставок на вебхук #%d ещё не было.",
+	"webhook.log_header":    "📜 Последние доставки #%d (%s):",
+	"webhook.log_delivered": "✅ %d",
+	"webhook.log_dead":      "❌ %s",
+	"webhook.log_pending":   "⏳ попыток: %d",
+	"webhook.auto_disabled": "⛔ Вебхук #%d (%s) отключён после серии неудачных доставок. Последняя ошибка: %s\n\nИсправьте адрес и включите его: /webhook enable %[1]d",
+
+	// Токены REST API
+	"apitoken.disabled":   "REST API выключено администратором.",
+	"apitoken.usage":      "Токен передаётся в заголовке Authorization: Bearer <токен>.\n\n/apitoken new [название] — выпустить токен\n/apitoken revoke <ID> — отозвать",
+	"apitoken.error":      "Ошибка при работе с токенами. Попробуйте позже.",
+	"apitoken.empty":      "🔐 Токенов API пока нет.",
+	"apitoken.header":     "🔐 Ваши токены API:",
+	"apitoken.item":       "выпущен %s, использован: %s",
+	"apitoken.never":      "ни разу",
+	"apitoken.limit.one":  "Можно выпустить не больше %d токена. Отзовите ненужный: /apitoken revoke <ID>",
+	"apitoken.limit.few":  "Можно выпустить не больше %d токенов. Отзовите ненужный: /apitoken revoke <ID>",
+	"apitoken.limit.many": "Можно выпустить не больше %d токенов. Отзовите ненужный: /apitoken revoke <ID>",
+	"apitoken.created":    "✅ Токен #%d выпущен. Сохраните его — больше он показан не будет:\n\n%s",
+	"apitoken.revoked":    "✅ Токен #%d отозван.",
+	"apitoken.not_found":  "Токен #%d не найден.",
+
+	// Изменения подписок через REST API
+	"api.subscription_added":   "🔌 Через REST API в отслеживание добавлен склад %s (ID: %d).",
+	"api.subscription_updated": "🔌 Через REST API изменены условия для склада %s (ID: %d).",
+	"api.subscription_removed": "🔌 Через REST API из отслеживания удалён склад %s (ID: %d).",
+	"api.subscription_filter":  "Уведомления при коэффициенте до %d, тип поставки: %s.",
+	"api.box_type_any":         "любой",
+
+	// Администрирование
+	"admin.stats":               "📊 Статистика\n\n👤 Пользователи: %d (заблокировали бота: %d, забанены: %d)\n📦 Подписок: %d\n🔔 Уведомлений сегодня: %d доставлено, %d не доставлено\n🌐 WB API: %d запросов, %d ошибок (%.1f%%)\n⏱ Интервал проверки: %s\n\n🏆 Популярные склады:\n",
+	"admin.stats_error":         "Ошибка при сборе статистики.",
+	"admin.top_empty":           "подписок пока нет\n",
+	"admin.broadcast_usage":     "Использование: /broadcast <текст>",
+	"admin.broadcast_preview":   "📣 Так будет выглядеть рассылка. Получателей: %d.",
+	"admin.broadcast_send":      "✅ Отправить",
+	"admin.broadcast_cancel":    "✖️ Отмена",
+	"admin.broadcast_error":     "Ошибка при подготовке рассылки.",
+	"admin.broadcast_queued":    "✅ Рассылка поставлена в очередь: %d получателей.",
+	"admin.broadcast_cancelled": "Рассылка отменена.",
+	"admin.broadcast_expired":   "Черновик рассылки не найден: он уже отправлен или отменён.",
+	"admin.user_usage":          "Использование: /user <Telegram ID>",
+	"admin.user_not_found":      "Пользователь %d не найден.",
+	"admin.user_error":          "Ошибка при получении пользователя.",
+	"admin.user_info":           "👤 %s (ID: %d)\nЯзык: %s\nПоследний визит: %s\nЗаблокировал бота: %s\nЗабанен: %s\nКоманда: %s\n\n📦 Склады:\n",
+	"admin.yes":                 "да",
+	"admin.no":                  "нет",
+	"admin.ban_usage":           "Использование: /%s <Telegram ID>",
+	"admin.ban_admin":           "Нельзя забанить администратора.",
+	"admin.ban_error":           "Ошибка при изменении бана.",
+	"admin.banned":              "⛔ Пользователь %d забанен: бот не отвечает ему и не присылает уведомления.",
+	"admin.unbanned":            "✅ Пользователь %d разбанен.",
+	"admin.interval_current":    "⏱ Интервал проверки коэффициентов: %s.\nИзменить для всех экземпляров: /pollinterval <секунды> (от %d до %d)",
+	"admin.interval_invalid":    "Интервал должен быть числом секунд от %d до %d.",
+	"admin.interval_set":        "✅ Интервал проверки коэффициентов: %s. Ведущий экземпляр применит его со следующей проверки.",
+	"admin.interval_error":      "Ошибка при сохранении интервала.",
+}
@@ -0,0 +1,249 @@
+package i18n
+
+var uz = map[string]string{
+	// Общие ошибки
+	"error.internal":            "Ichki xatolik yuz berdi. Keyinroq qayta urinib koʻring.",
+	"error.rate_limited":        "⏳ Soʻrovlar juda koʻp, biroz kuting.",
+	"error.private_only":        "Bu buyruq faqat bot bilan shaxsiy chatda ishlaydi.",
+	"error.users_only":          "Bu buyruq faqat foydalanuvchilar uchun. Botga shaxsiy chatda /start yozing.",
+	"error.not_chat_admin":      "⛔ Bu chatda omborlarni faqat administratorlar oʻzgartira oladi.",
+	"error.invalid_warehouse":   "Xato: omborning ID sini yoki nomini kiriting.",
+	"error.catalog":             "Omborlar roʻyxatini olishda xatolik. Keyinroq qayta urinib koʻring.",
+	"error.unknown_command":     "Nomaʼlum buyruq. Mavjud buyruqlar roʻyxati uchun /help yuboring.",
+	"warehouse.unknown":         "Nomaʼlum ombor",
+	"warehouse.unknown_with_id": "Nomaʼlum ombor (ID: %d)",
+
+	// Старт и помощь
+	"start.group":        "Bot chatga ulandi! 🎉 Limitlar haqidagi xabarlar shu yerga keladi. Omborlarni administratorlar boshqaradi.",
+	"start.welcome":      "Siz roʻyxatdan oʻtdingiz! 🎉 Xush kelibsiz!",
+	"start.welcome_back": "Qaytganingiz bilan! 👋",
+	"help": "📋 Mavjud buyruqlar:\n" +
+		"/start - Boshlash\n" +
+		"/help - Yordam\n" +
+		"/warehouses - Barcha omborlar roʻyxati\n" +
+		"/addwarehouse - Omborni kuzatuvga qoʻshish\n" +
+		"/mywarehouses - Mening omborlarim\n" +
+		"/status - Omborlarimning 14 kunlik koeffitsiyentlari\n" +
+		"/removewarehouse - Omborni kuzatuvdan olib tashlash\n" +
+		"/setinterval - Limitlarni tekshirish oraligʻini belgilash\n" +
+		"/language - Bot tili\n\n" +
+		"👥 Jamoalar:\n" +
+		"/newteam - Jamoa yaratish\n" +
+		"/team - Mening jamoam va umumiy omborlar\n" +
+		"/invite - Taklif havolasi (editor yoki viewer)\n" +
+		"/teamadd - Umumiy roʻyxatga ombor qoʻshish\n" +
+		"/teamremove - Umumiy roʻyxatdan omborni olib tashlash\n" +
+		"/setrole - Aʼzoning rolini oʻzgartirish\n" +
+		"/kick - Aʼzoni chiqarish\n" +
+		"/leaveteam - Jamoadan chiqish\n\n" +
+		"🔕 Bildirishnomalar:\n" +
+		"/quiet - Sokin soatlar, masalan /quiet 23-8\n" +
+		"/mute - Bildirishnomalarni N soatga oʻchirish\n" +
+		"/unmute - Bildirishnomalarni yoqish\n" +
+		"/template - Bildirishnoma shabloni\n" +
+		"/apikey - Oʻz WB API kalitingiz\n" +
+		"/webhook - Omborlar hodisalari haqida vebhuklar\n" +
+		"/apitoken - REST API tokenlari",
+
+	// Склады
+	"warehouses.error":    "Omborlarni olishda xatolik. Keyinroq qayta urinib koʻring.",
+	"warehouses.header":   "📦 Mavjud omborlar roʻyxati:\n",
+	"add.prompt":          "Kuzatuvga qoʻshmoqchi boʻlgan omborning ID sini yoki nomini kiriting. Bir nechtasini vergul bilan yozish mumkin, masalan: Koledino, Podolsk, 507",
+	"add.error":           "Omborni qoʻshishda xatolik.",
+	"add.failed":          "❌ %s omborini (ID: %d) qoʻshib boʻlmadi, keyinroq qayta urinib koʻring.",
+	"add.done":            "✅ %s ombori (ID: %d) muvaffaqiyatli qoʻshildi!",
+	"add.not_found":       "❓ «%s» ombori topilmadi.",
+	"add.search_hint":     "Nomini tekshiring yoki omborni roʻyxatdan toping: /warehouses",
+	"add.choose":          "🔎 «%s» soʻroviga bir nechta ombor mos keladi, keraklisini tanlang:",
+	"add.choose_more":     "Hammasi koʻrsatilmagan — kerakli ombor boʻlmasa, soʻrovni aniqlashtiring.",
+	"my.error":            "Omborlaringizni olishda xatolik.",
+	"my.empty":            "Hozircha kuzatuvda omborlar yoʻq. Ularni /addwarehouse orqali qoʻshing.",
+	"my.header.one":       "📦 Siz %d ta omborni kuzatyapsiz:\n",
+	"my.header.other":     "📦 Siz %d ta omborni kuzatyapsiz:\n",
+	"status.unavailable":  "WB koeffitsiyentlari hozircha mavjud emas. Bir necha daqiqadan keyin urinib koʻring.",
+	"status.header":       "📊 %s – %s oraligʻidagi qabul:",
+	"status.no_data":      "WB maʼlumotlari yoʻq",
+	"status.legend":       "🟢 bepul  🟡 ×1–×%d  🔴 ×%d dan qimmat  ⚫ yopiq",
+	"status.age_now":      "🕒 Maʼlumotlar bir daqiqadan kamroq vaqt oldin olingan.",
+	"status.age.one":      "🕒 Maʼlumotlar %d daqiqa oldin olingan.",
+	"status.age.other":    "🕒 Maʼlumotlar %d daqiqa oldin olingan.",
+	"remove.prompt":       "Kuzatuvdan olib tashlamoqchi boʻlgan omborning ID sini kiriting:",
+	"remove.error":        "Omborni olib tashlashda xatolik.",
+	"remove.done":         "✅ ID %d ombori kuzatuvdan olib tashlandi!",
+	"interval.prompt":     "Tekshirish oraligʻini daqiqalarda kiriting (masalan 5, 10, 15):",
+	"interval.invalid":    "Xato: iltimos, musbat son kiriting.",
+	"interval.error":      "Oraliqni saqlashda xatolik.",
+	"interval.done.one":   "✅ Oraliq yangilandi! Endi limitlar har %d daqiqada tekshiriladi.",
+	"interval.done.other": "✅ Oraliq yangilandi! Endi limitlar har %d daqiqada tekshiriladi.",
+
+	// Уведомления о лимитах
+	"alert.slot": "📦 Ombordagi limit: %s (ID: %d)\n📈 Koeffitsiyent: %d\n🗓 Sana: %s",
+
+	// Командная работа
+	"role.owner":             "egasi",
+	"role.editor":            "muharrir",
+	"role.viewer":            "kuzatuvchi",
+	"team.name_required":     "Jamoa nomini kiriting: /newteam <nomi>",
+	"team.already_member":    "Siz allaqachon jamoadasiz. Avval /leaveteam orqali undan chiqing.",
+	"team.create_error":      "Jamoa yaratishda xatolik.",
+	"team.created":           "✅ «%s» jamoasi yaratildi! Hamkasblarni /invite editor yoki /invite viewer orqali taklif qiling.",
+	"team.fetch_error":       "Jamoani olishda xatolik.",
+	"team.header":            "👥 «%s» jamoasi\nSizning rolingiz: %s\n\n",
+	"team.members.one":       "%d ta aʼzo:\n",
+	"team.members.other":     "%d ta aʼzo:\n",
+	"team.warehouses":        "\n📦 Umumiy omborlar:\n",
+	"team.no_warehouses":     "hozircha yoʻq, /teamadd orqali qoʻshing\n",
+	"team.invite_owner_only": "⛔ Jamoaga faqat egasi taklif qila oladi.",
+	"team.invite_bad_role":   "Rol editor yoki viewer boʻlishi kerak: /invite editor",
+	"team.invite_error":      "Taklif yaratishda xatolik.",
+	"team.invite_link":       "🔗 «%s» jamoasiga taklif havolasi (rol: %s), 7 kun amal qiladi:\n%s",
+	"team.invite_expired":    "Taklif yaroqsiz yoki muddati oʻtgan. Jamoa egasidan yangisini soʻrang.",
+	"team.join_error":        "Jamoaga qoʻshilishda xatolik.",
+	"team.joined":            "✅ Siz «%s» jamoasiga qoʻshildingiz (rol: %s). Batafsil: /team",
+	"team.member_joined":     "👋 @%s «%s» jamoasiga qoʻshildi (rol: %s).",
+	"team.add_prompt":        "Jamoaning umumiy roʻyxatiga qoʻshiladigan omborning ID sini yoki nomini kiriting. Bir nechtasini vergul bilan yozish mumkin:",
+	"team.add_done":          "✅ %s ombori (ID: %d) jamoaning umumiy roʻyxatiga qoʻshildi!",
+	"team.remove_prompt":     "Jamoaning umumiy roʻyxatidan olib tashlanadigan omborning ID sini kiriting:",
+	"team.remove_done":       "✅ ID %d ombori jamoaning umumiy roʻyxatidan olib tashlandi!",
+	"team.role_owner_only":   "⛔ Rollarni faqat jamoa egasi oʻzgartira oladi.",
+	"team.setrole_usage":     "Foydalanish: /setrole <aʼzo ID> <editor|viewer>",
+	"team.member_not_found":  "Aʼzo jamoangizda topilmadi.",
+	"team.setrole_error":     "Rolni oʻzgartirishda xatolik.",
+	"team.role_changed":      "✅ %d aʼzoning roli «%s» ga oʻzgartirildi.",
+	"team.kick_owner_only":   "⛔ Aʼzolarni faqat jamoa egasi chiqara oladi.",
+	"team.kick_usage":        "Foydalanish: /kick <aʼzo ID>",
+	"team.kick_error":        "Aʼzoni chiqarishda xatolik.",
+	"team.kicked":            "✅ %d aʼzo jamoadan chiqarildi.",
+	"team.delete_error":      "Jamoani oʻchirishda xatolik.",
+	"team.deleted":           "✅ «%s» jamoasi oʻchirildi.",
+	"team.leave_error":       "Jamoadan chiqishda xatolik.",
+	"team.left":              "✅ Siz «%s» jamoasidan chiqdingiz.",
+	"team.not_member":        "Siz hech qaysi jamoada emassiz. /newteam <nomi> orqali jamoa yarating yoki taklif soʻrang.",
+	"team.editor_only":       "⛔ Jamoa omborlarini faqat egasi va muharrirlar oʻzgartira oladi.",
+
+	// Тихие часы и пауза
+	"quiet.usage":  "Foydalanish: /quiet 23-8 (Moskva vaqti) yoki /quiet off",
+	"quiet.error":  "Sokin soatlarni saqlashda xatolik.",
+	"quiet.off":    "🔔 Sokin soatlar oʻchirildi.",
+	"quiet.on":     "🌙 Sokin soatlar: Moskva vaqti bilan %02d:00 dan %02d:00 gacha.",
+	"mute.usage":   "Foydalanish: /mute <soat>, masalan /mute 3",
+	"mute.error":   "Bildirishnomalarni oʻchirishda xatolik.",
+	"mute.on":      "🔕 Bildirishnomalar Moskva vaqti bilan %s gacha oʻchirildi. Oldinroq yoqish: /unmute",
+	"unmute.error": "Bildirishnomalarni yoqishda xatolik.",
+	"unmute.done":  "🔔 Bildirishnomalar yana yoqildi.",
+
+	// Язык
+	"language.choose": "🌐 Bot tilini tanlang:",
+	"language.auto":   "Telegramdagidek",
+	"language.set":    "✅ Bot tili: %s",
+	"language.reset":  "✅ Bot tili Telegram sozlamalariga qarab tanlanadi.",
+	"language.error":  "Tilni saqlashda xatolik.",
+
+	// Шаблоны уведомлений
+	"template.scope.personal": "shaxsiy",
+	"template.scope.team":     "«%s» jamoasi",
+	"template.default":        "standart matn",
+	"template.format.text":    "belgilashsiz",
+	"template.show":           "🧩 Bildirishnoma shabloni (%s), belgilash: %s\n\n%s\n\nMaydonlar: {{.WarehouseName}}, {{.WarehouseID}}, {{.Date}}, {{.Coefficient}}, {{.BoxType}}, {{.BoxTypeID}}, {{.AllowUnload}}, {{.SortingCenter}}, {{.Link}}\n\n/template preview — bildirishnoma namunasi\n/template set html|markdown|text — shablonni oʻrnatish\n/template reset — standart matnga qaytish\nJamoa shabloni uchun: /template team …",
+	"template.usage":          "Foydalanish: /template [team] [preview|set html|markdown|text|reset]",
+	"template.bad_format":     "Belgilash html, markdown yoki text boʻlishi kerak: /template set html",
+	"template.prompt":         "Shablon matnini yuboring, masalan:\n📦 {{.WarehouseName}}: koeffitsiyent {{.Coefficient}}, sana {{.Date}}",
+	"template.invalid":        "❌ Shablonda xato: %v",
+	"template.error":          "Shablonni saqlashda xatolik.",
+	"template.saved":          "✅ Shablon saqlandi. Bildirishnoma shunday koʻrinadi:",
+	"template.reset":          "✅ Bildirishnomalar yana standart matnda keladi.",
+	"template.preview_error":  "❌ Telegram shablon boʻyicha xabarni qabul qilmadi: %v",
+
+	// Ключи WB API
+	"apikey.disabled":    "Oʻz WB API kalitlarini ulash administrator tomonidan oʻchirilgan.",
+	"apikey.status_none": "🔑 Bildirishnomalar botning umumiy kaliti bilan tekshiriladi.\n\nWB soʻrovlari sizning limitingiz hisobidan ketishi uchun WB shaxsiy kabinetida «Yetkazib berishlar» toifasiga ruxsati bor token yarating va yuboring:\n/apikey <token>\nBot token yozilgan xabarni darhol oʻchiradi.",
+	"apikey.status_set":  "🔑 Sizning WB API kalitingiz ulangan (%s).\nAlmashtirish: /apikey <token>\nOʻchirish: /apikey delete",
+	"apikey.invalid":     "❌ WB kalitni qabul qilmadi: u amal qilishini va «Yetkazib berishlar»ga ruxsati borligini tekshiring.",
+	"apikey.check_error": "Kalitni WB orqali tekshirib boʻlmadi. Keyinroq urinib koʻring.",
+	"apikey.error":       "Kalitni saqlashda xatolik yuz berdi.",
+	"apikey.saved":       "✅ WB API kaliti (%s) tekshirildi va shifrlangan holda saqlandi. Endi bildirishnomalaringiz uchun koeffitsiyentlar shu kalit bilan soʻraladi.",
+	"apikey.removed":     "✅ Kalit oʻchirildi, bildirishnomalar botning umumiy kaliti bilan tekshiriladi.",
+	"apikey.malformed":   "❌ Bu WB API tokeniga oʻxshamaydi. Tokenni WB shaxsiy kabinetidan toʻliq nusxalang.",
+	"apikey.no_scope":    "❌ Tokenda «Yetkazib berishlar» toifasiga ruxsat yoʻq — usiz bot qabul koeffitsiyentlarini ololmaydi. Shu toifali token yarating.",
+	"apikey.expired":     "❌ Token muddati %s da tugagan. WB shaxsiy kabinetida yangi token yarating.",
+	"apikey.expires":     "%s gacha amal qiladi (MSK).",
+	"apikey.expiring":    "⚠️ Token muddati %s (MSK) da tugaydi — oldindan yangisini yarating va /apikey orqali yuboring.",
+
+	// Сроки ключей WB
+	"keys.shared_expiring": "⚠️ Botning umumiy WB API kaliti muddati %s (MSK) da tugaydi. WB_API_KEY ni almashtiring, aks holda bot koeffitsiyentlarni ololmaydi.",
+	"keys.shared_expired":  "⛔ Botning umumiy WB API kaliti muddati %s (MSK) da tugadi. Bot koeffitsiyentlarni olmayapti — WB_API_KEY ni almashtiring.",
+	"keys.user_expiring":   "⚠️ WB API kalitingiz muddati %s (MSK) da tugaydi. Yangi token yarating va /apikey orqali yuboring, aks holda bildirishnomalar botning umumiy kaliti bilan tekshiriladi.",
+	"keys.user_expired":    "⛔ WB API kalitingiz muddati %s (MSK) da tugadi, bildirishnomalar botning umumiy kaliti bilan tekshiriladi. Yangi tokenni /apikey orqali yuboring.",
+
+	// Исходящие вебхуки
+	"webhook.disabled":      "Vebhuklar administrator tomonidan oʻchirilgan.",
+	"webhook.usage":         "Vebhuklar omborlaringizda qabul ochilishi, yopilishi va koeffitsiyent oʻzgarishi haqida JSON bilan POST soʻrov oladi.\n\n/webhook add <https://…> — omborlaringiz boʻyicha shaxsiy vebhuk\n/webhook team <https://…> — jamoa omborlari boʻyicha vebhuk\n/webhook log <ID> — soʻnggi yetkazishlar\n/webhook enable <ID> — qayta yoqish\n/webhook delete <ID> — oʻchirish",
+	"webhook.error":         "Vebhuklar bilan ishlashda xatolik. Keyinroq urinib koʻring.",
+	"webhook.empty":         "🔗 Hozircha vebhuklar yoʻq.",
+	"webhook.header":        "🔗 Vebhuklaringiz:",
+	"webhook.team_mark":     "(jamoa)",
+	"webhook.last_error":    "oʻchirilgan: %s",
+	"webhook.invalid_url":   "❌ Manzil https:// bilan boshlanishi va xostga ega boʻlishi kerak.",
+	"webhook.private_url":   "❌ Mahalliy tarmoq manzillari qoʻllab-quvvatlanmaydi.",
+	"webhook.limit.one":     "Koʻpi bilan %d ta vebhuk qoʻshish mumkin.",
+	"webhook.limit.other":   "Koʻpi bilan %d ta vebhuk qoʻshish mumkin.",
+	"webhook.added":         "✅ #%d vebhuk qoʻshildi.\n\nImzo siri (faqat bir marta koʻrsatiladi):\n%s\n\nX-Webhook-Signature sarlavhasi = sha256=HMAC-SHA256(sir, X-Webhook-Timestamp + \".\" + soʻrov tanasi).",
+	"webhook.deleted":       "✅ #%d vebhuk oʻchirildi.",
+	"webhook.enabled":       "✅ #%d vebhuk yoqildi.",
+	"webhook.not_found":     "#%d vebhuk topilmadi.",
+	"webhook.log_empty":     "#%d vebhukka hali yetkazishlar boʻlmagan.",
+	"webhook.log_header":    "📜 #%d soʻnggi yetkazishlari (%s):",
+	"webhook.log_delivered": "✅ %d",
+	"webhook.log_dead":      "❌ %s",
+	"webhook.log_pending":   "⏳ urinishlar: %d",
+	"webhook.auto_disabled": "⛔ #%d vebhuk (%s) bir necha muvaffaqiyatsiz yetkazishdan soʻng oʻchirildi. Soʻnggi xato: %s\n\nManzilni tuzating va qayta yoqing: /webhook enable %[1]d",
+
+	// Токены REST API
+	"apitoken.disabled":    "REST API administrator tomonidan oʻchirilgan.",
+	"apitoken.usage":       "Token Authorization: Bearer <token> sarlavhasida yuboriladi.\n\n/apitoken new [nomi] — token chiqarish\n/apitoken revoke <ID> — bekor qilish",
+	"apitoken.error":       "Tokenlar bilan ishlashda xatolik. Keyinroq urinib koʻring.",
+	"apitoken.empty":       "🔐 Hozircha API tokenlari yoʻq.",
+	"apitoken.header":      "🔐 API tokenlaringiz:",
+	"apitoken.item":        "chiqarilgan %s, oxirgi foydalanish: %s",
+	"apitoken.never":       "hech qachon",
+	"apitoken.limit.one":   "Koʻpi bilan %d ta token chiqarish mumkin. Keraksizini bekor qiling: /apitoken revoke <ID>",
+	"apitoken.limit.other": "Koʻpi bilan %d ta token chiqarish mumkin. Keraksizini bekor qiling: /apitoken revoke <ID>",
+	"apitoken.created":     "✅ #%d token chiqarildi. Uni saqlab qoʻying — u qayta koʻrsatilmaydi:\n\n%s",
+	"apitoken.revoked":     "✅ #%d token bekor qilindi.",
+	"apitoken.not_found":   "#%d token topilmadi.",
+
+	// Изменения подписок через REST API
+	"api.subscription_added":   "🔌 REST API orqali %s ombori (ID: %d) kuzatuvga qoʻshildi.",
+	"api.subscription_updated": "🔌 REST API orqali %s ombori (ID: %d) shartlari oʻzgartirildi.",
+	"api.subscription_removed": "🔌 REST API orqali %s ombori (ID: %d) kuzatuvdan olib tashlandi.",
+	"api.subscription_filter":  "Koeffitsiyent %d gacha boʻlganda xabar beriladi, yetkazib berish turi: %s.",
+	"api.box_type_any":         "istalgan",
+
+	// Администрирование
+	"admin.stats":               "📊 Statistika\n\n👤 Foydalanuvchilar: %d (botni bloklaganlar: %d, banlanganlar: %d)\n📦 Obunalar: %d\n🔔 Bugungi bildirishnomalar: %d yetkazildi, %d yetkazilmadi\n🌐 WB API: %d soʻrov, %d xato (%.1f%%)\n⏱ Tekshirish oraligʻi: %s\n\n🏆 Mashhur omborlar:\n",
+	"admin.stats_error":         "Statistikani yigʻishda xatolik.",
+	"admin.top_empty":           "hozircha obunalar yoʻq\n",
+	"admin.broadcast_usage":     "Foydalanish: /broadcast <matn>",
+	"admin.broadcast_preview":   "📣 Tarqatma shunday koʻrinadi. Qabul qiluvchilar: %d.",
+	"admin.broadcast_send":      "✅ Yuborish",
+	"admin.broadcast_cancel":    "✖️ Bekor qilish",
+	"admin.broadcast_error":     "Tarqatmani tayyorlashda xatolik.",
+	"admin.broadcast_queued":    "✅ Tarqatma navbatga qoʻyildi: %d ta qabul qiluvchi.",
+	"admin.broadcast_cancelled": "Tarqatma bekor qilindi.",
+	"admin.broadcast_expired":   "Tarqatma qoralamasi topilmadi: u allaqachon yuborilgan yoki bekor qilingan.",
+	"admin.user_usage":          "Foydalanish: /user <Telegram ID>",
+	"admin.user_not_found":      "%d foydalanuvchi topilmadi.",
+	"admin.user_error":          "Foydalanuvchini olishda xatolik.",
+	"admin.user_info":           "👤 %s (ID: %d)\nTil: %s\nOxirgi tashrif: %s\nBotni bloklagan: %s\nBanlangan: %s\nJamoa: %s\n\n📦 Omborlar:\n",
+	"admin.yes":                 "ha",
+	"admin.no":                  "yoʻq",
+	"admin.ban_usage":           "Foydalanish: /%s <Telegram ID>",
+	"admin.ban_admin":           "Administratorni banlab boʻlmaydi.",
+	"admin.ban_error":           "Banni oʻzgartirishda xatolik.",
+	"admin.banned":              "⛔ %d foydalanuvchi banlandi: bot unga javob bermaydi va bildirishnoma yubormaydi.",
+	"admin.unbanned":            "✅ %d foydalanuvchi bandan chiqarildi.",
+	"admin.interval_current":    "⏱ Koeffitsiyentlarni tekshirish oraligʻi: %s.\nBarcha nusxalar uchun oʻzgartirish: /pollinterval <soniya> (%d dan %d gacha)",
+	"admin.interval_invalid":    "Oraliq %d dan %d gacha soniya boʻlishi kerak.",
+	"admin.interval_set":        "✅ Koeffitsiyentlarni tekshirish oraligʻi: %s. Yetakchi nusxa uni keyingi tekshiruvdan qoʻllaydi.",
+	"admin.interval_error":      "Oraliqni saqlashda xatolik.",
+}
@@ -0,0 +1,164 @@
+// Package leader — выбор ведущего экземпляра бота через аренду в общей базе.
+// Ведущий опрашивает WB и отправляет уведомления, остальные только отвечают на команды.
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/metrics"
+)
+
+// leaseName — название аренды ведущего в базе
+const leaseName = "leader"
+
+// LeaseStore — хранилище аренд
+type LeaseStore interface {
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(name, holder string) error
+}
+
+// Elector — участие экземпляра в выборах ведущего
+type Elector struct {
+	store LeaseStore
+	id    string
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	term    context.Context    // Срок лидерства, nil — экземпляр не ведущий
+	end     context.CancelFunc // Завершает term
+	changed chan struct{}      // Закрывается при смене лидерства
+}
+
+// New — участник выборов с ID экземпляра id (пусто — сгенерировать) и сроком аренды ttl
+func New(store LeaseStore, id string, ttl time.Duration) *Elector {
+	if id == "" {
+		id = InstanceID()
+	}
+	return &Elector{store: store, id: id, ttl: ttl, changed: make(chan struct{})}
+}
+
+// InstanceID — ID экземпляра: имя хоста, PID и случайный суффикс
+func InstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "bot"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// ID — ID этого экземпляра
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// Run — получать и продлевать аренду каждые ttl/3 до отмены ctx, затем отпустить её
+func (e *Elector) Run(ctx context.Context) error {
+	var renewed time.Time // Последнее успешное продление
+	for {
+		acquired, err := e.store.AcquireLease(leaseName, e.id, e.ttl)
+		switch {
+		case err != nil:
+			// База недоступна: лидерство держим, пока аренда точно не истекла у других
+			slog.Error("Ошибка продления аренды ведущего", "instance", e.id, logging.Err(err))
+			if time.Since(renewed) > e.ttl/2 {
+				e.setLeader(false)
+			}
+		case acquired:
+			renewed = time.Now()
+			e.setLeader(true)
+		default:
+			e.setLeader(false)
+		}
+
+		if !lifecycle.Sleep(ctx, e.ttl/3) {
+			break
+		}
+	}
+
+	if e.setLeader(false) {
+		if err := e.store.ReleaseLease(leaseName, e.id); err != nil {
+			slog.Error("Ошибка освобождения аренды ведущего", "instance", e.id, logging.Err(err))
+		}
+	}
+	return nil
+}
+
+// setLeader — сменить состояние лидерства, возвращает прежнее
+func (e *Elector) setLeader(leader bool) (was bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	was = e.term != nil
+	if was == leader {
+		return was
+	}
+	if leader {
+		e.term, e.end = context.WithCancel(context.Background())
+		slog.Info("Экземпляр стал ведущим", "instance", e.id)
+	} else {
+		e.end()
+		e.term, e.end = nil, nil
+		slog.Info("Экземпляр перестал быть ведущим", "instance", e.id)
+	}
+	metrics.SetLeader(leader)
+	close(e.changed)
+	e.changed = make(chan struct{})
+	return was
+}
+
+// current — срок текущего лидерства (nil — не ведущий) и канал следующей смены
+func (e *Elector) current() (context.Context, <-chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.term, e.changed
+}
+
+// IsLeader — ведущий ли сейчас этот экземпляр
+func (e *Elector) IsLeader() bool {
+	term, _ := e.current()
+	return term != nil
+}
+
+// Lead — обернуть задачу так, чтобы она работала только на ведущем: запускается при получении
+// лидерства и останавливается отменой контекста при его потере
+func (e *Elector) Lead(task func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			term, changed := e.current()
+			if term == nil {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-changed:
+					continue
+				}
+			}
+
+			runCtx, cancel := context.WithCancel(ctx)
+			stop := context.AfterFunc(term, cancel)
+			err := task(runCtx)
+			stop()
+			cancel()
+
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case term.Err() != nil:
+				// Лидерство потеряно — ждём следующего
+				continue
+			}
+			return err
+		}
+	}
+}
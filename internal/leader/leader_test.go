@@ -0,0 +1,162 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeases — аренда, исход которой задаёт тест
+type fakeLeases struct {
+	mu       sync.Mutex
+	acquire  bool
+	err      error
+	released []string
+}
+
+func (f *fakeLeases) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.acquire, f.err
+}
+
+func (f *fakeLeases) ReleaseLease(name, holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released = append(f.released, holder)
+	return nil
+}
+
+func (f *fakeLeases) set(acquire bool, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.acquire, f.err = acquire, err
+}
+
+const (
+	testTTL = 30 * time.Millisecond // Продление каждые 10 мс
+	waitFor = time.Second           // Сколько ждать смены лидерства
+)
+
+// wait — дождаться события из ch или провалить тест
+func wait(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(waitFor):
+		t.Fatalf("не дождались: %s", what)
+	}
+}
+
+func TestLeadFollowsLeadership(t *testing.T) {
+	leases := &fakeLeases{}
+	e := New(leases, "test", testTTL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runDone := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(runDone)
+	}()
+
+	started := make(chan struct{}, 1)
+	stopped := make(chan struct{}, 1)
+	leadDone := make(chan error, 1)
+	go func() {
+		leadDone <- e.Lead(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			stopped <- struct{}{}
+			return nil
+		})(ctx)
+	}()
+
+	// Аренду держит другой экземпляр — задача не запускается
+	select {
+	case <-started:
+		t.Fatal("задача запущена без лидерства")
+	case <-time.After(5 * testTTL):
+	}
+	if e.IsLeader() {
+		t.Fatal("экземпляр ведущий без аренды")
+	}
+
+	leases.set(true, nil)
+	wait(t, started, "запуск задачи после получения аренды")
+	if !e.IsLeader() {
+		t.Error("экземпляр не ведущий после получения аренды")
+	}
+
+	leases.set(false, nil)
+	wait(t, stopped, "остановка задачи после потери аренды")
+	if e.IsLeader() {
+		t.Error("экземпляр ведущий после потери аренды")
+	}
+
+	// Лидерство вернулось — задача запускается снова
+	leases.set(true, nil)
+	wait(t, started, "повторный запуск задачи")
+
+	cancel()
+	wait(t, stopped, "остановка задачи при остановке бота")
+	wait(t, runDone, "завершение выборов")
+	if err := <-leadDone; err != nil {
+		t.Errorf("Lead вернул %v", err)
+	}
+	leases.mu.Lock()
+	defer leases.mu.Unlock()
+	if len(leases.released) != 1 || leases.released[0] != "test" {
+		t.Errorf("аренда отпущена %v, ожидалось один раз экземпляром test", leases.released)
+	}
+}
+
+func TestRunDropsLeadershipWhenStoreFails(t *testing.T) {
+	leases := &fakeLeases{acquire: true}
+	e := New(leases, "test", testTTL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	stopped := make(chan struct{}, 1)
+	go e.Run(ctx)
+	go e.Lead(func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+		return nil
+	})(ctx)
+	wait(t, started, "запуск задачи")
+
+	// База недоступна: аренда могла истечь, и задачу останавливают, чтобы не было двух ведущих
+	leases.set(false, errors.New("база недоступна"))
+	wait(t, stopped, "остановка задачи при недоступной базе")
+	if e.IsLeader() {
+		t.Error("экземпляр остался ведущим при недоступной базе")
+	}
+}
+
+func TestLeadReturnsTaskError(t *testing.T) {
+	leases := &fakeLeases{acquire: true}
+	e := New(leases, "test", testTTL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx)
+
+	failure := errors.New("сбой задачи")
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Lead(func(ctx context.Context) error { return failure })(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, failure) {
+			t.Errorf("Lead вернул %v, ожидалось %v", err, failure)
+		}
+	case <-time.After(waitFor):
+		t.Fatal("Lead не вернул ошибку задачи")
+	}
+}
@@ -0,0 +1,149 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	minRestartDelay = time.Second     // Пауза перед первым перезапуском упавшей задачи
+	maxRestartDelay = time.Minute     // Максимальная пауза между перезапусками
+	stableRunPeriod = 5 * time.Minute // После стольких минут без падений пауза сбрасывается
+)
+
+// ErrShutdownTimeout — задачи не завершились за отведённое время
+var ErrShutdownTimeout = errors.New("фоновые задачи не завершились вовремя")
+
+// Manager — жизненный цикл бота: сигналы остановки и фоновые задачи под присмотром
+type Manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // Первая ошибка, из-за которой остановлен бот
+}
+
+// New — создать менеджер, контекст которого отменяется по SIGINT/SIGTERM
+func New() *Manager {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	return &Manager{ctx: ctx, cancel: cancel}
+}
+
+// Context — контекст, отменяемый при остановке
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Done — канал, закрываемый при остановке
+func (m *Manager) Done() <-chan struct{} {
+	return m.ctx.Done()
+}
+
+// Fail — остановить бот из-за ошибки, после которой работать дальше нельзя, например занятого порта.
+// Остановка идёт так же, как по сигналу; первую такую ошибку возвращает Err.
+func (m *Manager) Fail(err error) {
+	m.mu.Lock()
+	if m.err == nil {
+		m.err = err
+	}
+	m.mu.Unlock()
+	m.cancel()
+}
+
+// Err — ошибка, переданная в Fail, nil — остановка по сигналу
+func (m *Manager) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Go — запустить фоновую задачу. Паника или ошибка логируются, задача перезапускается
+// с нарастающей паузой, пока менеджер не остановлен. Задача должна вернуться после отмены ctx.
+func (m *Manager) Go(name string, fn func(ctx context.Context) error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		var delay time.Duration
+		for {
+			started := time.Now()
+			err := run(m.ctx, fn)
+			if m.ctx.Err() != nil {
+				slog.Info("Задача остановлена", "task", name)
+				return
+			}
+
+			delay = restartDelay(delay, time.Since(started))
+			if err == nil {
+				slog.Warn("Задача завершилась, перезапуск", "task", name, "delay", delay)
+			} else {
+				slog.Error("Задача упала, перезапуск", "task", name, "delay", delay, "error", err)
+			}
+
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+	}()
+}
+
+// restartDelay — пауза перед перезапуском задачи, проработавшей ran после предыдущей паузы prev:
+// удваивается до maxRestartDelay и сбрасывается, если задача проработала дольше stableRunPeriod
+func restartDelay(prev, ran time.Duration) time.Duration {
+	if prev == 0 || ran > stableRunPeriod {
+		return minRestartDelay
+	}
+	return min(prev*2, maxRestartDelay)
+}
+
+// run — выполнить задачу, превращая панику в ошибку
+func run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Паника в фоновой задаче", "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+			err = fmt.Errorf("паника: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Shutdown — отменить контекст и дождаться завершения задач, но не дольше timeout
+func (m *Manager) Shutdown(timeout time.Duration) error {
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrShutdownTimeout
+	}
+}
+
+// Sleep — пауза, прерываемая отменой контекста. Возвращает false, если контекст отменён.
+func Sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
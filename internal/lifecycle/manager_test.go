@@ -0,0 +1,98 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRestartDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		prev time.Duration
+		ran  time.Duration
+		want time.Duration
+	}{
+		{"первое падение", 0, time.Millisecond, minRestartDelay},
+		{"удвоение", minRestartDelay, time.Millisecond, 2 * minRestartDelay},
+		{"дальше удваивается", 8 * time.Second, time.Second, 16 * time.Second},
+		{"не больше максимума", 40 * time.Second, time.Second, maxRestartDelay},
+		{"на максимуме", maxRestartDelay, time.Second, maxRestartDelay},
+		{"сброс после стабильной работы", maxRestartDelay, stableRunPeriod + time.Second, minRestartDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restartDelay(tt.prev, tt.ran); got != tt.want {
+				t.Errorf("restartDelay(%v, %v) = %v, ожидалось %v", tt.prev, tt.ran, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoRestartsAfterPanic(t *testing.T) {
+	m := New()
+	var calls atomic.Int32
+	restarted := make(chan struct{})
+	m.Go("test", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			panic("сбой")
+		}
+		close(restarted)
+		<-ctx.Done()
+		return nil
+	})
+
+	select {
+	case <-restarted:
+	case <-time.After(minRestartDelay + 2*time.Second):
+		t.Fatal("задача не перезапущена после паники")
+	}
+	if err := m.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("запусков %d, ожидалось 2", n)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	m := New()
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	m.Go("stuck", func(ctx context.Context) error {
+		close(started)
+		<-release // Отмену контекста задача не замечает
+		return nil
+	})
+	<-started
+
+	begin := time.Now()
+	if err := m.Shutdown(50 * time.Millisecond); !errors.Is(err, ErrShutdownTimeout) {
+		t.Fatalf("Shutdown = %v, ожидалось %v", err, ErrShutdownTimeout)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("Shutdown ждал %v вместо таймаута", elapsed)
+	}
+}
+
+func TestFail(t *testing.T) {
+	m := New()
+	first := errors.New("порт занят")
+	m.Fail(first)
+	m.Fail(errors.New("вторая ошибка"))
+
+	select {
+	case <-m.Done():
+	default:
+		t.Fatal("Fail не остановил менеджер")
+	}
+	if err := m.Err(); err != first {
+		t.Errorf("Err = %v, ожидалась первая ошибка %v", err, first)
+	}
+	if err := m.Shutdown(time.Second); err != nil {
+		t.Errorf("Shutdown без задач: %v", err)
+	}
+}
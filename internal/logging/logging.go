@@ -0,0 +1,186 @@
+// Package logging — структурированный журнал на log/slog с вычищением секретов.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Имена полей, общие для всего журнала
+const (
+	KeyUserID      = "user_id"
+	KeyChatID      = "chat_id"
+	KeyWarehouseID = "warehouse_id"
+	KeyRequestID   = "request_id"
+	KeyTickID      = "tick_id"
+	KeyError       = "error"
+)
+
+// redacted — чем заменяются секреты
+const redacted = "[REDACTED]"
+
+// secretPatterns — секреты, которые вычищаются, даже если их не передали в Setup:
+// токены ботов Telegram (в том числе внутри URL api.telegram.org/bot<token>/...) и JWT-ключи WB
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\d{6,}:[A-Za-z0-9_-]{30,}`),
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+}
+
+var (
+	mu      sync.RWMutex
+	secrets = make(map[string]struct{})
+)
+
+// AddSecret — вычищать значение из всех последующих записей журнала, пока его не уберут RemoveSecret
+func AddSecret(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		// Короткие строки слишком легко совпадут с обычным текстом
+		if len(v) >= 8 {
+			secrets[v] = struct{}{}
+		}
+	}
+}
+
+// RemoveSecret — перестать вычищать значение: ключ удалён или заменён, в журнал он больше не попадёт
+func RemoveSecret(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		delete(secrets, v)
+	}
+}
+
+// Redact — заменить в строке известные секреты и всё, что похоже на токены
+func Redact(s string) string {
+	mu.RLock()
+	for secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	mu.RUnlock()
+
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+// Setup — настроить slog по умолчанию: уровень debug/info/warn/error, формат json или text.
+// Стандартный log тоже пишет через него, поэтому сторонние библиотеки попадают в тот же журнал.
+func Setup(w io.Writer, level, format string) *slog.Logger {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	logger := slog.New(&redactHandler{next: handler})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// redactHandler — обёртка над обработчиком, вычищающая секреты из сообщения и строковых полей
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(clean)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr — вычистить секреты из значения поля; ошибки и Stringer приводятся к строке
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		clean := make([]any, len(group))
+		for i, ga := range group {
+			clean[i] = redactAttr(ga)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, Redact(x.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, Redact(x.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// Err — поле с ошибкой
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+// BotLogger — журнал для библиотеки telegram-bot-api, её отладочный вывод идёт на уровне debug
+type BotLogger struct {
+	Logger *slog.Logger
+}
+
+func (l BotLogger) Println(v ...interface{}) {
+	l.Logger.Debug(strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+func (l BotLogger) Printf(format string, v ...interface{}) {
+	l.Logger.Debug(fmt.Sprintf(format, v...))
+}
+
+// StdLogger — стандартный *log.Logger поверх slog, например для http.Server.ErrorLog
+func StdLogger(logger *slog.Logger, level slog.Level) *log.Logger {
+	return slog.NewLogLogger(logger.Handler(), level)
+}
+
+// User — поле с Telegram ID пользователя
+func User(id int64) slog.Attr {
+	return slog.Int64(KeyUserID, id)
+}
+
+// Chat — поле с ID чата
+func Chat(id int64) slog.Attr {
+	return slog.Int64(KeyChatID, id)
+}
+
+// Warehouse — поле с ID склада
+func Warehouse(id int) slog.Attr {
+	return slog.Int(KeyWarehouseID, id)
+}
@@ -0,0 +1,108 @@
+// Package metrics — метрики Prometheus и проверки состояния бота.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "postavkin"
+
+var (
+	// WBRequests — запросы к WB API по эндпоинту и коду ответа ("error" — ошибка сети)
+	WBRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "wb_requests_total",
+		Help:      "Запросы к WB API по эндпоинту и статусу.",
+	}, []string{"endpoint", "status"})
+
+	// WBRequestDuration — длительность запросов к WB API
+	WBRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "wb_request_duration_seconds",
+		Help:      "Длительность запросов к WB API.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	// LastCoefficientsFetch — время последнего успешного получения коэффициентов
+	LastCoefficientsFetch = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "wb_last_coefficients_fetch_timestamp_seconds",
+		Help:      "Unix-время последнего успешного получения коэффициентов приёмки.",
+	})
+
+	// SchedulerTickDuration — длительность одной проверки всех подписок
+	SchedulerTickDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_tick_duration_seconds",
+		Help:      "Длительность одной проверки коэффициентов по всем подпискам.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	})
+
+	// RecipientsEvaluated — получатели (чаты и участники команд), проверенные планировщиком
+	RecipientsEvaluated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduler_recipients_evaluated_total",
+		Help:      "Получатели, чьи склады проверил планировщик.",
+	})
+
+	// Messages — исходящие сообщения по виду (alert, broadcast) и результату:
+	// queued — поставлено в очередь, sent — доставлено, error — неудачная попытка, dead — отправка прекращена
+	Messages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_total",
+		Help:      "Исходящие сообщения по виду и результату отправки.",
+	}, []string{"kind", "result"})
+
+	// SlotEvents — события по складам: открытие и закрытие приёмки, смена коэффициента, новый склад
+	SlotEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "slot_events_total",
+		Help:      "События по складам по виду.",
+	}, []string{"kind"})
+
+	// EventsDropped — события, отброшенные из-за заполненной очереди получателя
+	EventsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_dropped_total",
+		Help:      "События, не доставленные получателю из-за заполненной очереди.",
+	}, []string{"sink"})
+
+	// WebhookDeliveries — попытки доставки вебхуков по результату:
+	// delivered — получатель ответил 2xx, error — будет повтор, dead — доставка прекращена
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Попытки доставки исходящих вебхуков по результату.",
+	}, []string{"result"})
+
+	// Leader — 1, если экземпляр ведущий
+	Leader = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "leader",
+		Help:      "1, если экземпляр ведущий и опрашивает WB.",
+	})
+
+	// DBQueryDuration — длительность запросов к базе по операции и таблице
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Длительность запросов к базе данных.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+)
+
+// QueueDepth — зарегистрировать метрику глубины очереди, значение считается при каждом опросе
+func QueueDepth(count func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Сообщения в очереди, ожидающие отправки.",
+	}, func() float64 {
+		n, err := count()
+		if err != nil {
+			return -1
+		}
+		return float64(n)
+	})
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"postavkinBot/internal/logging"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	lastFetchUnixNano   atomic.Int64 // Последнее успешное получение коэффициентов
+	leaderSinceUnixNano atomic.Int64 // Когда экземпляр стал ведущим, 0 — не ведущий
+)
+
+// SetLeader — экземпляр стал ведущим или перестал им быть.
+// Коэффициенты получает только ведущий, поэтому проверки состояния учитывают лидерство.
+func SetLeader(leader bool) {
+	if leader {
+		leaderSinceUnixNano.Store(time.Now().UnixNano())
+		Leader.Set(1)
+	} else {
+		leaderSinceUnixNano.Store(0)
+		Leader.Set(0)
+	}
+}
+
+// leaderSince — с какого момента экземпляр ведущий, ok=false если не ведущий
+func leaderSince() (time.Time, bool) {
+	n := leaderSinceUnixNano.Load()
+	if n == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
+
+// MarkCoefficientsFetched — коэффициенты приёмки успешно получены от WB
+func MarkCoefficientsFetched() {
+	now := time.Now()
+	lastFetchUnixNano.Store(now.UnixNano())
+	LastCoefficientsFetch.Set(float64(now.Unix()))
+}
+
+// lastFetch — время последнего успешного получения коэффициентов, ok=false если его ещё не было
+func lastFetch() (time.Time, bool) {
+	n := lastFetchUnixNano.Load()
+	if n == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
+
+// Server — HTTP-сервер с /metrics, /healthz и /readyz
+type Server struct {
+	server       *http.Server
+	readyMaxAge  time.Duration
+	healthMaxAge time.Duration
+}
+
+// NewServer — сервер на адресе listen.
+// /readyz отвечает 200, только если коэффициенты получены не позже readyMaxAge назад.
+// /healthz отвечает 503, если коэффициентов нет дольше healthMaxAge (с момента, когда экземпляр
+// стал ведущим, или последнего успешного получения) — процесс, скорее всего, завис и его пора перезапустить.
+// Резервный экземпляр коэффициенты не получает и на обе проверки отвечает 200, пока отвечает на команды.
+func NewServer(listen string, readyMaxAge, healthMaxAge time.Duration) *Server {
+	s := &Server{readyMaxAge: readyMaxAge, healthMaxAge: healthMaxAge}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+
+	s.server = &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          logging.StdLogger(slog.Default(), slog.LevelWarn),
+	}
+	return s
+}
+
+// Run — обслуживать запросы до отмены ctx
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("Метрики доступны", "listen", s.server.Addr, "path", "/metrics")
+		errCh <- s.server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	since, leader := leaderSince()
+	if !leader {
+		fmt.Fprintln(w, "ok (standby)")
+		return
+	}
+	if last, ok := lastFetch(); ok && last.After(since) {
+		since = last
+	}
+	if age := time.Since(since); age > s.healthMaxAge {
+		http.Error(w, fmt.Sprintf("коэффициенты не обновлялись %s", age.Round(time.Second)), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, _ *http.Request) {
+	if _, leader := leaderSince(); !leader {
+		fmt.Fprintln(w, "ok (standby)")
+		return
+	}
+	last, ok := lastFetch()
+	if !ok {
+		http.Error(w, "коэффициенты ещё не получены", http.StatusServiceUnavailable)
+		return
+	}
+	if age := time.Since(last); age > s.readyMaxAge {
+		http.Error(w, fmt.Sprintf("коэффициенты не обновлялись %s", age.Round(time.Second)), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
@@ -0,0 +1,96 @@
+// Package ratelimit — ограничение частоты запросов в скользящем окне: апдейты пользователей
+// в боте и запросы по токенам REST API.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Result — решение по запросу
+type Result struct {
+	Allowed    bool          // Запрос можно выполнить
+	First      bool          // Отказ первый в окне: о нём стоит сказать, о следующих — нет
+	RetryAfter time.Duration // Через сколько повторить, если отказано
+}
+
+// Limiter — не больше limit запросов по одному ключу за скользящее окно period
+type Limiter[K comparable] struct {
+	limit  int
+	period time.Duration
+
+	mu      sync.Mutex
+	keys    map[K]*window
+	sweepAt time.Time // Когда в следующий раз удалить ключи, молчащие дольше period
+}
+
+// window — выполненные запросы ключа за последний period
+type window struct {
+	hits   []time.Time
+	warned bool // После последнего выполненного запроса уже был отказ
+}
+
+// New — ограничение limit запросов за period
+func New[K comparable](limit int, period time.Duration) *Limiter[K] {
+	return &Limiter[K]{
+		limit:  limit,
+		period: period,
+		keys:   make(map[K]*window),
+	}
+}
+
+// Allow — можно ли выполнить запрос по ключу key в момент now. Отказы в окне не учитываются:
+// ключ, который продолжает слать запросы, не отодвигает свой лимит.
+func (l *Limiter[K]) Allow(key K, now time.Time) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	w, ok := l.keys[key]
+	if !ok {
+		w = &window{}
+		l.keys[key] = w
+	}
+	recent := w.hits[:0]
+	for _, t := range w.hits {
+		if now.Sub(t) < l.period {
+			recent = append(recent, t)
+		}
+	}
+	w.hits = recent
+
+	if len(recent) < l.limit {
+		w.hits = append(w.hits, now)
+		w.warned = false
+		return Result{Allowed: true}
+	}
+
+	r := Result{
+		First: !w.warned,
+		// Запрос пройдёт, когда в окне останется меньше limit запросов
+		RetryAfter: recent[len(recent)-l.limit].Add(l.period).Sub(now),
+	}
+	w.warned = true
+	return r
+}
+
+// Len — сколько ключей сейчас в памяти
+func (l *Limiter[K]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.keys)
+}
+
+// sweep — раз в period забыть ключи, у которых в окне не осталось запросов
+func (l *Limiter[K]) sweep(now time.Time) {
+	if now.Before(l.sweepAt) {
+		return
+	}
+	for key, w := range l.keys {
+		if len(w.hits) == 0 || now.Sub(w.hits[len(w.hits)-1]) >= l.period {
+			delete(l.keys, key)
+		}
+	}
+	l.sweepAt = now.Add(l.period)
+}
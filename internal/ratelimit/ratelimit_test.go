@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	l := New[int64](2, time.Minute)
+	now := time.Now()
+
+	steps := []struct {
+		at         time.Duration
+		allowed    bool
+		first      bool
+		retryAfter time.Duration
+	}{
+		{0, true, false, 0},
+		{time.Second, true, false, 0},
+		{2 * time.Second, false, true, 58 * time.Second},  // Первый отказ — с предупреждением
+		{3 * time.Second, false, false, 57 * time.Second}, // Дальше молча
+		{time.Minute + 2*time.Second, true, false, 0},
+	}
+	for i, step := range steps {
+		r := l.Allow(1, now.Add(step.at))
+		if r.Allowed != step.allowed || r.First != step.first || r.RetryAfter != step.retryAfter {
+			t.Errorf("шаг %d: %+v, ожидалось allowed=%v first=%v retry_after=%v", i, r, step.allowed, step.first, step.retryAfter)
+		}
+	}
+}
+
+func TestAllowSlidingWindow(t *testing.T) {
+	l := New[uint](2, time.Minute)
+	now := time.Now()
+
+	l.Allow(1, now)
+	l.Allow(1, now.Add(50*time.Second))
+	// В фиксированном окне минуты счётчик бы обнулился, в скользящем первый запрос ещё учитывается
+	if r := l.Allow(1, now.Add(55*time.Second)); r.Allowed || r.RetryAfter != 5*time.Second {
+		t.Errorf("через 55 секунд: %+v, ожидался отказ на 5 секунд", r)
+	}
+	// Отказы не занимают место в окне
+	if r := l.Allow(1, now.Add(time.Minute)); !r.Allowed {
+		t.Errorf("через минуту: %+v, первый запрос должен был выйти из окна", r)
+	}
+	if r := l.Allow(1, now.Add(time.Minute+time.Second)); r.Allowed || !r.First || r.RetryAfter != 49*time.Second {
+		t.Errorf("через минуту и секунду: %+v, ожидался первый отказ после выполненного запроса", r)
+	}
+}
+
+func TestAllowPerKey(t *testing.T) {
+	l := New[int64](1, time.Minute)
+	now := time.Now()
+
+	if r := l.Allow(1, now); !r.Allowed {
+		t.Fatal("первый запрос ключа 1 отброшен")
+	}
+	if r := l.Allow(2, now); !r.Allowed {
+		t.Error("лимит ключа 1 не должен касаться ключа 2")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	l := New[int64](1, time.Minute)
+	now := time.Now()
+
+	for id := int64(1); id <= 3; id++ {
+		l.Allow(id, now)
+	}
+	l.Allow(4, now.Add(2*time.Minute))
+
+	if n := l.Len(); n != 1 {
+		t.Errorf("после окна осталось %d ключей, ожидался 1", n)
+	}
+}
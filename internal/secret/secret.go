@@ -0,0 +1,75 @@
+// Package secret — шифрование секретов пользователей (ключей WB API) мастер-ключом из настроек.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// KeySize — длина мастер-ключа в байтах (AES-256)
+const KeySize = 32
+
+// ErrDecrypt — шифротекст повреждён или зашифрован другим ключом
+var ErrDecrypt = errors.New("не удалось расшифровать секрет")
+
+// ParseKey — мастер-ключ из 32 байт в base64 или hex
+func ParseKey(s string) ([]byte, error) {
+	if key, err := hex.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("мастер-ключ должен быть %d байтами в base64 или hex", KeySize)
+}
+
+// Box — шифрование AES-256-GCM; каждый шифротекст со своим случайным nonce
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox — шифратор с мастер-ключом в base64 или hex
+func NewBox(masterKey string) (*Box, error) {
+	key, err := ParseKey(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Encrypt — зашифровать строку, результат — base64(nonce || шифротекст)
+func (b *Box) Encrypt(plain string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt — расшифровать результат Encrypt
+func (b *Box) Decrypt(encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	nonce, sealed := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import "time"
+
+// APIToken — токен пользователя для REST API; хранится только хэш
+type APIToken struct {
+	ID         uint   `gorm:"primaryKey"`
+	TelegramID int64  `gorm:"index"`
+	Name       string // Подпись, чтобы пользователь отличал токены
+	Prefix     string // Начало токена для показа в списке
+	Hash       string `gorm:"uniqueIndex"` // SHA-256 токена в hex
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// CreateAPIToken — сохранить новый токен
+func (s *Storage) CreateAPIToken(t *APIToken) error {
+	return s.db.Create(t).Error
+}
+
+// GetAPITokens — токены пользователя
+func (s *Storage) GetAPITokens(telegramID int64) ([]APIToken, error) {
+	var tokens []APIToken
+	err := s.db.Where("telegram_id = ?", telegramID).Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+// GetAPITokenByHash — токен по хэшу
+func (s *Storage) GetAPITokenByHash(hash string) (*APIToken, error) {
+	var t APIToken
+	if err := s.db.Where("hash = ?", hash).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteAPIToken — отозвать токен пользователя; ErrNotFound, если такого нет
+func (s *Storage) DeleteAPIToken(telegramID int64, id uint) error {
+	result := s.db.Where("telegram_id = ? AND id = ?", telegramID, id).Delete(&APIToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// TouchAPIToken — отметить использование токена
+func (s *Storage) TouchAPIToken(id uint, at time.Time) error {
+	return s.db.Model(&APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
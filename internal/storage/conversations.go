@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Conversation — вопрос бота, на который отправитель ещё не ответил. Хранится в базе,
+// потому что ответ может прийти на другой экземпляр бота.
+type Conversation struct {
+	ChatID    int64     `gorm:"primaryKey;autoIncrement:false"`
+	SenderID  int64     `gorm:"primaryKey;autoIncrement:false"`
+	Command   string    // Команда, обработчик которой получит ответ
+	Args      string    // Аргументы исходной команды
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// SaveConversation — запомнить вопрос, заменив прежний вопрос тому же отправителю в чате
+func (s *Storage) SaveConversation(conv *Conversation) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "sender_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"command", "args", "expires_at"}),
+	}).Create(conv).Error
+}
+
+// TakeConversation — забрать вопрос отправителю: запись удаляется, повторный вызов вернёт ErrNotFound.
+// Истёкший вопрос тоже удаляется и считается ненайденным.
+func (s *Storage) TakeConversation(chatID, senderID int64, now time.Time) (*Conversation, error) {
+	var conv Conversation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chat_id = ? AND sender_id = ?", chatID, senderID).First(&conv).Error; err != nil {
+			return err
+		}
+		// Тот же ответ мог забрать другой экземпляр: отвечает тот, кто удалил запись
+		result := tx.Where("chat_id = ? AND sender_id = ? AND expires_at = ?", chatID, senderID, conv.ExpiresAt).
+			Delete(&Conversation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if now.After(conv.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &conv, nil
+}
+
+// DeleteConversation — отменить вопрос отправителю, если он был
+func (s *Storage) DeleteConversation(chatID, senderID int64) error {
+	return s.db.Where("chat_id = ? AND sender_id = ?", chatID, senderID).Delete(&Conversation{}).Error
+}
+
+// PruneConversations — удалить вопросы, истёкшие до before
+func (s *Storage) PruneConversations(before time.Time) (int64, error) {
+	result := s.db.Where("expires_at < ?", before).Delete(&Conversation{})
+	return result.RowsAffected, result.Error
+}
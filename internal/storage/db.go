@@ -1,43 +1,187 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // User — структура таблицы пользователей
 type User struct {
-	ID            uint   `gorm:"primaryKey"`  // Автоинкремент ID в базе
-	TelegramID    int64  `gorm:"uniqueIndex"` // Уникальный Telegram ID
-	Username      string // Никнейм пользователя
-	Warehouses    string // Список складов в виде строки (например "123,456")
+	ID            uint       `gorm:"primaryKey"`  // Автоинкремент ID в базе
+	TelegramID    int64      `gorm:"uniqueIndex"` // Уникальный Telegram ID
+	Username      string     // Никнейм пользователя
+	FirstName     string     // Имя из профиля Telegram
+	LastName      string     // Фамилия из профиля Telegram
+	LanguageCode  string     // Язык интерфейса Telegram, например "ru"
+	Language      string     // Язык, выбранный через /language (пусто — по LanguageCode)
+	LastSeenAt    *time.Time // Последнее обращение к боту
+	Blocked       bool       // Пользователь заблокировал бота, уведомления не отправляются
+	Banned        bool       `gorm:"not null;default:false"` // Заблокирован администратором: бот не отвечает и не присылает уведомления
+	Warehouses    string     // Устарело: склады перенесены в Subscription, поле читается только при миграции
+	CheckInterval int        // Устарело: интервал перенесён в Chat
+	QuietFrom     int        // Начало тихих часов (час по МСК), при QuietFrom == QuietTo тихие часы выключены
+	QuietTo       int        // Конец тихих часов (час по МСК)
+	MutedUntil    *time.Time // Уведомления выключены до этого момента
+	AlertTemplate string     // Свой шаблон уведомления (text/template), пусто — стандартный текст
+	AlertFormat   string     // Режим разметки шаблона: "", HTML или MarkdownV2
+	WBAPIKey      string     // Свой ключ WB API, зашифрованный мастер-ключом; пусто — общий ключ бота
+	// Когда владелец последний раз получил предупреждение о сроке этого ключа; сбрасывается при смене ключа
+	WBAPIKeyWarnedAt *time.Time
+}
+
+// Chat — чат, которому принадлежат подписки (личный, группа, супергруппа или канал)
+type Chat struct {
+	ID            uint   `gorm:"primaryKey"`
+	ChatID        int64  `gorm:"uniqueIndex"` // Telegram ID чата, совпадает с ID пользователя для личных чатов
+	Type          string // private, group, supergroup или channel
+	Title         string // Название группы/канала или никнейм пользователя
 	CheckInterval int    // Интервал проверки лимитов в минутах
+	Blocked       bool   // Бот заблокирован или удалён из чата, уведомления не отправляются
+}
+
+// UserProfile — данные пользователя из апдейта Telegram
+type UserProfile struct {
+	TelegramID   int64
+	Username     string
+	FirstName    string
+	LastName     string
+	LanguageCode string
 }
 
+// Subscription — склад в отслеживании у чата или команды
+type Subscription struct {
+	ID          uint  `gorm:"primaryKey"`
+	ChatID      int64 `gorm:"uniqueIndex:idx_subscription_owner"`                    // 0 для подписок команды
+	TeamID      uint  `gorm:"uniqueIndex:idx_subscription_owner;not null;default:0"` // 0 для подписок чата
+	WarehouseID int   `gorm:"uniqueIndex:idx_subscription_owner"`
+
+	// Уведомлять при коэффициенте от 0 до MaxCoefficient включительно. Без default в теге: GORM заменил бы
+	// нулевой порог «только бесплатно» на значение по умолчанию, поэтому порог всегда задаётся при создании
+	MaxCoefficient int `gorm:"not null"`
+	BoxTypeID      int `gorm:"not null;default:0"` // Только этот тип поставки, 0 — первый в ответе WB
+}
+
+// DefaultMaxCoefficient — порог коэффициента новых подписок: бесплатно или 1
+const DefaultMaxCoefficient = 1
+
+// ErrNotFound — запись не найдена
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// ErrAlreadySubscribed — чат уже отслеживает склад
+var ErrAlreadySubscribed = errors.New("склад уже отслеживается")
+
 // Storage — обёртка для базы данных
 type Storage struct {
 	db *gorm.DB
 }
 
-// NewStorage — инициализация базы данных
-func NewStorage(dbPath string) (*Storage, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+// memoryDatabases — счётчик для имён баз в памяти, чтобы экземпляры не видели данные друг друга
+var memoryDatabases atomic.Int64
+
+// NewStorage — инициализация базы данных по DSN:
+//   - postgres://… , postgresql://… или "host=… dbname=…" — PostgreSQL, общая база для нескольких реплик;
+//   - :memory: — SQLite в памяти, пропадает при закрытии (для тестов);
+//   - sqlite://путь или просто путь к файлу — SQLite.
+//
+// Перед работой применяются недостающие миграции; если схема новее бота — ErrSchemaTooNew.
+func NewStorage(dsn string) (*Storage, error) {
+	s, err := Open(dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	// Миграция таблицы пользователей
-	err = db.AutoMigrate(&User{})
+	if _, err := s.MigrateUp(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open — подключиться к базе без миграций, для команды migrate
+func Open(dsn string) (*Storage, error) {
+	db, err := gorm.Open(dialector(dsn), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := registerMetrics(db); err != nil {
+		return nil, err
+	}
 	return &Storage{db: db}, nil
 }
 
+// NewMemory — хранилище в памяти на SQLite, для тестов
+func NewMemory() (*Storage, error) {
+	return NewStorage(":memory:")
+}
+
+// dialector — драйвер GORM по DSN
+func dialector(dsn string) gorm.Dialector {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"), strings.Contains(dsn, "host="):
+		return postgres.Open(dsn)
+	case dsn == ":memory:":
+		// Общий кэш, чтобы все соединения пула видели одну базу
+		return sqlite.Open(fmt.Sprintf("file:memdb%d?mode=memory&cache=shared", memoryDatabases.Add(1)))
+	default:
+		return sqlite.Open(strings.TrimPrefix(dsn, "sqlite://"))
+	}
+}
+
+// Close — закрыть соединение с базой данных
+func (s *Storage) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// migrateUserWarehouses — перенос складов из старого поля User.Warehouses в личные чаты (миграция 2)
+func migrateUserWarehouses(tx *gorm.DB) error {
+	var users []userV1
+	if err := tx.Where("warehouses <> ''").Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		chat := chatV1{
+			ChatID:        user.TelegramID,
+			Type:          "private",
+			Title:         user.Username,
+			CheckInterval: user.CheckInterval,
+		}
+		if err := tx.Where(chatV1{ChatID: user.TelegramID}).FirstOrCreate(&chat).Error; err != nil {
+			return fmt.Errorf("перенос складов пользователя %d: %w", user.TelegramID, err)
+		}
+
+		for _, idStr := range strings.Split(user.Warehouses, ",") {
+			var id int
+			if _, err := fmt.Sscanf(idStr, "%d", &id); err != nil {
+				continue
+			}
+			sub := subscriptionV1{ChatID: user.TelegramID, WarehouseID: id}
+			if err := tx.Where(sub).FirstOrCreate(&sub).Error; err != nil {
+				return fmt.Errorf("перенос складов пользователя %d: %w", user.TelegramID, err)
+			}
+		}
+
+		if err := tx.Model(&userV1{}).Where("id = ?", user.ID).Update("warehouses", "").Error; err != nil {
+			return fmt.Errorf("перенос складов пользователя %d: %w", user.TelegramID, err)
+		}
+	}
+
+	return nil
+}
+
 // CreateUser — создать нового пользователя
 func (s *Storage) CreateUser(telegramID int64, username string) error {
 	user := User{
@@ -49,6 +193,54 @@ func (s *Storage) CreateUser(telegramID int64, username string) error {
 	return s.db.Create(&user).Error
 }
 
+// TouchUser — зарегистрировать пользователя или обновить его профиль и время последнего обращения.
+// Возвращает признак того, что пользователь создан впервые.
+func (s *Storage) TouchUser(profile UserProfile) (*User, bool, error) {
+	now := time.Now()
+
+	var user User
+	err := s.db.Where("telegram_id = ?", profile.TelegramID).First(&user).Error
+	if IsNotFound(err) {
+		user = User{
+			TelegramID:    profile.TelegramID,
+			Username:      profile.Username,
+			FirstName:     profile.FirstName,
+			LastName:      profile.LastName,
+			LanguageCode:  profile.LanguageCode,
+			LastSeenAt:    &now,
+			CheckInterval: 5, // По умолчанию 5 минут интервал
+		}
+		if err := s.db.Create(&user).Error; err != nil {
+			return nil, false, err
+		}
+		return &user, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = s.db.Model(&user).Updates(map[string]interface{}{
+		"username":      profile.Username,
+		"first_name":    profile.FirstName,
+		"last_name":     profile.LastName,
+		"language_code": profile.LanguageCode,
+		"last_seen_at":  now,
+		"blocked":       false,
+	}).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &user, false, nil
+}
+
+// SetUserBlocked — отметить, что пользователь заблокировал бота (или разблокировал)
+func (s *Storage) SetUserBlocked(telegramID int64, blocked bool) error {
+	return s.db.Model(&User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("blocked", blocked).Error
+}
+
 // GetUserByTelegramID — получить пользователя по Telegram ID
 func (s *Storage) GetUserByTelegramID(telegramID int64) (*User, error) {
 	var user User
@@ -66,84 +258,197 @@ func (s *Storage) UserExists(telegramID int64) (bool, error) {
 	return count > 0, err
 }
 
-// AddWarehouseToUser — добавить ID склада пользователю
-func (s *Storage) AddWarehouseToUser(telegramID int64, warehouseID int) error {
-	var user User
-	if err := s.db.Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
-		return err
-	}
-
-	warehouses := strings.Split(user.Warehouses, ",")
-	for _, w := range warehouses {
-		if w == fmt.Sprint(warehouseID) {
-			// Уже есть
-			return nil
-		}
+// GetAllUsers — получить всех пользователей
+func (s *Storage) GetAllUsers() ([]User, error) {
+	var users []User
+	if err := s.db.Find(&users).Error; err != nil {
+		return nil, err
 	}
+	return users, nil
+}
 
-	if user.Warehouses != "" {
-		user.Warehouses += ","
+// EnsureChat — создать чат или обновить его тип и название
+func (s *Storage) EnsureChat(chatID int64, chatType, title string) error {
+	chat := Chat{
+		ChatID:        chatID,
+		Type:          chatType,
+		Title:         title,
+		CheckInterval: 5, // По умолчанию 5 минут интервал
 	}
-	user.Warehouses += fmt.Sprint(warehouseID)
+	return s.db.Where(Chat{ChatID: chatID}).
+		Assign(map[string]interface{}{"type": chatType, "title": title, "blocked": false}).
+		FirstOrCreate(&chat).Error
+}
 
-	return s.db.Save(&user).Error
+// SetChatBlocked — отметить, что бот заблокирован в чате (или снова доступен)
+func (s *Storage) SetChatBlocked(chatID int64, blocked bool) error {
+	return s.db.Model(&Chat{}).
+		Where("chat_id = ?", chatID).
+		Update("blocked", blocked).Error
 }
 
-// GetUserWarehouses — получить список складов пользователя
-func (s *Storage) GetUserWarehouses(telegramID int64) ([]int, error) {
-	var user User
-	if err := s.db.Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
+// GetChat — получить чат по Telegram ID
+func (s *Storage) GetChat(chatID int64) (*Chat, error) {
+	var chat Chat
+	if err := s.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
 		return nil, err
 	}
+	return &chat, nil
+}
 
-	var warehouseIDs []int
-	if user.Warehouses == "" {
-		return warehouseIDs, nil
+// GetAllChats — получить все чаты, где бот не заблокирован
+func (s *Storage) GetAllChats() ([]Chat, error) {
+	var chats []Chat
+	if err := s.db.Where("blocked = ?", false).Find(&chats).Error; err != nil {
+		return nil, err
 	}
+	return chats, nil
+}
 
-	ids := strings.Split(user.Warehouses, ",")
-	for _, idStr := range ids {
-		var id int
-		fmt.Sscanf(idStr, "%d", &id)
-		warehouseIDs = append(warehouseIDs, id)
+// AddSubscription — добавить склад в отслеживание чата
+func (s *Storage) AddSubscription(chatID int64, warehouseID int) error {
+	sub := Subscription{ChatID: chatID, WarehouseID: warehouseID}
+	return s.db.Where(sub).Attrs(Subscription{MaxCoefficient: DefaultMaxCoefficient}).FirstOrCreate(&sub).Error
+}
+
+// CreateSubscription — добавить склад в отслеживание чата сразу с порогом и типом поставки одной вставкой;
+// ErrAlreadySubscribed, если чат уже отслеживает склад
+func (s *Storage) CreateSubscription(sub *Subscription) error {
+	sub.TeamID = 0
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(sub)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadySubscribed
 	}
+	return nil
+}
 
-	return warehouseIDs, nil
+// RemoveSubscription — удалить склад из отслеживания чата
+func (s *Storage) RemoveSubscription(chatID int64, warehouseID int) error {
+	return s.db.Where("chat_id = ? AND team_id = 0 AND warehouse_id = ?", chatID, warehouseID).
+		Delete(&Subscription{}).Error
 }
 
-// RemoveWarehouseFromUser — удалить ID склада у пользователя
-func (s *Storage) RemoveWarehouseFromUser(telegramID int64, warehouseID int) error {
-	var user User
-	if err := s.db.Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
-		return err
+// GetChatSubscription — подписка чата на склад
+func (s *Storage) GetChatSubscription(chatID int64, warehouseID int) (*Subscription, error) {
+	var sub Subscription
+	err := s.db.Where("chat_id = ? AND team_id = 0 AND warehouse_id = ?", chatID, warehouseID).First(&sub).Error
+	if err != nil {
+		return nil, err
 	}
+	return &sub, nil
+}
 
-	warehouses := strings.Split(user.Warehouses, ",")
-	newWarehouses := []string{}
+// GetChatSubscriptions — подписки чата вместе с порогами
+func (s *Storage) GetChatSubscriptions(chatID int64) ([]Subscription, error) {
+	var subscriptions []Subscription
+	if err := s.db.Where("chat_id = ? AND team_id = 0", chatID).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
 
-	for _, w := range warehouses {
-		if w != fmt.Sprint(warehouseID) && w != "" {
-			newWarehouses = append(newWarehouses, w)
-		}
+// SetSubscriptionFilter — изменить порог коэффициента и тип поставки подписки чата
+func (s *Storage) SetSubscriptionFilter(chatID int64, warehouseID, maxCoefficient, boxTypeID int) error {
+	result := s.db.Model(&Subscription{}).
+		Where("chat_id = ? AND team_id = 0 AND warehouse_id = ?", chatID, warehouseID).
+		Updates(map[string]interface{}{"max_coefficient": maxCoefficient, "box_type_id": boxTypeID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
 	}
+	return nil
+}
 
-	user.Warehouses = strings.Join(newWarehouses, ",")
+// GetChatWarehouses — получить список складов чата
+func (s *Storage) GetChatWarehouses(chatID int64) ([]int, error) {
+	var warehouseIDs []int
+	err := s.db.Model(&Subscription{}).
+		Where("chat_id = ? AND team_id = 0", chatID).
+		Order("id").
+		Pluck("warehouse_id", &warehouseIDs).Error
+	return warehouseIDs, err
+}
 
-	return s.db.Save(&user).Error
+// GetAllSubscriptions — получить подписки всех чатов и команд одним запросом
+func (s *Storage) GetAllSubscriptions() ([]Subscription, error) {
+	var subscriptions []Subscription
+	if err := s.db.Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
 }
 
-// GetAllUsers — получить всех пользователей
-func (s *Storage) GetAllUsers() ([]User, error) {
-	var users []User
-	if err := s.db.Find(&users).Error; err != nil {
+// GetWarehouseSubscriptions — подписки чатов и команд на склад вместе с порогами
+func (s *Storage) GetWarehouseSubscriptions(warehouseID int) ([]Subscription, error) {
+	var subscriptions []Subscription
+	if err := s.db.Where("warehouse_id = ?", warehouseID).Order("id").Find(&subscriptions).Error; err != nil {
 		return nil, err
 	}
-	return users, nil
+	return subscriptions, nil
+}
+
+// UpdateCheckInterval — изменить интервал проверки для чата
+func (s *Storage) UpdateCheckInterval(chatID int64, interval int) error {
+	return s.db.Model(&Chat{}).
+		Where("chat_id = ?", chatID).
+		Update("check_interval", interval).Error
 }
 
-// UpdateCheckInterval — изменить интервал проверки для пользователя
-func (s *Storage) UpdateCheckInterval(telegramID int64, interval int) error {
+// SetQuietHours — установить тихие часы пользователя (from == to выключает их)
+func (s *Storage) SetQuietHours(telegramID int64, from, to int) error {
 	return s.db.Model(&User{}).
 		Where("telegram_id = ?", telegramID).
-		Update("check_interval", interval).Error
+		Updates(map[string]interface{}{"quiet_from": from, "quiet_to": to}).Error
+}
+
+// SetMutedUntil — выключить уведомления пользователя до указанного момента (nil включает их)
+func (s *Storage) SetMutedUntil(telegramID int64, until *time.Time) error {
+	return s.db.Model(&User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("muted_until", until).Error
+}
+
+// IsNotFound — проверка, что ошибка означает отсутствие записи
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// SetUserLanguage — сохранить язык бота, выбранный пользователем (пустая строка — как в Telegram)
+func (s *Storage) SetUserLanguage(telegramID int64, lang string) error {
+	return s.db.Model(&User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("language", lang).Error
+}
+
+// SetUserAlertTemplate — сохранить шаблон уведомлений пользователя (пустой шаблон возвращает стандартный)
+func (s *Storage) SetUserAlertTemplate(telegramID int64, template, parseMode string) error {
+	return s.db.Model(&User{}).
+		Where("telegram_id = ?", telegramID).
+		Updates(map[string]interface{}{
+			"alert_template": template,
+			"alert_format":   parseMode,
+		}).Error
+}
+
+// SetUserWBAPIKey — сохранить зашифрованный ключ WB API пользователя (пустая строка удаляет ключ)
+func (s *Storage) SetUserWBAPIKey(telegramID int64, encrypted string) error {
+	return s.db.Model(&User{}).
+		Where("telegram_id = ?", telegramID).
+		Updates(map[string]interface{}{
+			"wb_api_key":           encrypted,
+			"wb_api_key_warned_at": nil,
+		}).Error
+}
+
+// SetUserWBAPIKeyWarned — отметить предупреждение о сроке ключа. Отметка ставится, только если
+// ключ не сменили с момента проверки: encrypted — ключ, о котором предупредили.
+func (s *Storage) SetUserWBAPIKeyWarned(telegramID int64, encrypted string, at time.Time) error {
+	return s.db.Model(&User{}).
+		Where("telegram_id = ? AND wb_api_key = ?", telegramID, encrypted).
+		Update("wb_api_key_warned_at", at).Error
 }
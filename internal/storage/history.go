@@ -0,0 +1,58 @@
+package storage
+
+import "time"
+
+// SlotEvent — запись истории: изменение коэффициента склада (ChatID = 0)
+// или уведомление об открытой приёмке, поставленное подписчику
+type SlotEvent struct {
+	ID            uint   `gorm:"primaryKey"`
+	Kind          string `gorm:"index"`
+	ChatID        int64  `gorm:"index"`              // Получатель уведомления, 0 — событие склада
+	TeamID        uint   `gorm:"not null;default:0"` // Команда, по чьему списку ушло уведомление
+	WarehouseID   int    `gorm:"index"`
+	WarehouseName string
+	BoxTypeID     int
+	Date          string // Дата приёмки из ответа WB
+	Coefficient   int
+	Previous      *int // Предыдущий коэффициент, nil — неизвестен
+	AllowUnload   bool
+	CreatedAt     time.Time `gorm:"index"`
+}
+
+// RecordSlotEvent — сохранить событие в историю
+func (s *Storage) RecordSlotEvent(e *SlotEvent) error {
+	return s.db.Create(e).Error
+}
+
+// PruneSlotEvents — удалить события, записанные до before
+func (s *Storage) PruneSlotEvents(before time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", before).Delete(&SlotEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// SlotEventFilter — выборка из истории
+type SlotEventFilter struct {
+	ChatID       int64  // Получатель уведомлений, 0 — события складов
+	WarehouseIDs []int  // Пусто — все склады
+	Kind         string // Пусто — все виды
+	Since        time.Time
+	Limit        int
+}
+
+// GetSlotEvents — события из истории по фильтру, новые первыми
+func (s *Storage) GetSlotEvents(filter SlotEventFilter) ([]SlotEvent, error) {
+	query := s.db.Where("chat_id = ?", filter.ChatID)
+	if len(filter.WarehouseIDs) > 0 {
+		query = query.Where("warehouse_id IN ?", filter.WarehouseIDs)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+
+	var events []SlotEvent
+	err := query.Order("id DESC").Limit(filter.Limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lease — аренда роли: пока она не истекла, роль принадлежит держателю
+type Lease struct {
+	Name      string `gorm:"primaryKey"` // Название роли, например "leader"
+	Holder    string // ID экземпляра бота
+	ExpiresAt time.Time
+}
+
+// AcquireLease — получить или продлить аренду до now+ttl. Успешно, если аренда свободна,
+// истекла или уже принадлежит holder; иначе false без ошибки.
+func (s *Storage) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&Lease{}).
+			Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+			Updates(map[string]interface{}{"holder": holder, "expires_at": now.Add(ttl)})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			acquired = true
+			return nil
+		}
+
+		// Записи ещё нет; если её успела создать другая реплика, вставка ничего не сделает
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)})
+		acquired = result.RowsAffected > 0
+		return result.Error
+	})
+	return acquired, err
+}
+
+// ReleaseLease — отпустить аренду, если она принадлежит holder
+func (s *Storage) ReleaseLease(name, holder string) error {
+	return s.db.Where("name = ? AND holder = ?", name, holder).Delete(&Lease{}).Error
+}
@@ -0,0 +1,553 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSchemaTooNew — база мигрирована более новой версией бота, работать с ней нельзя
+var ErrSchemaTooNew = errors.New("схема базы новее, чем поддерживает эта версия бота")
+
+// migrationLockID — ключ advisory-блокировки PostgreSQL, чтобы реплики не мигрировали базу одновременно
+const migrationLockID = 7_301_202_401
+
+// Migration — версия схемы базы. Up и Down выполняются в транзакции вместе с записью в schema_version.
+// Модели в миграциях — снимки на момент версии: текущие структуры со временем меняются.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationStatus — миграция и время её применения (nil — не применена)
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaVersion — запись о применённой миграции
+type schemaVersion struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string // Название миграции на момент применения
+	AppliedAt time.Time
+}
+
+func (schemaVersion) TableName() string { return "schema_version" }
+
+// migrations — все миграции по возрастанию версии; новые добавляются только в конец
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "начальная схема",
+		Up: func(tx *gorm.DB) error {
+			// Старый уникальный индекс (chat_id, warehouse_id) не пускает подписки команд
+			if tx.Migrator().HasIndex(&subscriptionV1{}, "idx_subscription") {
+				if err := tx.Migrator().DropIndex(&subscriptionV1{}, "idx_subscription"); err != nil {
+					return err
+				}
+			}
+			// Базы, созданные до миграций, уже содержат эти таблицы — AutoMigrate только добавит недостающее
+			return tx.AutoMigrate(&userV1{}, &chatV1{}, &subscriptionV1{}, &teamV1{}, &teamMemberV1{}, &teamInviteV1{}, &outboundMessageV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&outboundMessageV1{}, &teamInviteV1{}, &teamMemberV1{}, &teamV1{}, &subscriptionV1{}, &chatV1{}, &userV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "перенос складов из users.warehouses в подписки",
+		Up:      migrateUserWarehouses,
+		Down: func(tx *gorm.DB) error {
+			// Подписки остаются: старое поле больше не читается
+			return nil
+		},
+	},
+	{
+		Version: 3,
+		Name:    "ключи WB API пользователей",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&userV3{}, "WBAPIKey")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&userV3{}, "WBAPIKey")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "аренды для выбора ведущего экземпляра",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&leaseV4{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&leaseV4{})
+		},
+	},
+	{
+		Version: 5,
+		Name:    "история событий по складам",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&slotEventV5{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&slotEventV5{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "исходящие вебхуки",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&webhookV6{}, &webhookDeliveryV6{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&webhookDeliveryV6{}, &webhookV6{})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "токены REST API",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&apiTokenV7{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiTokenV7{})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "пороги и тип поставки в подписках",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&subscriptionV8{}, "MaxCoefficient"); err != nil {
+				return err
+			}
+			return tx.Migrator().AddColumn(&subscriptionV8{}, "BoxTypeID")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&subscriptionV8{}, "BoxTypeID"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&subscriptionV8{}, "MaxCoefficient")
+		},
+	},
+	{
+		Version: 9,
+		Name:    "общие настройки экземпляров",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&settingV9{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&settingV9{})
+		},
+	},
+	{
+		Version: 10,
+		Name:    "вопросы бота, ожидающие ответа",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&conversationV10{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&conversationV10{})
+		},
+	},
+	{
+		Version: 11,
+		Name:    "снимок коэффициентов ведущего экземпляра",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&coefficientSnapshotV11{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&coefficientSnapshotV11{})
+		},
+	},
+	{
+		Version: 12,
+		Name:    "отметки об уведомлениях по открытым приёмкам",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&notificationV12{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&notificationV12{})
+		},
+	},
+	{
+		Version: 13,
+		Name:    "отметка о предупреждении про срок ключа WB",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&userV13{}, "WBAPIKeyWarnedAt")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&userV13{}, "WBAPIKeyWarnedAt")
+		},
+	},
+}
+
+// LatestVersion — последняя версия схемы, которую знает бот
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion — текущая версия схемы базы, 0 — миграции ещё не применялись
+func (s *Storage) SchemaVersion() (int, error) {
+	if err := s.db.AutoMigrate(&schemaVersion{}); err != nil {
+		return 0, err
+	}
+	return currentVersion(s.db)
+}
+
+// MigrateUp — применить все недостающие миграции, возвращает применённые
+func (s *Storage) MigrateUp() ([]Migration, error) {
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > LatestVersion() {
+		return nil, fmt.Errorf("%w: версия базы %d, бота %d", ErrSchemaTooNew, version, LatestVersion())
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		done, err := s.applyMigration(m, true)
+		if err != nil {
+			return applied, fmt.Errorf("миграция %d (%s): %w", m.Version, m.Name, err)
+		}
+		if done {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+// MigrateDown — откатить steps последних применённых миграций, возвращает откаченные
+func (s *Storage) MigrateDown(steps int) ([]Migration, error) {
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > LatestVersion() {
+		return nil, fmt.Errorf("%w: версия базы %d, бота %d", ErrSchemaTooNew, version, LatestVersion())
+	}
+
+	var reverted []Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		if m.Version > version {
+			continue
+		}
+		done, err := s.applyMigration(m, false)
+		if err != nil {
+			return reverted, fmt.Errorf("откат миграции %d (%s): %w", m.Version, m.Name, err)
+		}
+		if done {
+			reverted = append(reverted, m)
+		}
+	}
+	return reverted, nil
+}
+
+// MigrationStatus — все миграции бота с отметкой о применении
+func (s *Storage) MigrationStatus() ([]MigrationStatus, error) {
+	if err := s.db.AutoMigrate(&schemaVersion{}); err != nil {
+		return nil, err
+	}
+
+	var rows []schemaVersion
+	if err := s.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		status[i] = MigrationStatus{Migration: m}
+		if at, ok := appliedAt[m.Version]; ok {
+			status[i].AppliedAt = &at
+		}
+	}
+	return status, nil
+}
+
+// applyMigration — применить (up) или откатить миграцию в транзакции.
+// Версия перепроверяется под блокировкой: другая реплика могла успеть раньше, тогда done=false.
+func (s *Storage) applyMigration(m Migration, up bool) (done bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+				return err
+			}
+		}
+
+		var count int64
+		if err := tx.Model(&schemaVersion{}).Where("version = ?", m.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if up == (count > 0) {
+			return nil
+		}
+
+		if up {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			done = true
+			return tx.Create(&schemaVersion{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		}
+
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		done = true
+		return tx.Delete(&schemaVersion{}, "version = ?", m.Version).Error
+	})
+	return done && err == nil, err
+}
+
+// currentVersion — наибольшая применённая версия
+func currentVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Model(&schemaVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// ===== Снимки моделей для миграции 1 =====
+
+type userV1 struct {
+	ID            uint  `gorm:"primaryKey"`
+	TelegramID    int64 `gorm:"uniqueIndex"`
+	Username      string
+	FirstName     string
+	LastName      string
+	LanguageCode  string
+	Language      string
+	LastSeenAt    *time.Time
+	Blocked       bool
+	Banned        bool `gorm:"not null;default:false"`
+	Warehouses    string
+	CheckInterval int
+	QuietFrom     int
+	QuietTo       int
+	MutedUntil    *time.Time
+	AlertTemplate string
+	AlertFormat   string
+}
+
+func (userV1) TableName() string { return "users" }
+
+type chatV1 struct {
+	ID            uint  `gorm:"primaryKey"`
+	ChatID        int64 `gorm:"uniqueIndex"`
+	Type          string
+	Title         string
+	CheckInterval int
+	Blocked       bool
+}
+
+func (chatV1) TableName() string { return "chats" }
+
+type subscriptionV1 struct {
+	ID          uint  `gorm:"primaryKey"`
+	ChatID      int64 `gorm:"uniqueIndex:idx_subscription_owner"`
+	TeamID      uint  `gorm:"uniqueIndex:idx_subscription_owner;not null;default:0"`
+	WarehouseID int   `gorm:"uniqueIndex:idx_subscription_owner"`
+}
+
+func (subscriptionV1) TableName() string { return "subscriptions" }
+
+type teamV1 struct {
+	ID            uint `gorm:"primaryKey"`
+	Name          string
+	OwnerID       int64
+	AlertTemplate string
+	AlertFormat   string
+}
+
+func (teamV1) TableName() string { return "teams" }
+
+type teamMemberV1 struct {
+	ID         uint  `gorm:"primaryKey"`
+	TeamID     uint  `gorm:"index"`
+	TelegramID int64 `gorm:"uniqueIndex"`
+	Role       string
+}
+
+func (teamMemberV1) TableName() string { return "team_members" }
+
+type teamInviteV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Token     string `gorm:"uniqueIndex"`
+	TeamID    uint   `gorm:"index"`
+	Role      string
+	ExpiresAt time.Time
+}
+
+func (teamInviteV1) TableName() string { return "team_invites" }
+
+type outboundMessageV1 struct {
+	ID            uint   `gorm:"primaryKey"`
+	ChatID        int64  `gorm:"index"`
+	Kind          string `gorm:"index"`
+	Text          string
+	ParseMode     string
+	Status        string `gorm:"index:idx_outbound_due"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index:idx_outbound_due"`
+	LastError     string
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+func (outboundMessageV1) TableName() string { return "outbound_messages" }
+
+// ===== Снимки моделей для миграции 3 =====
+
+type userV3 struct {
+	WBAPIKey string
+}
+
+func (userV3) TableName() string { return "users" }
+
+// ===== Снимки моделей для миграции 4 =====
+
+type leaseV4 struct {
+	Name      string `gorm:"primaryKey"`
+	Holder    string
+	ExpiresAt time.Time
+}
+
+func (leaseV4) TableName() string { return "leases" }
+
+// ===== Снимки моделей для миграции 5 =====
+
+type slotEventV5 struct {
+	ID            uint   `gorm:"primaryKey"`
+	Kind          string `gorm:"index"`
+	ChatID        int64  `gorm:"index"`
+	TeamID        uint   `gorm:"not null;default:0"`
+	WarehouseID   int    `gorm:"index"`
+	WarehouseName string
+	BoxTypeID     int
+	Date          string
+	Coefficient   int
+	Previous      *int
+	AllowUnload   bool
+	CreatedAt     time.Time `gorm:"index"`
+}
+
+func (slotEventV5) TableName() string { return "slot_events" }
+
+// ===== Снимки моделей для миграции 6 =====
+
+type webhookV6 struct {
+	ID         uint  `gorm:"primaryKey"`
+	ChatID     int64 `gorm:"index"`
+	TeamID     uint  `gorm:"index;not null;default:0"`
+	CreatedBy  int64
+	URL        string
+	Secret     string
+	Enabled    bool
+	Failures   int
+	LastError  string
+	CreatedAt  time.Time
+	DisabledAt *time.Time
+}
+
+func (webhookV6) TableName() string { return "webhooks" }
+
+type webhookDeliveryV6 struct {
+	ID            uint `gorm:"primaryKey"`
+	WebhookID     uint `gorm:"index"`
+	Event         string
+	WarehouseID   int
+	Payload       string
+	Status        string `gorm:"index:idx_webhook_due"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index:idx_webhook_due"`
+	ResponseCode  int
+	LastError     string
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+func (webhookDeliveryV6) TableName() string { return "webhook_deliveries" }
+
+// ===== Снимки моделей для миграции 7 =====
+
+type apiTokenV7 struct {
+	ID         uint  `gorm:"primaryKey"`
+	TelegramID int64 `gorm:"index"`
+	Name       string
+	Prefix     string
+	Hash       string `gorm:"uniqueIndex"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+func (apiTokenV7) TableName() string { return "api_tokens" }
+
+// ===== Снимки моделей для миграции 8 =====
+
+type subscriptionV8 struct {
+	MaxCoefficient int `gorm:"not null;default:1"`
+	BoxTypeID      int `gorm:"not null;default:0"`
+}
+
+func (subscriptionV8) TableName() string { return "subscriptions" }
+
+// ===== Снимки моделей для миграции 9 =====
+
+type settingV9 struct {
+	Name      string `gorm:"primaryKey"`
+	Value     string
+	UpdatedAt time.Time
+}
+
+func (settingV9) TableName() string { return "settings" }
+
+// ===== Снимки моделей для миграции 10 =====
+
+type conversationV10 struct {
+	ChatID    int64 `gorm:"primaryKey;autoIncrement:false"`
+	SenderID  int64 `gorm:"primaryKey;autoIncrement:false"`
+	Command   string
+	Args      string
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+func (conversationV10) TableName() string { return "conversations" }
+
+// ===== Снимки моделей для миграции 11 =====
+
+type coefficientSnapshotV11 struct {
+	ID        uint `gorm:"primaryKey;autoIncrement:false"`
+	Data      string
+	FetchedAt time.Time
+}
+
+func (coefficientSnapshotV11) TableName() string { return "coefficient_snapshots" }
+
+// ===== Снимки моделей для миграции 12 =====
+
+type notificationV12 struct {
+	ChatID      int64 `gorm:"primaryKey;autoIncrement:false"`
+	TeamID      uint  `gorm:"primaryKey;autoIncrement:false"`
+	WarehouseID int   `gorm:"primaryKey;autoIncrement:false"`
+	NotifiedAt  time.Time
+}
+
+func (notificationV12) TableName() string { return "notifications" }
+
+// ===== Снимки моделей для миграции 13 =====
+
+type userV13 struct {
+	WBAPIKeyWarnedAt *time.Time
+}
+
+func (userV13) TableName() string { return "users" }
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Notification — когда получателю последний раз сообщили об открытой приёмке на складе. Запись живёт,
+// пока приёмка открыта, и хранится в базе, чтобы новый ведущий экземпляр не повторял уведомления раньше срока.
+type Notification struct {
+	ChatID      int64 `gorm:"primaryKey;autoIncrement:false"`
+	TeamID      uint  `gorm:"primaryKey;autoIncrement:false"` // Команда, по чьему списку уведомили, 0 — подписка чата
+	WarehouseID int   `gorm:"primaryKey;autoIncrement:false"`
+	NotifiedAt  time.Time
+}
+
+// GetNotifications — все отметки об уведомлениях, одним запросом на проверку
+func (s *Storage) GetNotifications() ([]Notification, error) {
+	var notifications []Notification
+	err := s.db.Find(&notifications).Error
+	return notifications, err
+}
+
+// SaveNotification — отметить уведомление, заменив прежнюю отметку
+func (s *Storage) SaveNotification(n *Notification) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "team_id"}, {Name: "warehouse_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"notified_at"}),
+	}).Create(n).Error
+}
+
+// DeleteNotification — приёмка закрылась: снять отметку
+func (s *Storage) DeleteNotification(chatID int64, teamID uint, warehouseID int) error {
+	return s.db.Where("chat_id = ? AND team_id = ? AND warehouse_id = ?", chatID, teamID, warehouseID).
+		Delete(&Notification{}).Error
+}
+
+// PruneNotifications — удалить отметки, не обновлявшиеся с before: подписку удалили при открытой приёмке
+func (s *Storage) PruneNotifications(before time.Time) (int64, error) {
+	result := s.db.Where("notified_at < ?", before).Delete(&Notification{})
+	return result.RowsAffected, result.Error
+}
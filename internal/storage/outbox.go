@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Статусы сообщений в очереди
+const (
+	MessagePending = "pending" // Ждёт отправки
+	MessageSent    = "sent"    // Доставлено в Telegram
+	MessageDead    = "dead"    // Не удалось доставить, отправка прекращена
+	MessageDraft   = "draft"   // Черновик рассылки, ждёт подтверждения администратора
+)
+
+// Виды сообщений в очереди
+const (
+	KindAlert     = "alert"     // Уведомление о лимите
+	KindBroadcast = "broadcast" // Рассылка администратора
+	KindSystem    = "system"    // Служебное уведомление, например об истекающем ключе WB
+)
+
+// OutboundMessage — исходящее сообщение в очереди на отправку
+type OutboundMessage struct {
+	ID            uint      `gorm:"primaryKey"`
+	ChatID        int64     `gorm:"index"`
+	Kind          string    `gorm:"index"` // alert, broadcast или system
+	Text          string    // Текст сообщения
+	ParseMode     string    // Режим разметки Telegram, пусто для обычного текста
+	Status        string    `gorm:"index:idx_outbound_due"` // pending, sent или dead
+	Attempts      int       // Сколько раз пытались отправить
+	NextAttemptAt time.Time `gorm:"index:idx_outbound_due"` // Не отправлять раньше этого момента
+	LastError     string    // Последняя ошибка отправки
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+// EnqueueMessage — поставить сообщение в очередь
+func (s *Storage) EnqueueMessage(chatID int64, kind, text, parseMode string) error {
+	return s.db.Create(&OutboundMessage{
+		ChatID:        chatID,
+		Kind:          kind,
+		Text:          text,
+		ParseMode:     parseMode,
+		Status:        MessagePending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// GetDueMessages — сообщения, которые пора отправлять: из каждого чата только первое в очереди,
+// чтобы отложенное после 429 или ошибки не обогнали более новые. Чаты из skip (ещё ждут паузы
+// между сообщениями) не выбираются, чтобы один занятый чат не занимал всю выборку.
+func (s *Storage) GetDueMessages(limit int, skip []int64) ([]OutboundMessage, error) {
+	heads := s.db.Model(&OutboundMessage{}).
+		Select("MIN(id)").
+		Where("status = ?", MessagePending).
+		Group("chat_id")
+
+	query := s.db.Where("id IN (?) AND next_attempt_at <= ?", heads, time.Now())
+	if len(skip) > 0 {
+		query = query.Where("chat_id NOT IN ?", skip)
+	}
+
+	var messages []OutboundMessage
+	err := query.Order("id").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// PruneMessages — удалить отправленные и недоставленные сообщения, созданные раньше before
+func (s *Storage) PruneMessages(before time.Time) (int64, error) {
+	result := s.db.Where("status IN ? AND created_at < ?", []string{MessageSent, MessageDead}, before).
+		Delete(&OutboundMessage{})
+	return result.RowsAffected, result.Error
+}
+
+// MarkMessageSent — сообщение доставлено
+func (s *Storage) MarkMessageSent(id uint) error {
+	return s.db.Model(&OutboundMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status": MessageSent, "sent_at": time.Now()}).Error
+}
+
+// RetryMessage — отложить повторную отправку; countAttempt учитывает попытку в лимите
+func (s *Storage) RetryMessage(id uint, next time.Time, lastError string, countAttempt bool) error {
+	updates := map[string]interface{}{
+		"next_attempt_at": next,
+		"last_error":      lastError,
+	}
+	if countAttempt {
+		updates["attempts"] = gorm.Expr("attempts + 1")
+	}
+	return s.db.Model(&OutboundMessage{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// MarkMessageDead — перенести сообщение в dead letter после окончательной ошибки
+func (s *Storage) MarkMessageDead(id uint, lastError string) error {
+	return s.db.Model(&OutboundMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     MessageDead,
+			"last_error": lastError,
+			"attempts":   gorm.Expr("attempts + 1"),
+		}).Error
+}
+
+// CountPendingMessages — глубина очереди
+func (s *Storage) CountPendingMessages() (int64, error) {
+	var count int64
+	err := s.db.Model(&OutboundMessage{}).Where("status = ?", MessagePending).Count(&count).Error
+	return count, err
+}
+
+// CountMessagesSince — сколько сообщений вида kind в статусе status создано начиная с since
+func (s *Storage) CountMessagesSince(kind, status string, since time.Time) (int64, error) {
+	var count int64
+	err := s.db.Model(&OutboundMessage{}).
+		Where("kind = ? AND status = ? AND created_at >= ?", kind, status, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateDraft — сохранить черновик рассылки до подтверждения
+func (s *Storage) CreateDraft(authorID int64, text string) (*OutboundMessage, error) {
+	draft := &OutboundMessage{
+		ChatID: authorID,
+		Kind:   KindBroadcast,
+		Text:   text,
+		Status: MessageDraft,
+	}
+	if err := s.db.Create(draft).Error; err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// TakeDraft — забрать черновик рассылки (он удаляется, повторное подтверждение вернёт ErrNotFound)
+func (s *Storage) TakeDraft(id uint) (*OutboundMessage, error) {
+	var draft OutboundMessage
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND status = ?", id, MessageDraft).First(&draft).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&draft).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
@@ -0,0 +1,178 @@
+package storage
+
+import "time"
+
+// Repository — все операции с базой, которые нужны боту.
+// Реализация — Storage поверх GORM: SQLite, PostgreSQL или SQLite в памяти для тестов (см. NewStorage).
+type Repository interface {
+	UserRepository
+	ChatRepository
+	SubscriptionRepository
+	TeamRepository
+	OutboxRepository
+	StatsRepository
+	LeaseRepository
+	HistoryRepository
+	WebhookRepository
+	APITokenRepository
+	SettingRepository
+	ConversationRepository
+	SnapshotRepository
+	NotificationRepository
+
+	// Close — закрыть соединение с базой
+	Close() error
+}
+
+// UserRepository — пользователи и их настройки
+type UserRepository interface {
+	CreateUser(telegramID int64, username string) error
+	TouchUser(profile UserProfile) (*User, bool, error)
+	SetUserBlocked(telegramID int64, blocked bool) error
+	SetUserBanned(telegramID int64, banned bool) error
+	GetUserByTelegramID(telegramID int64) (*User, error)
+	UserExists(telegramID int64) (bool, error)
+	GetAllUsers() ([]User, error)
+	SetQuietHours(telegramID int64, from, to int) error
+	SetMutedUntil(telegramID int64, until *time.Time) error
+	SetUserLanguage(telegramID int64, lang string) error
+	SetUserAlertTemplate(telegramID int64, template, parseMode string) error
+	SetUserWBAPIKey(telegramID int64, encrypted string) error
+	SetUserWBAPIKeyWarned(telegramID int64, encrypted string, at time.Time) error
+}
+
+// ChatRepository — чаты, в которые приходят уведомления
+type ChatRepository interface {
+	EnsureChat(chatID int64, chatType, title string) error
+	SetChatBlocked(chatID int64, blocked bool) error
+	GetChat(chatID int64) (*Chat, error)
+	GetAllChats() ([]Chat, error)
+	UpdateCheckInterval(chatID int64, interval int) error
+}
+
+// SubscriptionRepository — склады в отслеживании у чатов
+type SubscriptionRepository interface {
+	AddSubscription(chatID int64, warehouseID int) error
+	CreateSubscription(sub *Subscription) error
+	RemoveSubscription(chatID int64, warehouseID int) error
+	GetChatSubscription(chatID int64, warehouseID int) (*Subscription, error)
+	GetChatSubscriptions(chatID int64) ([]Subscription, error)
+	SetSubscriptionFilter(chatID int64, warehouseID, maxCoefficient, boxTypeID int) error
+	GetChatWarehouses(chatID int64) ([]int, error)
+	GetAllSubscriptions() ([]Subscription, error)
+	GetWarehouseSubscriptions(warehouseID int) ([]Subscription, error)
+}
+
+// TeamRepository — команды, участники, приглашения и общие склады
+type TeamRepository interface {
+	CreateTeam(name string, ownerID int64) (*Team, error)
+	GetTeam(teamID uint) (*Team, error)
+	GetAllTeams() ([]Team, error)
+	GetTeamMember(telegramID int64) (*TeamMember, error)
+	GetTeamMembers(teamID uint) ([]TeamMember, error)
+	GetAllTeamMembers() ([]TeamMember, error)
+	SetTeamMemberRole(teamID uint, telegramID int64, role string) error
+	RemoveTeamMember(teamID uint, telegramID int64) error
+	DeleteTeam(teamID uint) error
+	CreateTeamInvite(teamID uint, role string, ttl time.Duration) (string, error)
+	AcceptTeamInvite(token string, telegramID int64) (*Team, string, error)
+	AddTeamSubscription(teamID uint, warehouseID int) error
+	RemoveTeamSubscription(teamID uint, warehouseID int) error
+	GetTeamWarehouses(teamID uint) ([]int, error)
+	SetTeamAlertTemplate(teamID uint, template, parseMode string) error
+}
+
+// OutboxRepository — очередь исходящих сообщений и черновики рассылок
+type OutboxRepository interface {
+	EnqueueMessage(chatID int64, kind, text, parseMode string) error
+	GetDueMessages(limit int, skip []int64) ([]OutboundMessage, error)
+	PruneMessages(before time.Time) (int64, error)
+	MarkMessageSent(id uint) error
+	RetryMessage(id uint, next time.Time, lastError string, countAttempt bool) error
+	MarkMessageDead(id uint, lastError string) error
+	CountPendingMessages() (int64, error)
+	CountMessagesSince(kind, status string, since time.Time) (int64, error)
+	CreateDraft(authorID int64, text string) (*OutboundMessage, error)
+	TakeDraft(id uint) (*OutboundMessage, error)
+}
+
+// StatsRepository — выборки для администраторов
+type StatsRepository interface {
+	CountUsers() (UserCounts, error)
+	CountSubscriptions() (int64, error)
+	TopWarehouses(limit int) ([]WarehouseCount, error)
+	GetBroadcastRecipients() ([]int64, error)
+}
+
+// LeaseRepository — аренды ролей для выбора ведущего экземпляра
+type LeaseRepository interface {
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(name, holder string) error
+}
+
+// HistoryRepository — история событий по складам
+type HistoryRepository interface {
+	RecordSlotEvent(e *SlotEvent) error
+	GetSlotEvents(filter SlotEventFilter) ([]SlotEvent, error)
+	PruneSlotEvents(before time.Time) (int64, error)
+}
+
+// WebhookRepository — исходящие вебхуки и журнал их доставок
+type WebhookRepository interface {
+	CreateWebhook(w *Webhook) error
+	GetWebhook(id uint) (*Webhook, error)
+	GetWebhooks(chatID int64, teamID uint) ([]Webhook, error)
+	CountEnabledWebhooks() (int64, error)
+	DeleteWebhook(id uint) error
+	EnableWebhook(id uint) error
+	DisableWebhook(id uint, reason string) error
+	GetWebhooksForWarehouse(warehouseID int) ([]Webhook, error)
+	CreateWebhookDeliveries(deliveries []WebhookDelivery) error
+	GetDueWebhookDeliveries(limit int) ([]WebhookDelivery, error)
+	MarkWebhookDelivered(d *WebhookDelivery, code int) error
+	FailWebhookDelivery(d *WebhookDelivery, next *time.Time, code int, lastError string) (int, error)
+	DropWebhookDelivery(d *WebhookDelivery, lastError string) error
+	GetWebhookDeliveries(webhookID uint, limit int) ([]WebhookDelivery, error)
+	PruneWebhookDeliveries(before time.Time) (int64, error)
+}
+
+// APITokenRepository — токены REST API пользователей
+type APITokenRepository interface {
+	CreateAPIToken(t *APIToken) error
+	GetAPITokens(telegramID int64) ([]APIToken, error)
+	GetAPITokenByHash(hash string) (*APIToken, error)
+	DeleteAPIToken(telegramID int64, id uint) error
+	TouchAPIToken(id uint, at time.Time) error
+}
+
+// SettingRepository — настройки, общие для всех экземпляров
+type SettingRepository interface {
+	GetSetting(name string) (*Setting, error)
+	SetSetting(name, value string) error
+}
+
+// ConversationRepository — вопросы бота, ожидающие ответа
+type ConversationRepository interface {
+	SaveConversation(conv *Conversation) error
+	TakeConversation(chatID, senderID int64, now time.Time) (*Conversation, error)
+	DeleteConversation(chatID, senderID int64) error
+	PruneConversations(before time.Time) (int64, error)
+}
+
+// SnapshotRepository — последний ответ WB, общий для всех экземпляров
+type SnapshotRepository interface {
+	SaveCoefficientSnapshot(data string, fetchedAt time.Time) error
+	TouchCoefficientSnapshot(fetchedAt time.Time) error
+	GetCoefficientSnapshotTime() (time.Time, error)
+	GetCoefficientSnapshot() (*CoefficientSnapshot, error)
+}
+
+// NotificationRepository — отметки ведущего экземпляра об уведомлениях по открытым приёмкам
+type NotificationRepository interface {
+	GetNotifications() ([]Notification, error)
+	SaveNotification(n *Notification) error
+	DeleteNotification(chatID int64, teamID uint, warehouseID int) error
+	PruneNotifications(before time.Time) (int64, error)
+}
+
+var _ Repository = (*Storage)(nil)
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Setting — настройка, общая для всех экземпляров бота: её меняют командами, а не конфигурацией
+type Setting struct {
+	Name      string `gorm:"primaryKey"` // Например scheduler.check_interval
+	Value     string
+	UpdatedAt time.Time
+}
+
+// GetSetting — настройка по имени; ErrNotFound, если её ещё не задавали
+func (s *Storage) GetSetting(name string) (*Setting, error) {
+	var setting Setting
+	if err := s.db.Where("name = ?", name).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// SetSetting — сохранить настройку, перезаписав прежнее значение
+func (s *Storage) SetSetting(name, value string) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&Setting{Name: name, Value: value, UpdatedAt: time.Now()}).Error
+}
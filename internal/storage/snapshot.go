@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// snapshotID — единственная строка снимка: хранится только последний ответ WB
+const snapshotID = 1
+
+// CoefficientSnapshot — последний ответ WB по общему ключу, полученный ведущим экземпляром.
+// По нему отвечают /status и REST API на любом экземпляре, не обращаясь к WB.
+type CoefficientSnapshot struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement:false"`
+	Data      string // Коэффициенты в JSON
+	FetchedAt time.Time
+}
+
+// SaveCoefficientSnapshot — заменить снимок коэффициентов
+func (s *Storage) SaveCoefficientSnapshot(data string, fetchedAt time.Time) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "fetched_at"}),
+	}).Create(&CoefficientSnapshot{ID: snapshotID, Data: data, FetchedAt: fetchedAt}).Error
+}
+
+// TouchCoefficientSnapshot — WB вернул те же коэффициенты: обновить только время получения
+func (s *Storage) TouchCoefficientSnapshot(fetchedAt time.Time) error {
+	result := s.db.Model(&CoefficientSnapshot{}).Where("id = ?", snapshotID).Update("fetched_at", fetchedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetCoefficientSnapshotTime — когда получен сохранённый снимок, без самих коэффициентов
+func (s *Storage) GetCoefficientSnapshotTime() (time.Time, error) {
+	var snapshot CoefficientSnapshot
+	if err := s.db.Select("fetched_at").Where("id = ?", snapshotID).First(&snapshot).Error; err != nil {
+		return time.Time{}, err
+	}
+	return snapshot.FetchedAt, nil
+}
+
+// GetCoefficientSnapshot — сохранённый снимок; ErrNotFound, если ведущий его ещё не записал
+func (s *Storage) GetCoefficientSnapshot() (*CoefficientSnapshot, error) {
+	var snapshot CoefficientSnapshot
+	if err := s.db.Where("id = ?", snapshotID).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
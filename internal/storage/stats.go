@@ -0,0 +1,79 @@
+package storage
+
+// UserCounts — число пользователей по состояниям
+type UserCounts struct {
+	Total   int64
+	Blocked int64 // Заблокировали бота
+	Banned  int64 // Заблокированы администратором
+}
+
+// WarehouseCount — склад и число подписок на него
+type WarehouseCount struct {
+	WarehouseID int
+	Count       int64
+}
+
+// CountUsers — число пользователей всего, заблокировавших бота и забаненных
+func (s *Storage) CountUsers() (UserCounts, error) {
+	var counts UserCounts
+	err := s.db.Model(&User{}).
+		Select("COUNT(*) AS total, " +
+			"COALESCE(SUM(CASE WHEN blocked THEN 1 ELSE 0 END), 0) AS blocked, " +
+			"COALESCE(SUM(CASE WHEN banned THEN 1 ELSE 0 END), 0) AS banned").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// CountSubscriptions — число действующих подписок: чатов, где бот не заблокирован и владелец не забанен,
+// и команд, у которых есть хотя бы один такой участник
+func (s *Storage) CountSubscriptions() (int64, error) {
+	// У пользователей, созданных до появления флагов, в них NULL
+	inactiveUsers := s.db.Model(&User{}).Select("telegram_id").Where("blocked IS TRUE OR banned IS TRUE")
+	activeMembers := s.db.Model(&TeamMember{}).Select("team_id").Where("telegram_id NOT IN (?)", inactiveUsers)
+
+	var count int64
+	err := s.db.Model(&Subscription{}).
+		Where(s.db.
+			Where("team_id = 0 AND chat_id NOT IN (?) AND chat_id NOT IN (?)",
+				s.db.Model(&Chat{}).Select("chat_id").Where("blocked IS TRUE"), inactiveUsers).
+			Or("team_id <> 0 AND team_id IN (?)", activeMembers)).
+		Count(&count).Error
+	return count, err
+}
+
+// TopWarehouses — самые отслеживаемые склады
+func (s *Storage) TopWarehouses(limit int) ([]WarehouseCount, error) {
+	var top []WarehouseCount
+	err := s.db.Model(&Subscription{}).
+		Select("warehouse_id, COUNT(*) AS count").
+		Group("warehouse_id").
+		Order("count DESC, warehouse_id").
+		Limit(limit).
+		Scan(&top).Error
+	return top, err
+}
+
+// SetUserBanned — забанить или разбанить пользователя
+func (s *Storage) SetUserBanned(telegramID int64, banned bool) error {
+	result := s.db.Model(&User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("banned", banned)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetBroadcastRecipients — пользователи, которым можно отправить рассылку
+func (s *Storage) GetBroadcastRecipients() ([]int64, error) {
+	var ids []int64
+	err := s.db.Model(&User{}).
+		// У пользователей, созданных до появления флагов, в них NULL
+		Where("blocked IS NOT TRUE AND banned IS NOT TRUE").
+		Order("id").
+		Pluck("telegram_id", &ids).Error
+	return ids, err
+}
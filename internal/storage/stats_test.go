@@ -0,0 +1,61 @@
+package storage
+
+import "testing"
+
+func TestCountSubscriptions(t *testing.T) {
+	s, err := NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// 1 — активный личный чат, 2 — заблокировал бота, 3 — забанен, -100 — группа, откуда бота удалили
+	for _, id := range []int64{1, 2, 3} {
+		if err := s.CreateUser(id, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.EnsureChat(id, "private", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.EnsureChat(-100, "group", "Группа"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetUserBlocked(2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetUserBanned(3, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetChatBlocked(-100, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{1, 2, 3, -100} {
+		if err := s.AddSubscription(id, 507); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Команда активного участника учитывается, команда из одного забаненного — нет
+	active, err := s.CreateTeam("Активная", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	banned, err := s.CreateTeam("Забаненная", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{active.ID, banned.ID} {
+		if err := s.AddTeamSubscription(id, 507); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := s.CountSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("действующих подписок %d, ожидалось 2: чат 1 и команда %d", count, active.ID)
+	}
+}
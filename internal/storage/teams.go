@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Роли участников команды
+const (
+	RoleOwner  = "owner"  // Владелец: всё, включая приглашения и роли
+	RoleEditor = "editor" // Редактор: меняет общий список складов
+	RoleViewer = "viewer" // Наблюдатель: только получает уведомления
+)
+
+var (
+	// ErrAlreadyInTeam — пользователь уже состоит в команде
+	ErrAlreadyInTeam = errors.New("пользователь уже состоит в команде")
+	// ErrInviteExpired — приглашение не найдено или истекло
+	ErrInviteExpired = errors.New("приглашение недействительно или истекло")
+)
+
+// Team — команда с общим списком складов
+type Team struct {
+	ID            uint   `gorm:"primaryKey"`
+	Name          string // Название команды
+	OwnerID       int64  // Telegram ID владельца
+	AlertTemplate string // Шаблон уведомлений по общим складам, пусто — шаблон или стандартный текст участника
+	AlertFormat   string // Режим разметки шаблона: "", HTML или MarkdownV2
+}
+
+// TeamMember — участник команды
+type TeamMember struct {
+	ID         uint   `gorm:"primaryKey"`
+	TeamID     uint   `gorm:"index"`
+	TelegramID int64  `gorm:"uniqueIndex"` // Пользователь состоит не более чем в одной команде
+	Role       string // owner, editor или viewer
+}
+
+// TeamInvite — приглашение в команду по deep-link /start <token>
+type TeamInvite struct {
+	ID        uint   `gorm:"primaryKey"`
+	Token     string `gorm:"uniqueIndex"`
+	TeamID    uint   `gorm:"index"`
+	Role      string // Роль, с которой вступает приглашённый
+	ExpiresAt time.Time
+}
+
+// CanEdit — может ли участник менять общий список складов
+func (m TeamMember) CanEdit() bool {
+	return m.Role == RoleOwner || m.Role == RoleEditor
+}
+
+// IsValidRole — проверка допустимости роли для приглашения или назначения
+func IsValidRole(role string) bool {
+	return role == RoleEditor || role == RoleViewer
+}
+
+// CreateTeam — создать команду, создатель становится владельцем
+func (s *Storage) CreateTeam(name string, ownerID int64) (*Team, error) {
+	team := Team{Name: name, OwnerID: ownerID}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&TeamMember{}).Where("telegram_id = ?", ownerID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyInTeam
+		}
+
+		if err := tx.Create(&team).Error; err != nil {
+			return err
+		}
+		return tx.Create(&TeamMember{TeamID: team.ID, TelegramID: ownerID, Role: RoleOwner}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// GetTeam — получить команду по ID
+func (s *Storage) GetTeam(teamID uint) (*Team, error) {
+	var team Team
+	if err := s.db.First(&team, teamID).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetAllTeams — получить все команды
+func (s *Storage) GetAllTeams() ([]Team, error) {
+	var teams []Team
+	if err := s.db.Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// GetTeamMember — получить членство пользователя в команде
+func (s *Storage) GetTeamMember(telegramID int64) (*TeamMember, error) {
+	var member TeamMember
+	if err := s.db.Where("telegram_id = ?", telegramID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetTeamMembers — получить всех участников команды
+func (s *Storage) GetTeamMembers(teamID uint) ([]TeamMember, error) {
+	var members []TeamMember
+	if err := s.db.Where("team_id = ?", teamID).Order("id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetAllTeamMembers — получить участников всех команд одним запросом
+func (s *Storage) GetAllTeamMembers() ([]TeamMember, error) {
+	var members []TeamMember
+	if err := s.db.Order("id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// SetTeamMemberRole — изменить роль участника (кроме владельца)
+func (s *Storage) SetTeamMemberRole(teamID uint, telegramID int64, role string) error {
+	result := s.db.Model(&TeamMember{}).
+		Where("team_id = ? AND telegram_id = ? AND role <> ?", teamID, telegramID, RoleOwner).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RemoveTeamMember — исключить участника из команды, ErrNotFound — его нет в команде
+func (s *Storage) RemoveTeamMember(teamID uint, telegramID int64) error {
+	result := s.db.Where("team_id = ? AND telegram_id = ?", teamID, telegramID).
+		Delete(&TeamMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteTeam — удалить команду вместе с участниками, приглашениями, подписками и вебхуками
+func (s *Storage) DeleteTeam(teamID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Без этого диспетчер продолжал бы подписывать и отправлять события несуществующей команды
+		webhooks := tx.Model(&Webhook{}).Select("id").Where("team_id = ?", teamID)
+		if err := tx.Where("webhook_id IN (?)", webhooks).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&Webhook{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&TeamMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&TeamInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&Subscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Team{}, teamID).Error
+	})
+}
+
+// CreateTeamInvite — создать приглашение с ролью и сроком действия
+func (s *Storage) CreateTeamInvite(teamID uint, role string, ttl time.Duration) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	invite := TeamInvite{
+		Token:     hex.EncodeToString(buf),
+		TeamID:    teamID,
+		Role:      role,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.db.Create(&invite).Error; err != nil {
+		return "", err
+	}
+
+	return invite.Token, nil
+}
+
+// AcceptTeamInvite — вступить в команду по приглашению
+func (s *Storage) AcceptTeamInvite(token string, telegramID int64) (*Team, string, error) {
+	var team Team
+	var invite TeamInvite
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("token = ? AND expires_at > ?", token, time.Now()).First(&invite).Error
+		if IsNotFound(err) {
+			return ErrInviteExpired
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&team, invite.TeamID).Error; err != nil {
+			if IsNotFound(err) {
+				return ErrInviteExpired
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&TeamMember{}).Where("telegram_id = ?", telegramID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyInTeam
+		}
+
+		return tx.Create(&TeamMember{TeamID: team.ID, TelegramID: telegramID, Role: invite.Role}).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &team, invite.Role, nil
+}
+
+// AddTeamSubscription — добавить склад в общий список команды
+func (s *Storage) AddTeamSubscription(teamID uint, warehouseID int) error {
+	sub := Subscription{TeamID: teamID, WarehouseID: warehouseID}
+	return s.db.Where(sub).Where("chat_id = 0").Attrs(Subscription{MaxCoefficient: DefaultMaxCoefficient}).FirstOrCreate(&sub).Error
+}
+
+// RemoveTeamSubscription — удалить склад из общего списка команды
+func (s *Storage) RemoveTeamSubscription(teamID uint, warehouseID int) error {
+	return s.db.Where("team_id = ? AND chat_id = 0 AND warehouse_id = ?", teamID, warehouseID).
+		Delete(&Subscription{}).Error
+}
+
+// GetTeamWarehouses — получить общий список складов команды
+func (s *Storage) GetTeamWarehouses(teamID uint) ([]int, error) {
+	var warehouseIDs []int
+	err := s.db.Model(&Subscription{}).
+		Where("team_id = ? AND chat_id = 0", teamID).
+		Order("id").
+		Pluck("warehouse_id", &warehouseIDs).Error
+	return warehouseIDs, err
+}
+
+// SetTeamAlertTemplate — сохранить шаблон уведомлений команды
+func (s *Storage) SetTeamAlertTemplate(teamID uint, template, parseMode string) error {
+	return s.db.Model(&Team{}).
+		Where("id = ?", teamID).
+		Updates(map[string]interface{}{
+			"alert_template": template,
+			"alert_format":   parseMode,
+		}).Error
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeleteTeam(t *testing.T) {
+	s, err := NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	team, err := s.CreateTeam("Склад", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.CreateTeam("Другая", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{team.ID, other.ID} {
+		if err := s.AddTeamSubscription(id, 507); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CreateTeamInvite(id, RoleViewer, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		w := &Webhook{TeamID: id, URL: "https://example.com/hook", Enabled: true}
+		if err := s.CreateWebhook(w); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.CreateWebhookDeliveries([]WebhookDelivery{{WebhookID: w.ID, Status: DeliveryPending, NextAttemptAt: time.Now()}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.DeleteTeam(team.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetTeam(team.ID); !IsNotFound(err) {
+		t.Errorf("команда осталась: %v", err)
+	}
+	if members, _ := s.GetTeamMembers(team.ID); len(members) != 0 {
+		t.Errorf("остались участники: %v", members)
+	}
+	if ids, _ := s.GetTeamWarehouses(team.ID); len(ids) != 0 {
+		t.Errorf("остались склады: %v", ids)
+	}
+	if webhooks, _ := s.GetWebhooks(0, team.ID); len(webhooks) != 0 {
+		t.Errorf("остались вебхуки: %v", webhooks)
+	}
+	if webhooks, _ := s.GetWebhooksForWarehouse(507); len(webhooks) != 1 || webhooks[0].TeamID != other.ID {
+		t.Errorf("вебхуки склада после удаления команды: %+v, ожидался только вебхук другой команды", webhooks)
+	}
+	if due, _ := s.GetDueWebhookDeliveries(10); len(due) != 1 || due[0].WebhookID != webhooksOf(t, s, other.ID)[0].ID {
+		t.Errorf("доставки после удаления команды: %+v", due)
+	}
+
+	// Другая команда не задета
+	if ids, _ := s.GetTeamWarehouses(other.ID); len(ids) != 1 {
+		t.Errorf("склады другой команды: %v", ids)
+	}
+}
+
+// webhooksOf — вебхуки команды
+func webhooksOf(t *testing.T, s *Storage, teamID uint) []Webhook {
+	t.Helper()
+	webhooks, err := s.GetWebhooks(0, teamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return webhooks
+}
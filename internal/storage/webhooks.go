@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Статусы доставки вебхука
+const (
+	DeliveryPending   = "pending"   // Ждёт отправки или повтора
+	DeliveryDelivered = "delivered" // Получатель ответил 2xx
+	DeliveryDead      = "dead"      // Попытки исчерпаны или вебхук отключён
+)
+
+// Webhook — адрес, куда бот отправляет события по складам из подписок владельца
+type Webhook struct {
+	ID         uint  `gorm:"primaryKey"`
+	ChatID     int64 `gorm:"index"`                    // Владелец личного вебхука, 0 — вебхук команды
+	TeamID     uint  `gorm:"index;not null;default:0"` // Команда, 0 — личный вебхук
+	CreatedBy  int64 // Кто добавил: ему приходит уведомление об отключении
+	URL        string
+	Secret     string // Секрет подписи, зашифрован
+	Enabled    bool
+	Failures   int    // Неудачных попыток подряд
+	LastError  string // Последняя ошибка доставки
+	CreatedAt  time.Time
+	DisabledAt *time.Time
+}
+
+// WebhookDelivery — доставка одного события на вебхук
+type WebhookDelivery struct {
+	ID            uint   `gorm:"primaryKey"`
+	WebhookID     uint   `gorm:"index"`
+	Event         string // Вид события
+	WarehouseID   int
+	Payload       string    // Тело запроса, одно и то же во всех попытках
+	Status        string    `gorm:"index:idx_webhook_due"` // pending, delivered или dead
+	Attempts      int       // Сколько раз пытались доставить
+	NextAttemptAt time.Time `gorm:"index:idx_webhook_due"`
+	ResponseCode  int       // HTTP-код последней попытки, 0 — ответа не было
+	LastError     string
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+// CreateWebhook — сохранить новый вебхук
+func (s *Storage) CreateWebhook(w *Webhook) error {
+	return s.db.Create(w).Error
+}
+
+// GetWebhook — получить вебхук по ID
+func (s *Storage) GetWebhook(id uint) (*Webhook, error) {
+	var w Webhook
+	if err := s.db.First(&w, id).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// GetWebhooks — личные вебхуки чата и вебхуки команды (teamID = 0 — без команды)
+func (s *Storage) GetWebhooks(chatID int64, teamID uint) ([]Webhook, error) {
+	var webhooks []Webhook
+	query := s.db.Where("chat_id = ? AND team_id = 0", chatID)
+	if teamID != 0 {
+		query = query.Or("team_id = ?", teamID)
+	}
+	err := query.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// DeleteWebhook — удалить вебхук вместе с журналом доставок
+func (s *Storage) DeleteWebhook(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Webhook{}, id).Error
+	})
+}
+
+// CountEnabledWebhooks — сколько вебхуков ждут событий
+func (s *Storage) CountEnabledWebhooks() (int64, error) {
+	var count int64
+	err := s.db.Model(&Webhook{}).Where("enabled = ?", true).Count(&count).Error
+	return count, err
+}
+
+// EnableWebhook — снова включить вебхук и обнулить счётчик ошибок
+func (s *Storage) EnableWebhook(id uint) error {
+	return s.db.Model(&Webhook{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"enabled": true, "failures": 0, "disabled_at": nil}).Error
+}
+
+// DisableWebhook — отключить вебхук, недоставленные события больше не отправляются
+func (s *Storage) DisableWebhook(id uint, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Webhook{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{"enabled": false, "disabled_at": time.Now(), "last_error": reason}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&WebhookDelivery{}).
+			Where("webhook_id = ? AND status = ?", id, DeliveryPending).
+			Update("status", DeliveryDead).Error
+	})
+}
+
+// GetWebhooksForWarehouse — включённые вебхуки, чьи владельцы отслеживают склад:
+// личные — по подпискам чата владельца, командные — по общему списку команды
+func (s *Storage) GetWebhooksForWarehouse(warehouseID int) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := s.db.Where("enabled = ?", true).
+		Where(s.db.
+			Where("team_id = 0 AND chat_id IN (?)",
+				s.db.Model(&Subscription{}).Select("chat_id").Where("team_id = 0 AND warehouse_id = ?", warehouseID)).
+			Or("team_id <> 0 AND team_id IN (?)",
+				s.db.Model(&Subscription{}).Select("team_id").Where("chat_id = 0 AND warehouse_id = ?", warehouseID))).
+		Order("id").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// CreateWebhookDeliveries — поставить доставки в очередь
+func (s *Storage) CreateWebhookDeliveries(deliveries []WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return s.db.Create(&deliveries).Error
+}
+
+// GetDueWebhookDeliveries — доставки, которые пора отправлять, в порядке постановки
+func (s *Storage) GetDueWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := s.db.Where("status = ? AND next_attempt_at <= ?", DeliveryPending, time.Now()).
+		Order("id").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// MarkWebhookDelivered — доставка прошла, счётчик ошибок вебхука обнуляется
+func (s *Storage) MarkWebhookDelivered(d *WebhookDelivery, code int) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&WebhookDelivery{}).
+			Where("id = ?", d.ID).
+			Updates(map[string]interface{}{
+				"status":        DeliveryDelivered,
+				"attempts":      gorm.Expr("attempts + 1"),
+				"response_code": code,
+				"delivered_at":  time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Webhook{}).Where("id = ?", d.WebhookID).Update("failures", 0).Error
+	})
+}
+
+// FailWebhookDelivery — неудачная попытка: доставка откладывается до next или,
+// если next = nil, прекращается. Возвращает число ошибок вебхука подряд.
+func (s *Storage) FailWebhookDelivery(d *WebhookDelivery, next *time.Time, code int, lastError string) (int, error) {
+	var failures int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"attempts":      gorm.Expr("attempts + 1"),
+			"response_code": code,
+			"last_error":    lastError,
+		}
+		if next != nil {
+			updates["next_attempt_at"] = *next
+		} else {
+			updates["status"] = DeliveryDead
+		}
+		if err := tx.Model(&WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&Webhook{}).
+			Where("id = ?", d.WebhookID).
+			Updates(map[string]interface{}{"failures": gorm.Expr("failures + 1"), "last_error": lastError}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&Webhook{}).Where("id = ?", d.WebhookID).Select("failures").Scan(&failures).Error
+	})
+	return failures, err
+}
+
+// DropWebhookDelivery — прекратить доставку, не засчитывая ошибку вебхуку: он удалён или отключён
+func (s *Storage) DropWebhookDelivery(d *WebhookDelivery, lastError string) error {
+	return s.db.Model(&WebhookDelivery{}).
+		Where("id = ?", d.ID).
+		Updates(map[string]interface{}{"status": DeliveryDead, "last_error": lastError}).Error
+}
+
+// PruneWebhookDeliveries — удалить доставленные и недоставленные события, поставленные в очередь до before.
+// Ожидающие повтора остаются, сколько бы ни были старыми.
+func (s *Storage) PruneWebhookDeliveries(before time.Time) (int64, error) {
+	result := s.db.Where("status IN ? AND created_at < ?", []string{DeliveryDelivered, DeliveryDead}, before).
+		Delete(&WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+// GetWebhookDeliveries — последние доставки вебхука, новые первыми
+func (s *Storage) GetWebhookDeliveries(webhookID uint, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := s.db.Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
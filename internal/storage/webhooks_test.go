@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDropWebhookDelivery(t *testing.T) {
+	s, err := NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	w := &Webhook{ChatID: 1, URL: "https://example.com/hook", Enabled: true, Failures: 2}
+	if err := s.CreateWebhook(w); err != nil {
+		t.Fatal(err)
+	}
+	deliveries := []WebhookDelivery{{WebhookID: w.ID, Status: DeliveryPending, NextAttemptAt: time.Now()}}
+	if err := s.CreateWebhookDeliveries(deliveries); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := s.CountEnabledWebhooks(); err != nil || count != 1 {
+		t.Errorf("включённых вебхуков %d (%v), ожидался 1", count, err)
+	}
+
+	if err := s.DropWebhookDelivery(&deliveries[0], "вебхук отключён"); err != nil {
+		t.Fatal(err)
+	}
+
+	if due, _ := s.GetDueWebhookDeliveries(10); len(due) != 0 {
+		t.Errorf("отменённая доставка осталась в очереди: %+v", due)
+	}
+	got, err := s.GetWebhook(w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Failures != 2 {
+		t.Errorf("ошибок подряд %d, ожидалось 2: отмена доставки не считается ошибкой вебхука", got.Failures)
+	}
+}
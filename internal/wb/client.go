@@ -1,34 +1,81 @@
 package wb
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"postavkinBot/internal/metrics"
 
 	"github.com/go-resty/resty/v2"
 )
 
+// ErrUnauthorized — WB отклонил ключ API: он неверный, отозван, истёк или без нужных прав
+var ErrUnauthorized = errors.New("ключ WB API не принят")
+
 // Client — структура для работы с WB API
 type Client struct {
 	apiKey string
 	client *resty.Client
+
+	requests atomic.Int64 // Запросов к API с момента запуска
+	failures atomic.Int64 // Из них завершились ошибкой сети или ответом 4xx/5xx
 }
 
 // NewClient — создание нового клиента WB
-func NewClient() *Client {
-	apiKey := os.Getenv("WB_API_KEY")
-	if apiKey == "" {
-		panic("WB_API_KEY не установлен в переменных окружения")
-	}
-
+func NewClient(baseURL, apiKey string, timeout time.Duration) *Client {
 	client := resty.New().
-		SetHostURL("https://supplies-api.wildberries.ru").
+		SetBaseURL(baseURL).
+		SetTimeout(timeout).
 		SetHeader("Authorization", apiKey).
 		SetHeader("Content-Type", "application/json")
 
-	return &Client{
+	c := &Client{
 		apiKey: apiKey,
 		client: client,
 	}
+	client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		c.requests.Add(1)
+		if resp.IsError() {
+			c.failures.Add(1)
+		}
+		endpoint := endpointOf(resp.Request)
+		metrics.WBRequests.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode())).Inc()
+		metrics.WBRequestDuration.WithLabelValues(endpoint).Observe(resp.Time().Seconds())
+		return nil
+	})
+	client.OnError(func(req *resty.Request, err error) {
+		// Ответ с кодом ошибки уже учтён в OnAfterResponse
+		var respErr *resty.ResponseError
+		if !errors.As(err, &respErr) {
+			c.requests.Add(1)
+			c.failures.Add(1)
+			endpoint := endpointOf(req)
+			metrics.WBRequests.WithLabelValues(endpoint, "error").Inc()
+			metrics.WBRequestDuration.WithLabelValues(endpoint).Observe(time.Since(req.Time).Seconds())
+		}
+	})
+	return c
+}
+
+// endpointOf — путь запроса без хоста и параметров для меток метрик
+func endpointOf(req *resty.Request) string {
+	if req.RawRequest != nil {
+		return req.RawRequest.URL.Path
+	}
+	if u, err := url.Parse(req.URL); err == nil {
+		return u.Path
+	}
+	return req.URL
+}
+
+// Stats — число запросов к API и ошибок с момента запуска
+func (c *Client) Stats() (requests, failures int64) {
+	return c.requests.Load(), c.failures.Load()
 }
 
 // Warehouse — структура склада
@@ -50,12 +97,19 @@ func (c *Client) GetWarehouses() ([]Warehouse, error) {
 	}
 
 	if resp.IsError() {
-		return nil, fmt.Errorf("ошибка ответа: %s", resp.Status())
+		return nil, responseError(resp)
 	}
 
 	return warehouses, nil
 }
 
+// BoxTypes — типы поставки из коэффициентов приёмки
+var BoxTypes = map[int]string{
+	2: "Короба",
+	5: "Монопаллеты",
+	6: "Суперсейф",
+}
+
 // Coefficient — структура коэффициента лимита склада
 type Coefficient struct {
 	Date            string `json:"date"`
@@ -81,8 +135,31 @@ func (c *Client) GetAcceptanceCoefficients() ([]Coefficient, error) {
 	}
 
 	if resp.IsError() {
-		return nil, fmt.Errorf("ошибка ответа: %s", resp.Status())
+		return nil, responseError(resp)
 	}
 
 	return coefficients, nil
 }
+
+// Ping — проверить ключ: WB отвечает 200 только на действующий ключ с доступом к API поставок
+func (c *Client) Ping() error {
+	resp, err := c.client.R().Get("/ping")
+	if err != nil {
+		return fmt.Errorf("ошибка запроса: %v", err)
+	}
+
+	if resp.IsError() {
+		return responseError(resp)
+	}
+
+	return nil
+}
+
+// responseError — ошибка по ответу с кодом 4xx/5xx; 401 и 403 оборачивают ErrUnauthorized
+func responseError(resp *resty.Response) error {
+	switch resp.StatusCode() {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, resp.Status())
+	}
+	return fmt.Errorf("ошибка ответа: %s", resp.Status())
+}
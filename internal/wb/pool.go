@@ -0,0 +1,80 @@
+package wb
+
+import (
+	"sync"
+	"time"
+)
+
+// Pool — клиенты WB API по ключам: у каждого пользователя со своим ключом свой лимит запросов
+type Pool struct {
+	baseURL string
+	timeout time.Duration
+	shared  *Client
+
+	mu      sync.Mutex
+	clients map[string]*Client // Ключ API -> клиент
+}
+
+// NewPool — пул с общим клиентом на ключе бота
+func NewPool(baseURL, apiKey string, timeout time.Duration) *Pool {
+	return &Pool{
+		baseURL: baseURL,
+		timeout: timeout,
+		shared:  NewClient(baseURL, apiKey, timeout),
+		clients: make(map[string]*Client),
+	}
+}
+
+// Shared — клиент с общим ключом бота
+func (p *Pool) Shared() *Client {
+	return p.shared
+}
+
+// Get — клиент для ключа, пустой ключ — общий клиент
+func (p *Pool) Get(apiKey string) *Client {
+	if apiKey == "" {
+		return p.shared
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	client, ok := p.clients[apiKey]
+	if !ok {
+		client = NewClient(p.baseURL, apiKey, p.timeout)
+		p.clients[apiKey] = client
+	}
+	return client
+}
+
+// NewClient — отдельный клиент вне пула, например для проверки ключа до сохранения
+func (p *Pool) NewClient(apiKey string) *Client {
+	return NewClient(p.baseURL, apiKey, p.timeout)
+}
+
+// Remove — забыть клиента ключа, когда пользователь его удалил или заменил
+func (p *Pool) Remove(apiKey string) {
+	p.mu.Lock()
+	delete(p.clients, apiKey)
+	p.mu.Unlock()
+}
+
+// Len — сколько в пуле клиентов с ключами пользователей
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Stats — запросы и ошибки всех клиентов пула с момента запуска
+func (p *Pool) Stats() (requests, failures int64) {
+	requests, failures = p.shared.Stats()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, client := range p.clients {
+		r, f := client.Stats()
+		requests += r
+		failures += f
+	}
+	return requests, failures
+}
@@ -0,0 +1,101 @@
+package wb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Scope — бит категории API в поле s токена WB
+type Scope uint
+
+// Категории токена WB API, номера битов — из документации WB
+const (
+	ScopeContent     Scope = 1  // Контент
+	ScopeAnalytics   Scope = 2  // Аналитика
+	ScopePrices      Scope = 3  // Цены и скидки
+	ScopeMarketplace Scope = 4  // Маркетплейс
+	ScopeStatistics  Scope = 5  // Статистика
+	ScopePromotion   Scope = 6  // Продвижение
+	ScopeFeedbacks   Scope = 7  // Вопросы и отзывы
+	ScopeChat        Scope = 9  // Чат с покупателями
+	ScopeSupplies    Scope = 10 // Поставки — нужна боту для коэффициентов приёмки
+	ScopeReturns     Scope = 11 // Возвраты покупателями
+	ScopeDocuments   Scope = 12 // Документы
+	ScopeReadOnly    Scope = 30 // Токен только на чтение
+)
+
+// ErrMalformedToken — строка не похожа на токен WB API
+var ErrMalformedToken = errors.New("ключ не похож на токен WB API")
+
+// TokenInfo — сведения из токена WB API, прочитанные без обращения к API.
+// Подпись не проверяется: это делает WB при каждом запросе.
+type TokenInfo struct {
+	ID         string    // ID токена (id)
+	SellerID   int64     // ID продавца (oid)
+	SellerUUID string    // UUID продавца (sid)
+	ExpiresAt  time.Time // Срок действия (exp), нулевой — бессрочный
+	Scopes     uint64    // Битовая маска категорий (s)
+	Test       bool      // Токен тестового контура (t)
+}
+
+// tokenClaims — поля полезной нагрузки JWT, которые выдаёт WB
+type tokenClaims struct {
+	ID   string `json:"id"`
+	Exp  int64  `json:"exp"`
+	OID  int64  `json:"oid"`
+	SID  string `json:"sid"`
+	S    uint64 `json:"s"`
+	Test bool   `json:"t"`
+}
+
+// ParseToken — разобрать токен WB API (JWT) без проверки подписи
+func ParseToken(token string) (TokenInfo, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return TokenInfo{}, ErrMalformedToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return TokenInfo{}, ErrMalformedToken
+	}
+
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return TokenInfo{}, ErrMalformedToken
+	}
+
+	info := TokenInfo{
+		ID:         claims.ID,
+		SellerID:   claims.OID,
+		SellerUUID: claims.SID,
+		Scopes:     claims.S,
+		Test:       claims.Test,
+	}
+	if claims.Exp > 0 {
+		info.ExpiresAt = time.Unix(claims.Exp, 0)
+	}
+	return info, nil
+}
+
+// HasScope — есть ли у токена доступ к категории
+func (t TokenInfo) HasScope(scope Scope) bool {
+	return t.Scopes&(1<<scope) != 0
+}
+
+// ReadOnly — токен только на чтение
+func (t TokenInfo) ReadOnly() bool {
+	return t.HasScope(ScopeReadOnly)
+}
+
+// Expired — истёк ли токен к моменту now
+func (t TokenInfo) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// ExpiresWithin — истекает ли токен в ближайшие d от now (истёкший тоже считается)
+func (t TokenInfo) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return !t.ExpiresAt.IsZero() && now.Add(d).After(t.ExpiresAt)
+}
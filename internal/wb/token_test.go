@@ -0,0 +1,147 @@
+package wb
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testToken — JWT с полезной нагрузкой payload и фиктивной подписью
+func testToken(payload string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","kid":"test"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestParseToken(t *testing.T) {
+	token := testToken(`{"id":"0a1b2c","exp":1893456000,"oid":123456,"sid":"seller-uuid","s":1024,"t":false}`)
+
+	info, err := ParseToken(" " + token + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TokenInfo{
+		ID:         "0a1b2c",
+		SellerID:   123456,
+		SellerUUID: "seller-uuid",
+		ExpiresAt:  time.Unix(1893456000, 0),
+		Scopes:     1 << ScopeSupplies,
+	}
+	if info != want {
+		t.Errorf("ParseToken = %+v, ожидалось %+v", info, want)
+	}
+	if !info.HasScope(ScopeSupplies) || info.ReadOnly() {
+		t.Errorf("права %b: ожидались поставки без режима только чтения", info.Scopes)
+	}
+}
+
+func TestParseTokenPadded(t *testing.T) {
+	// Некоторые генераторы оставляют = в конце частей
+	token := testToken(`{"id":"x","s":4}`)
+	header, rest, _ := strings.Cut(token, ".")
+	payload, signature, _ := strings.Cut(rest, ".")
+	if _, err := ParseToken(header + "." + payload + "==." + signature); err != nil {
+		t.Errorf("токен с выравниванием: %v", err)
+	}
+}
+
+func TestParseTokenMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"пустая строка", ""},
+		{"без точек", "abcdef"},
+		{"две части", "a.b"},
+		{"четыре части", testToken(`{}`) + ".d"},
+		{"не base64", "a.!!!.c"},
+		{"не JSON", testToken("not json")},
+		{"неверный тип поля", testToken(`{"exp":"завтра"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseToken(tt.token); !errors.Is(err, ErrMalformedToken) {
+				t.Errorf("ParseToken(%q): ошибка %v, ожидалась ErrMalformedToken", tt.token, err)
+			}
+		})
+	}
+}
+
+func TestTokenWithoutExp(t *testing.T) {
+	info, err := ParseToken(testToken(`{"id":"forever","s":1024}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ExpiresAt.IsZero() {
+		t.Errorf("срок %v, ожидался бессрочный токен", info.ExpiresAt)
+	}
+
+	far := time.Now().AddDate(100, 0, 0)
+	if info.Expired(far) || info.ExpiresWithin(far, 24*time.Hour) {
+		t.Error("бессрочный токен не должен истекать")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	expires := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	info := TokenInfo{ExpiresAt: expires}
+
+	tests := []struct {
+		now     time.Time
+		expired bool
+		within  bool // Истекает в ближайшие 7 дней
+	}{
+		{expires.AddDate(0, 0, -30), false, false},
+		{expires.AddDate(0, 0, -7), false, false},
+		{expires.AddDate(0, 0, -7).Add(time.Second), false, true},
+		{expires.Add(-time.Second), false, true},
+		{expires, true, true},
+		{expires.AddDate(0, 0, 1), true, true},
+	}
+	for _, tt := range tests {
+		if got := info.Expired(tt.now); got != tt.expired {
+			t.Errorf("Expired(%v) = %v, ожидалось %v", tt.now, got, tt.expired)
+		}
+		if got := info.ExpiresWithin(tt.now, 7*24*time.Hour); got != tt.within {
+			t.Errorf("ExpiresWithin(%v) = %v, ожидалось %v", tt.now, got, tt.within)
+		}
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	scopes := []Scope{
+		ScopeContent, ScopeAnalytics, ScopePrices, ScopeMarketplace, ScopeStatistics, ScopePromotion,
+		ScopeFeedbacks, ScopeChat, ScopeSupplies, ScopeReturns, ScopeDocuments, ScopeReadOnly,
+	}
+	for _, scope := range scopes {
+		info := TokenInfo{Scopes: 1 << scope}
+		for _, other := range scopes {
+			if got := info.HasScope(other); got != (other == scope) {
+				t.Errorf("маска %b: HasScope(%d) = %v", info.Scopes, other, got)
+			}
+		}
+		if got := info.ReadOnly(); got != (scope == ScopeReadOnly) {
+			t.Errorf("маска %b: ReadOnly = %v", info.Scopes, got)
+		}
+	}
+
+	// Бит 0 и бит 8 не относятся ни к одной категории
+	info := TokenInfo{Scopes: 1<<0 | 1<<8}
+	for _, scope := range scopes {
+		if info.HasScope(scope) {
+			t.Errorf("маска %b: лишняя категория %d", info.Scopes, scope)
+		}
+	}
+
+	// Несколько категорий сразу: контент, цены, маркетплейс, поставки и только чтение
+	info = TokenInfo{Scopes: 1073742874}
+	for scope, want := range map[Scope]bool{
+		ScopeContent: true, ScopePrices: true, ScopeMarketplace: true, ScopeSupplies: true, ScopeReadOnly: true,
+		ScopeAnalytics: false, ScopeStatistics: false, ScopeChat: false,
+	} {
+		if got := info.HasScope(scope); got != want {
+			t.Errorf("маска %b: HasScope(%d) = %v, ожидалось %v", info.Scopes, scope, got, want)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/lifecycle"
+	"postavkinBot/internal/logging"
+	"postavkinBot/internal/metrics"
+	"postavkinBot/internal/secret"
+	"postavkinBot/internal/storage"
+)
+
+const (
+	batchSize      = 50               // Доставок за один проход
+	idleDelay      = 2 * time.Second  // Пауза, когда доставлять нечего
+	minRetryDelay  = 30 * time.Second // Пауза перед первым повтором, дальше удваивается
+	maxRetryDelay  = time.Hour        // Максимальная пауза между повторами
+	maxResponseLen = 64 << 10         // Сколько читать из ответа получателя
+	userAgent      = "postavkinBot-webhook/1"
+)
+
+// Store — хранилище вебхуков и доставок
+type Store interface {
+	GetWebhook(id uint) (*storage.Webhook, error)
+	GetWebhooksForWarehouse(warehouseID int) ([]storage.Webhook, error)
+	GetWarehouseSubscriptions(warehouseID int) ([]storage.Subscription, error)
+	CreateWebhookDeliveries(deliveries []storage.WebhookDelivery) error
+	GetDueWebhookDeliveries(limit int) ([]storage.WebhookDelivery, error)
+	MarkWebhookDelivered(d *storage.WebhookDelivery, code int) error
+	FailWebhookDelivery(d *storage.WebhookDelivery, next *time.Time, code int, lastError string) (int, error)
+	DropWebhookDelivery(d *storage.WebhookDelivery, lastError string) error
+	DisableWebhook(id uint, reason string) error
+}
+
+// Доставке подходит любое хранилище бота, а не только *storage.Storage
+var _ Store = storage.Repository(nil)
+
+// Payload — тело запроса вебхука
+type Payload struct {
+	ID                  string    `json:"id"`    // ID события, общий для всех вебхуков, получивших его
+	Event               string    `json:"event"` // slot_opened, slot_closed, coefficient_changed или warehouse_added
+	OccurredAt          time.Time `json:"occurred_at"`
+	WarehouseID         int       `json:"warehouse_id"`
+	WarehouseName       string    `json:"warehouse_name"`
+	Coefficient         int       `json:"coefficient"`
+	PreviousCoefficient *int      `json:"previous_coefficient,omitempty"`
+	AllowUnload         bool      `json:"allow_unload"`
+	BoxTypeID           int       `json:"box_type_id"`
+	BoxTypeName         string    `json:"box_type_name"`
+	Date                string    `json:"date"` // Дата приёмки из ответа WB
+}
+
+// Dispatcher — получатель событий, который ставит доставки в очередь, и отправка этой очереди
+type Dispatcher struct {
+	store      Store
+	keys       *secret.Box
+	cfg        config.Webhooks
+	client     *http.Client
+	onDisabled func(w storage.Webhook) // Уведомить владельца об отключении вебхука
+}
+
+// NewDispatcher — создать доставку вебхуков; keys расшифровывает секреты подписи
+func NewDispatcher(store Store, keys *secret.Box, cfg config.Webhooks, onDisabled func(w storage.Webhook)) *Dispatcher {
+	return &Dispatcher{
+		store:      store,
+		keys:       keys,
+		cfg:        cfg,
+		client:     newHTTPClient(cfg.Timeout, cfg.AllowPrivate),
+		onDisabled: onDisabled,
+	}
+}
+
+// HandleEvent — поставить событие склада в очередь для вебхуков, чьи владельцы его отслеживают.
+// Вид события пересчитывается по порогу и типу поставки из подписки владельца.
+// Уведомления конкретным подписчикам (e.Recipient) вебхукам не отправляются.
+func (d *Dispatcher) HandleEvent(ctx context.Context, e events.Event) error {
+	if e.Recipient != nil {
+		return nil
+	}
+
+	webhooks, err := d.store.GetWebhooksForWarehouse(e.Coefficient.WarehouseID)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	subscriptions, err := d.store.GetWarehouseSubscriptions(e.Coefficient.WarehouseID)
+	if err != nil {
+		return err
+	}
+	type owner struct {
+		chatID int64
+		teamID uint
+	}
+	filters := make(map[owner]storage.Subscription, len(subscriptions))
+	for _, sub := range subscriptions {
+		filters[owner{sub.ChatID, sub.TeamID}] = sub
+	}
+
+	payload := Payload{
+		ID:            NewSecret()[:16],
+		OccurredAt:    e.At.UTC(),
+		WarehouseID:   e.Coefficient.WarehouseID,
+		WarehouseName: e.Coefficient.WarehouseName,
+		Coefficient:   e.Coefficient.Coefficient,
+		AllowUnload:   e.Coefficient.AllowUnload,
+		BoxTypeID:     e.Coefficient.BoxTypeID,
+		BoxTypeName:   e.Coefficient.BoxTypeName,
+		Date:          e.Coefficient.Date,
+	}
+	if e.Previous != nil {
+		previous := e.Previous.Coefficient
+		payload.PreviousCoefficient = &previous
+	}
+	bodies := make(map[events.Kind]string) // Тело для каждого вида события, ID у всех общий
+
+	var deliveries []storage.WebhookDelivery
+	for _, w := range webhooks {
+		// Подписки команды хранятся с chat_id = 0
+		key := owner{w.ChatID, 0}
+		if w.TeamID != 0 {
+			key = owner{0, w.TeamID}
+		}
+		sub, ok := filters[key]
+		if !ok {
+			continue
+		}
+		kind, ok := ownerKind(e, sub)
+		if !ok {
+			continue
+		}
+
+		body, ok := bodies[kind]
+		if !ok {
+			payload.Event = string(kind)
+			raw, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+			body = string(raw)
+			bodies[kind] = body
+		}
+		deliveries = append(deliveries, storage.WebhookDelivery{
+			WebhookID:     w.ID,
+			Event:         string(kind),
+			WarehouseID:   payload.WarehouseID,
+			Payload:       body,
+			Status:        storage.DeliveryPending,
+			NextAttemptAt: e.At,
+		})
+	}
+	return d.store.CreateWebhookDeliveries(deliveries)
+}
+
+// ownerKind — вид события для владельца подписки sub; false — событие его не касается:
+// другой тип поставки или изменение, которое при его пороге ничего не открыло и не закрыло
+func ownerKind(e events.Event, sub storage.Subscription) (events.Kind, bool) {
+	if sub.BoxTypeID == 0 && !e.First || sub.BoxTypeID != 0 && e.Coefficient.BoxTypeID != sub.BoxTypeID {
+		return "", false
+	}
+	if e.Kind == events.WarehouseAdded {
+		return e.Kind, true
+	}
+
+	open := !e.Removed && events.OpenFor(e.Coefficient, sub.MaxCoefficient)
+	wasOpen := e.Previous != nil && events.OpenFor(*e.Previous, sub.MaxCoefficient)
+	switch {
+	case !wasOpen && open:
+		return events.SlotOpened, true
+	case wasOpen && !open:
+		return events.SlotClosed, true
+	case e.Removed || e.Previous == nil:
+		return "", false
+	case e.Previous.Coefficient != e.Coefficient.Coefficient:
+		return events.CoefficientChanged, true
+	}
+	return "", false
+}
+
+// Run — отправка доставок из очереди до отмены ctx
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		deliveries, err := d.store.GetDueWebhookDeliveries(batchSize)
+		if err != nil {
+			slog.Error("Ошибка чтения очереди вебхуков", logging.Err(err))
+		}
+
+		webhooks := make(map[uint]*storage.Webhook) // Вебхуки этого прохода
+		for i := range deliveries {
+			if ctx.Err() != nil {
+				return nil
+			}
+			d.deliver(ctx, &deliveries[i], webhooks)
+		}
+
+		if len(deliveries) == 0 && !lifecycle.Sleep(ctx, idleDelay) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// deliver — одна попытка доставки
+func (d *Dispatcher) deliver(ctx context.Context, delivery *storage.WebhookDelivery, webhooks map[uint]*storage.Webhook) {
+	w, ok := webhooks[delivery.WebhookID]
+	if !ok {
+		var err error
+		if w, err = d.store.GetWebhook(delivery.WebhookID); err != nil && !storage.IsNotFound(err) {
+			slog.Error("Ошибка получения вебхука", webhookAttr(delivery.WebhookID), logging.Err(err))
+			return
+		}
+		webhooks[delivery.WebhookID] = w
+	}
+	if w == nil || !w.Enabled {
+		// Вебхук удалён или отключён во время прохода — доставку не повторяем и ошибкой вебхука не считаем
+		metrics.WebhookDeliveries.WithLabelValues("dead").Inc()
+		if err := d.store.DropWebhookDelivery(delivery, "вебхук отключён"); err != nil {
+			slog.Error("Ошибка отмены доставки вебхука", webhookAttr(delivery.WebhookID), logging.Err(err))
+		}
+		return
+	}
+
+	secretKey, err := d.keys.Decrypt(w.Secret)
+	if err != nil {
+		slog.Error("Ошибка расшифровки секрета вебхука", webhookAttr(w.ID), logging.Err(err))
+		d.fail(delivery, w, 0, "ошибка расшифровки секрета", false)
+		return
+	}
+
+	code, err := d.send(ctx, delivery, secretKey, w.URL)
+	if err == nil {
+		metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+		if err := d.store.MarkWebhookDelivered(delivery, code); err != nil {
+			slog.Error("Ошибка отметки доставки вебхука", webhookAttr(w.ID), logging.Err(err))
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Остановка бота — попытку не считаем
+		return
+	}
+	d.fail(delivery, w, code, err.Error(), true)
+}
+
+// send — POST тела доставки с подписью, возвращает HTTP-код
+func (d *Dispatcher) send(ctx context.Context, delivery *storage.WebhookDelivery, secretKey, url string) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secretKey, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseLen))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// fail — неудачная попытка: повтор с нарастающей паузой, пока есть попытки (retry),
+// и отключение вебхука после webhooks.disable_after ошибок подряд
+func (d *Dispatcher) fail(delivery *storage.WebhookDelivery, w *storage.Webhook, code int, reason string, retry bool) {
+	var next *time.Time
+	attempts := delivery.Attempts + 1
+	if retry && attempts < d.cfg.MaxAttempts {
+		delay := minRetryDelay << uint(attempts-1)
+		if delay > maxRetryDelay || delay <= 0 {
+			delay = maxRetryDelay
+		}
+		at := time.Now().Add(delay)
+		next = &at
+		metrics.WebhookDeliveries.WithLabelValues("error").Inc()
+	} else {
+		metrics.WebhookDeliveries.WithLabelValues("dead").Inc()
+	}
+
+	failures, err := d.store.FailWebhookDelivery(delivery, next, code, reason)
+	if err != nil {
+		slog.Error("Ошибка сохранения неудачной доставки вебхука", webhookAttr(delivery.WebhookID), logging.Err(err))
+		return
+	}
+	slog.Warn("Вебхук не принял событие", webhookAttr(w.ID), "attempt", attempts, "failures", failures, "error", reason)
+
+	if failures >= d.cfg.DisableAfter {
+		if err := d.store.DisableWebhook(w.ID, reason); err != nil {
+			slog.Error("Ошибка отключения вебхука", webhookAttr(w.ID), logging.Err(err))
+			return
+		}
+		w.Enabled = false
+		slog.Warn("Вебхук отключён после серии ошибок", webhookAttr(w.ID), "failures", failures)
+		if d.onDisabled != nil {
+			d.onDisabled(*w)
+		}
+	}
+}
+
+// webhookAttr — поле журнала с ID вебхука
+func webhookAttr(id uint) slog.Attr {
+	return slog.Uint64("webhook_id", uint64(id))
+}
@@ -0,0 +1,414 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"postavkinBot/internal/config"
+	"postavkinBot/internal/events"
+	"postavkinBot/internal/secret"
+	"postavkinBot/internal/storage"
+	"postavkinBot/internal/wb"
+)
+
+// fakeStore — хранилище вебхуков в памяти с той же логикой счётчиков, что и storage
+type fakeStore struct {
+	mu            sync.Mutex
+	webhooks      map[uint]*storage.Webhook
+	subscriptions []storage.Subscription
+	deliveries    []storage.WebhookDelivery
+}
+
+func newFakeStore(webhooks ...storage.Webhook) *fakeStore {
+	s := &fakeStore{webhooks: make(map[uint]*storage.Webhook)}
+	for i := range webhooks {
+		s.webhooks[webhooks[i].ID] = &webhooks[i]
+	}
+	return s
+}
+
+func (s *fakeStore) GetWebhook(id uint) (*storage.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.webhooks[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	copied := *w
+	return &copied, nil
+}
+
+func (s *fakeStore) GetWebhooksForWarehouse(int) ([]storage.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var webhooks []storage.Webhook
+	for id := uint(1); id <= uint(len(s.webhooks)); id++ {
+		if w, ok := s.webhooks[id]; ok && w.Enabled {
+			webhooks = append(webhooks, *w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (s *fakeStore) GetWarehouseSubscriptions(int) ([]storage.Subscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *fakeStore) CreateWebhookDeliveries(deliveries []storage.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range deliveries {
+		d.ID = uint(len(s.deliveries) + 1)
+		s.deliveries = append(s.deliveries, d)
+	}
+	return nil
+}
+
+func (s *fakeStore) GetDueWebhookDeliveries(limit int) ([]storage.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []storage.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status == storage.DeliveryPending && !d.NextAttemptAt.After(time.Now()) && len(due) < limit {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (s *fakeStore) MarkWebhookDelivered(d *storage.WebhookDelivery, code int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := &s.deliveries[d.ID-1]
+	stored.Status = storage.DeliveryDelivered
+	stored.Attempts++
+	stored.ResponseCode = code
+	s.webhooks[d.WebhookID].Failures = 0
+	return nil
+}
+
+func (s *fakeStore) FailWebhookDelivery(d *storage.WebhookDelivery, next *time.Time, code int, lastError string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := &s.deliveries[d.ID-1]
+	stored.Attempts++
+	stored.ResponseCode = code
+	stored.LastError = lastError
+	if next != nil {
+		stored.NextAttemptAt = *next
+	} else {
+		stored.Status = storage.DeliveryDead
+	}
+	w := s.webhooks[d.WebhookID]
+	w.Failures++
+	w.LastError = lastError
+	return w.Failures, nil
+}
+
+func (s *fakeStore) DropWebhookDelivery(d *storage.WebhookDelivery, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[d.ID-1].Status = storage.DeliveryDead
+	s.deliveries[d.ID-1].LastError = lastError
+	return nil
+}
+
+func (s *fakeStore) DisableWebhook(id uint, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhooks[id].Enabled = false
+	s.webhooks[id].LastError = reason
+	for i := range s.deliveries {
+		if s.deliveries[i].WebhookID == id && s.deliveries[i].Status == storage.DeliveryPending {
+			s.deliveries[i].Status = storage.DeliveryDead
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) delivery(id uint) storage.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deliveries[id-1]
+}
+
+// receiver — получатель вебхуков: проверяет подпись и отвечает кодом status
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	bodies   []string
+	verified []bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, string(body))
+	r.verified = append(r.verified, Verify(r.secret, timestamp, body, req.Header.Get(HeaderSignature)))
+	w.WriteHeader(r.status)
+}
+
+// newTestDispatcher — рассылка на один вебхук с адресом url и секретом secretKey
+func newTestDispatcher(t *testing.T, url, secretKey string, cfg config.Webhooks) (*Dispatcher, *fakeStore, *[]storage.Webhook) {
+	t.Helper()
+	keys, err := secret.NewBox(strings.Repeat("ab", secret.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := keys.Encrypt(secretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newFakeStore(storage.Webhook{ID: 1, ChatID: 42, CreatedBy: 42, URL: url, Secret: encrypted, Enabled: true})
+	store.subscriptions = []storage.Subscription{{ChatID: 42, WarehouseID: 507, MaxCoefficient: 1}}
+
+	var disabled []storage.Webhook
+	d := NewDispatcher(store, keys, cfg, func(w storage.Webhook) { disabled = append(disabled, w) })
+	return d, store, &disabled
+}
+
+// openEvent — приёмка на складе 507 открылась
+func openEvent() events.Event {
+	return events.Event{
+		Kind:        events.SlotOpened,
+		Coefficient: wb.Coefficient{WarehouseID: 507, WarehouseName: "Коледино", Coefficient: 0, AllowUnload: true, BoxTypeID: 2},
+		Previous:    &wb.Coefficient{WarehouseID: 507, Coefficient: -1, BoxTypeID: 2},
+		First:       true,
+		At:          time.Now(),
+	}
+}
+
+func TestOwnerKind(t *testing.T) {
+	coefficient := func(c int, unload bool) wb.Coefficient {
+		return wb.Coefficient{WarehouseID: 507, Coefficient: c, AllowUnload: unload, BoxTypeID: 2}
+	}
+	previous := func(c int) *wb.Coefficient {
+		p := coefficient(c, true)
+		return &p
+	}
+
+	tests := []struct {
+		name   string
+		event  events.Event
+		sub    storage.Subscription
+		want   events.Kind
+		wantOK bool
+	}{
+		{"открылась бесплатно", events.Event{Coefficient: coefficient(0, true), Previous: previous(-1), First: true},
+			storage.Subscription{MaxCoefficient: 0}, events.SlotOpened, true},
+		{"выше порога подписки", events.Event{Coefficient: coefficient(3, true), Previous: previous(-1), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.CoefficientChanged, true},
+		{"открылась при высоком пороге", events.Event{Coefficient: coefficient(3, true), Previous: previous(-1), First: true},
+			storage.Subscription{MaxCoefficient: 5}, events.SlotOpened, true},
+		{"выгрузка запрещена", events.Event{Coefficient: coefficient(0, false), Previous: previous(-1), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.CoefficientChanged, true},
+		{"выгрузку запретили", events.Event{Coefficient: coefficient(0, false), Previous: previous(0), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.SlotClosed, true},
+		{"закрылась", events.Event{Coefficient: coefficient(-1, true), Previous: previous(1), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.SlotClosed, true},
+		{"закрылась для низкого порога", events.Event{Coefficient: coefficient(2, true), Previous: previous(1), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.SlotClosed, true},
+		{"тип поставки пропал", events.Event{Coefficient: coefficient(0, true), Previous: previous(0), First: true, Removed: true},
+			storage.Subscription{MaxCoefficient: 1}, events.SlotClosed, true},
+		{"коэффициент изменился", events.Event{Coefficient: coefficient(5, true), Previous: previous(4), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.CoefficientChanged, true},
+		{"изменение внутри порога", events.Event{Coefficient: coefficient(1, true), Previous: previous(0), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.CoefficientChanged, true},
+		{"без изменений", events.Event{Coefficient: coefficient(5, true), Previous: previous(5), First: true},
+			storage.Subscription{MaxCoefficient: 1}, "", false},
+		{"новый закрытый тип", events.Event{Coefficient: coefficient(5, true), First: true},
+			storage.Subscription{MaxCoefficient: 1}, "", false},
+		{"не первый тип без фильтра", events.Event{Coefficient: coefficient(0, true), Previous: previous(-1)},
+			storage.Subscription{MaxCoefficient: 1}, "", false},
+		{"выбранный тип поставки", events.Event{Coefficient: coefficient(0, true), Previous: previous(-1)},
+			storage.Subscription{MaxCoefficient: 1, BoxTypeID: 2}, events.SlotOpened, true},
+		{"другой тип поставки", events.Event{Coefficient: coefficient(0, true), Previous: previous(-1), First: true},
+			storage.Subscription{MaxCoefficient: 1, BoxTypeID: 5}, "", false},
+		{"новый склад", events.Event{Kind: events.WarehouseAdded, Coefficient: coefficient(5, true), First: true},
+			storage.Subscription{MaxCoefficient: 1}, events.WarehouseAdded, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ownerKind(tt.event, tt.sub)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ownerKind = %q, %v, ожидалось %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHandleEventRoutesByOwner(t *testing.T) {
+	store := newFakeStore(
+		storage.Webhook{ID: 1, ChatID: 42, Enabled: true},
+		storage.Webhook{ID: 2, TeamID: 7, Enabled: true},
+		storage.Webhook{ID: 3, ChatID: 43, Enabled: true},
+	)
+	store.subscriptions = []storage.Subscription{
+		{ChatID: 42, WarehouseID: 507, MaxCoefficient: 0},
+		{TeamID: 7, WarehouseID: 507, MaxCoefficient: 5},
+	}
+	d := NewDispatcher(store, nil, config.Webhooks{}, nil)
+
+	e := openEvent()
+	e.Coefficient.Coefficient = 3
+	if err := d.HandleEvent(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	// Для порога 0 приёмка не открылась, у чата 43 нет подписки на склад
+	want := map[uint]events.Kind{1: events.CoefficientChanged, 2: events.SlotOpened}
+	if len(store.deliveries) != len(want) {
+		t.Fatalf("доставок %d, ожидалось %d", len(store.deliveries), len(want))
+	}
+	for _, delivery := range store.deliveries {
+		if delivery.Event != string(want[delivery.WebhookID]) {
+			t.Errorf("вебхук %d получил %s, ожидалось %s", delivery.WebhookID, delivery.Event, want[delivery.WebhookID])
+		}
+		if !strings.Contains(delivery.Payload, `"event":"`+delivery.Event+`"`) {
+			t.Errorf("тело %s не совпадает с видом события", delivery.Payload)
+		}
+	}
+
+	e.Recipient = &events.Recipient{ChatID: 42}
+	if err := d.HandleEvent(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.deliveries) != len(want) {
+		t.Errorf("уведомление подписчику попало в вебхуки: %d доставок", len(store.deliveries))
+	}
+}
+
+func TestDeliverSigned(t *testing.T) {
+	rcv := &receiver{secret: "hook-secret", status: http.StatusNoContent}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	d, store, _ := newTestDispatcher(t, server.URL, rcv.secret, config.Webhooks{Timeout: time.Second, MaxAttempts: 3, DisableAfter: 5, AllowPrivate: true})
+	if err := d.HandleEvent(context.Background(), openEvent()); err != nil {
+		t.Fatal(err)
+	}
+	due, _ := store.GetDueWebhookDeliveries(batchSize)
+	if len(due) != 1 {
+		t.Fatalf("доставок в очереди %d, ожидалась 1", len(due))
+	}
+	d.deliver(context.Background(), &due[0], make(map[uint]*storage.Webhook))
+
+	if len(rcv.bodies) != 1 || !rcv.verified[0] {
+		t.Fatalf("получатель не принял подпись: %v", rcv.verified)
+	}
+	if rcv.bodies[0] != due[0].Payload {
+		t.Errorf("тело %s, ожидалось %s", rcv.bodies[0], due[0].Payload)
+	}
+	if got := store.delivery(due[0].ID); got.Status != storage.DeliveryDelivered || got.ResponseCode != http.StatusNoContent {
+		t.Errorf("доставка %s с кодом %d", got.Status, got.ResponseCode)
+	}
+}
+
+func TestDeliverBlocksPrivateAddress(t *testing.T) {
+	rcv := &receiver{secret: "hook-secret", status: http.StatusOK}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	// Адрес прошёл бы проверку при создании через DNS, но соединение с loopback обрывается
+	d, store, _ := newTestDispatcher(t, server.URL, rcv.secret, config.Webhooks{Timeout: time.Second, MaxAttempts: 3, DisableAfter: 5})
+	if err := d.HandleEvent(context.Background(), openEvent()); err != nil {
+		t.Fatal(err)
+	}
+	due, _ := store.GetDueWebhookDeliveries(batchSize)
+	d.deliver(context.Background(), &due[0], make(map[uint]*storage.Webhook))
+
+	if len(rcv.bodies) != 0 {
+		t.Fatal("запрос дошёл до адреса локальной сети")
+	}
+	got := store.delivery(due[0].ID)
+	if got.Status != storage.DeliveryPending || !strings.Contains(got.LastError, ErrPrivateAddress.Error()) {
+		t.Errorf("доставка %s, ошибка %q", got.Status, got.LastError)
+	}
+	if _, err := d.client.Get(server.URL); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("клиент вернул %v, ожидалось %v", err, ErrPrivateAddress)
+	}
+}
+
+func TestFailRetriesAndDisables(t *testing.T) {
+	rcv := &receiver{secret: "hook-secret", status: http.StatusInternalServerError}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	d, store, disabled := newTestDispatcher(t, server.URL, rcv.secret, config.Webhooks{Timeout: time.Second, MaxAttempts: 2, DisableAfter: 3, AllowPrivate: true})
+	for range 3 {
+		if err := d.HandleEvent(context.Background(), openEvent()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Первая попытка: повтор через minRetryDelay
+	first := store.delivery(1)
+	before := time.Now()
+	d.deliver(context.Background(), &first, make(map[uint]*storage.Webhook))
+	got := store.delivery(1)
+	if got.Status != storage.DeliveryPending || got.Attempts != 1 || got.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("после первой ошибки доставка %+v", got)
+	}
+	if delay := got.NextAttemptAt.Sub(before); delay < minRetryDelay || delay > minRetryDelay+time.Second {
+		t.Errorf("повтор через %v, ожидалось %v", delay, minRetryDelay)
+	}
+
+	// Вторая попытка исчерпывает max_attempts
+	d.deliver(context.Background(), &got, make(map[uint]*storage.Webhook))
+	if got = store.delivery(1); got.Status != storage.DeliveryDead || got.Attempts != 2 {
+		t.Fatalf("после последней попытки доставка %s, попыток %d", got.Status, got.Attempts)
+	}
+	if len(*disabled) != 0 {
+		t.Fatal("вебхук отключён раньше disable_after ошибок")
+	}
+
+	// Третья ошибка подряд отключает вебхук, оставшиеся доставки прекращаются
+	second := store.delivery(2)
+	d.deliver(context.Background(), &second, make(map[uint]*storage.Webhook))
+	if w, _ := store.GetWebhook(1); w.Enabled || w.Failures != 3 {
+		t.Errorf("вебхук включён %v, ошибок %d", w.Enabled, w.Failures)
+	}
+	if len(*disabled) != 1 || (*disabled)[0].ID != 1 || (*disabled)[0].Enabled {
+		t.Errorf("уведомления об отключении %+v", *disabled)
+	}
+	for id := uint(2); id <= 3; id++ {
+		if got := store.delivery(id); got.Status != storage.DeliveryDead {
+			t.Errorf("доставка %d в статусе %s, ожидалось %s", id, got.Status, storage.DeliveryDead)
+		}
+	}
+
+	// Доставка отключённому вебхуку прекращается без запроса и без новой ошибки
+	third := store.delivery(3)
+	third.Status = storage.DeliveryPending
+	requests := len(rcv.bodies)
+	d.deliver(context.Background(), &third, make(map[uint]*storage.Webhook))
+	if len(rcv.bodies) != requests {
+		t.Error("отключённому вебхуку отправлен запрос")
+	}
+	if w, _ := store.GetWebhook(1); w.Failures != 3 {
+		t.Errorf("ошибок %d, отключённый вебхук не должен копить ошибки", w.Failures)
+	}
+}
+
+func TestFailBackoffCapped(t *testing.T) {
+	store := newFakeStore(storage.Webhook{ID: 1, Enabled: true})
+	store.deliveries = []storage.WebhookDelivery{{ID: 1, WebhookID: 1, Status: storage.DeliveryPending, Attempts: 20}}
+	d := NewDispatcher(store, nil, config.Webhooks{MaxAttempts: 100, DisableAfter: 100}, nil)
+
+	before := time.Now()
+	d.fail(&store.deliveries[0], store.webhooks[1], 500, "HTTP 500", true)
+	if delay := store.delivery(1).NextAttemptAt.Sub(before); delay < maxRetryDelay || delay > maxRetryDelay+time.Second {
+		t.Errorf("повтор через %v, ожидалось не больше %v", delay, maxRetryDelay)
+	}
+}
@@ -0,0 +1,111 @@
+// Package webhook — исходящие вебхуки: события по складам из подписок владельца отправляются
+// POST-запросом с JSON и подписью HMAC-SHA256, с повторами и отключением после серии ошибок.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Заголовки запроса
+const (
+	HeaderEvent     = "X-Webhook-Event"     // Вид события
+	HeaderDelivery  = "X-Webhook-Delivery"  // ID доставки, одинаковый во всех попытках
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix-время попытки
+	HeaderSignature = "X-Webhook-Signature" // sha256=<hex HMAC-SHA256(секрет, timestamp + "." + тело)>
+)
+
+// maxURLLength — ограничение длины адреса вебхука
+const maxURLLength = 2048
+
+var (
+	// ErrInvalidURL — адрес вебхука не подходит
+	ErrInvalidURL = errors.New("адрес вебхука должен быть https://хост/путь")
+	// ErrPrivateAddress — адрес ведёт в локальную сеть
+	ErrPrivateAddress = errors.New("адрес вебхука ведёт в локальную сеть")
+)
+
+// NewSecret — случайный секрет подписи
+func NewSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Sign — значение заголовка X-Webhook-Signature для тела body, отправленного в момент timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify — проверить подпись; для получателей и проверки в тестах
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// ValidateURL — проверить адрес вебхука: только https, без логина в адресе;
+// адреса локальной сети запрещены, если не allowPrivate
+func ValidateURL(raw string, allowPrivate bool) error {
+	if len(raw) > maxURLLength {
+		return ErrInvalidURL
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil || u.Fragment != "" {
+		return ErrInvalidURL
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !allowPrivate && !publicIP(ip) {
+		return ErrPrivateAddress
+	}
+	if u.Hostname() == "localhost" && !allowPrivate {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// publicIP — адрес из интернета, а не локальной сети или служебных диапазонов
+func publicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// newHTTPClient — клиент без перенаправлений; если не allowPrivate, соединения с адресами
+// локальной сети обрываются уже после разрешения имени, чтобы DNS не обошёл проверку
+func newHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}